@@ -16,21 +16,95 @@ const (
 	AnnLinodeHealthCheckAttempts = "service.beta.kubernetes.io/linode-loadbalancer-check-attempts"
 	AnnLinodeHealthCheckPassive  = "service.beta.kubernetes.io/linode-loadbalancer-check-passive"
 
+	// AnnLinodeHealthCheckIntervalScaling is the annotation enabling scaling the
+	// health check interval with the number of backends, to keep the total health
+	// check load on the backends bounded.
+	AnnLinodeHealthCheckIntervalScaling = "service.beta.kubernetes.io/linode-loadbalancer-check-interval-scaling"
+
+	// AnnLinodeMinTLSVersion is the annotation specifying the minimum TLS version
+	// accepted by HTTPS NodeBalancer configs. Options are 1.0, 1.1 and 1.2.
+	AnnLinodeMinTLSVersion = "service.beta.kubernetes.io/linode-loadbalancer-min-tls-version"
+
+	// AnnLinodeAlgorithm and AnnLinodeStickiness are the annotations specifying
+	// the balancing algorithm and the session stickiness of the NodeBalancer
+	// configs. Options are roundrobin, leastconn and source for the algorithm,
+	// and none, table and http_cookie for the stickiness.
+	AnnLinodeAlgorithm  = "service.beta.kubernetes.io/linode-loadbalancer-algorithm"
+	AnnLinodeStickiness = "service.beta.kubernetes.io/linode-loadbalancer-stickiness"
+
 	// AnnLinodeThrottle is the annotation specifying the value of the Client Connection
 	// Throttle, which limits the number of subsequent new connections per second from the
 	// same client IP. Options are a number between 1-20, or 0 to disable. Defaults to 20.
 	AnnLinodeThrottle = "service.beta.kubernetes.io/linode-loadbalancer-throttle"
 
+	// AnnLinodeIdleTimeout is the annotation specifying the idle connection
+	// timeout of the NodeBalancer, in seconds between 1 and 3600. It is reserved
+	// until the Linode API exposes the setting, and is rejected until then.
+	AnnLinodeIdleTimeout = "service.beta.kubernetes.io/linode-loadbalancer-idle-timeout"
+
+	// AnnLinodeAPIQPS and AnnLinodeAPIBurst are the annotations specifying the
+	// rate, in requests per second, and burst of the Linode API requests made
+	// for the Service, overriding the CCM defaults. A rate of 0 is unlimited.
+	AnnLinodeAPIQPS   = "service.beta.kubernetes.io/linode-loadbalancer-api-qps"
+	AnnLinodeAPIBurst = "service.beta.kubernetes.io/linode-loadbalancer-api-burst"
+
+	// AnnLinodeMinBackends is the annotation specifying the minimum number of
+	// backend nodes of the NodeBalancer, overriding the CCM default. A Warning
+	// event is emitted for Services with fewer backends.
+	AnnLinodeMinBackends = "service.beta.kubernetes.io/linode-loadbalancer-min-backends"
+
 	AnnLinodeLoadBalancerPreserve = "service.beta.kubernetes.io/linode-loadbalancer-preserve"
 	AnnLinodeNodeBalancerID       = "service.beta.kubernetes.io/linode-loadbalancer-nodebalancer-id"
 
+	// AnnLinodeNodeBalancerStatusID is the annotation set by the CCM, when
+	// enabled, to the ID of the NodeBalancer backing the Service. Unlike
+	// AnnLinodeNodeBalancerID, it is only informational and never read.
+	AnnLinodeNodeBalancerStatusID = "service.beta.kubernetes.io/linode-loadbalancer-status-nodebalancer-id"
+
+	// AnnLinodeEffectiveConfig is the annotation set by the CCM, when enabled,
+	// to the NodeBalancer configuration applied for the Service, as JSON, once
+	// its annotations are resolved against the CCM flags and defaults. It is
+	// only informational and never read.
+	AnnLinodeEffectiveConfig = "service.beta.kubernetes.io/linode-loadbalancer-status-effective-config"
+
+	// AnnLinodeRegions is the annotation specifying a comma separated list of
+	// candidate regions for the NodeBalancer, in order of preference; it is
+	// created in the candidate with the most backend nodes.
+	AnnLinodeRegions = "service.beta.kubernetes.io/linode-loadbalancer-regions"
+
 	AnnLinodeHostnameOnlyIngress = "service.beta.kubernetes.io/linode-loadbalancer-hostname-only-ingress"
 	AnnLinodeLoadBalancerTags    = "service.beta.kubernetes.io/linode-loadbalancer-tags"
+	AnnLinodeAuditTags           = "service.beta.kubernetes.io/linode-loadbalancer-audit-tags"
 	AnnLinodeCloudFirewallID     = "service.beta.kubernetes.io/linode-loadbalancer-firewall-id"
 	AnnLinodeCloudFirewallACL    = "service.beta.kubernetes.io/linode-loadbalancer-firewall-acl"
 
+	// AnnLinodeBackendSubnet is the annotation specifying a comma separated list
+	// of CIDRs; the first node address within any of them is used as the
+	// NodeBalancer backend address. Useful for nodes with multiple NICs/private
+	// IPs.
+	AnnLinodeBackendSubnet = "service.beta.kubernetes.io/linode-loadbalancer-backend-subnet"
+
+	// AnnLinodeBackendIPPreference is the annotation specifying an ordered, comma
+	// separated list of node address types (vpc, private, public) to use as the
+	// NodeBalancer backend address. The first available type wins.
+	AnnLinodeBackendIPPreference = "service.beta.kubernetes.io/linode-loadbalancer-backend-ip-preference"
+
+	// AnnLinodeBackendNodeSelector is the annotation specifying a label selector
+	// the nodes used as NodeBalancer backends must match, overriding the
+	// cluster-wide default. An empty value selects all nodes.
+	AnnLinodeBackendNodeSelector = "service.beta.kubernetes.io/linode-loadbalancer-backend-node-selector"
+
+	// AnnLinodeHostNetworking is the annotation specifying that the Service is
+	// backed by host-networked pods, so backends use the target port instead of the NodePort
+	AnnLinodeHostNetworking = "service.beta.kubernetes.io/linode-loadbalancer-host-networking"
+
 	AnnLinodeNodePrivateIP = "node.k8s.linode.com/private-ip"
 	AnnLinodeHostUUID      = "node.k8s.linode.com/host-uuid"
 
+	// AnnLinodeInstanceClass is the label set by the CCM, when enabled, to the
+	// class of the Linode type of the node: standard, dedicated, gpu, highmem
+	// or other.
+	AnnLinodeInstanceClass = "node.k8s.linode.com/instance-class"
+
 	AnnLinodeNodeIPSharingUpdated = "node.k8s.linode.com/ip-sharing-updated"
 )
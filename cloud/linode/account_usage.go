@@ -0,0 +1,43 @@
+package linode
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client"
+)
+
+// reportAccountUsage exports the number of NodeBalancers on the account and,
+// since the Linode API does not expose it, the limit set with
+// Options.AccountNodeBalancerLimit.
+func reportAccountUsage(ctx context.Context, linodeClient client.Client) error {
+	nbs, err := linodeClient.ListNodeBalancers(ctx, nil)
+	if err != nil {
+		return err
+	}
+	accountNodeBalancersUsed.Set(float64(len(nbs)))
+	if Options.AccountNodeBalancerLimit > 0 {
+		accountNodeBalancersLimit.Set(float64(Options.AccountNodeBalancerLimit))
+	}
+	return nil
+}
+
+// runAccountUsageReporter reports the account usage every
+// Options.AccountUsageInterval until stopCh is closed.
+func runAccountUsageReporter(linodeClient client.Client, stopCh <-chan struct{}) {
+	if Options.AccountUsageInterval <= 0 {
+		return
+	}
+
+	registerMetrics()
+	wait.Until(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := reportAccountUsage(ctx, linodeClient); err != nil {
+			klog.Errorf("failed to report account usage: %s", err)
+		}
+	}, Options.AccountUsageInterval, stopCh)
+}
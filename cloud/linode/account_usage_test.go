@@ -0,0 +1,48 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
+)
+
+func TestReportAccountUsage(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+	registerMetrics()
+	defer func() { Options.AccountNodeBalancerLimit = 0 }()
+
+	t.Run("exports the NodeBalancer count and limit", func(t *testing.T) {
+		Options.AccountNodeBalancerLimit = 50
+		client.EXPECT().ListNodeBalancers(gomock.Any(), nil).Times(1).Return([]linodego.NodeBalancer{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+
+		assert.NoError(t, reportAccountUsage(ctx, client))
+
+		used, err := testutil.GetGaugeMetricValue(accountNodeBalancersUsed)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3), used)
+		limit, err := testutil.GetGaugeMetricValue(accountNodeBalancersLimit)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(50), limit)
+	})
+
+	t.Run("keeps the last values on error", func(t *testing.T) {
+		client.EXPECT().ListNodeBalancers(gomock.Any(), nil).Times(1).Return(nil, errors.New("internal server error"))
+
+		assert.Error(t, reportAccountUsage(ctx, client))
+
+		used, err := testutil.GetGaugeMetricValue(accountNodeBalancersUsed)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(3), used)
+	})
+}
@@ -0,0 +1,141 @@
+package linode
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+const (
+	// nodeBalancerNodeDown is the status reported by the Linode API for a
+	// NodeBalancer node that is failing its health checks.
+	nodeBalancerNodeDown = "DOWN"
+	// nodeBalancerNodeUp is the status reported for a NodeBalancer node that
+	// is passing its health checks.
+	nodeBalancerNodeUp = "UP"
+)
+
+// backendTracker remembers when backends were added to a NodeBalancer. Freshly
+// added backends report DOWN until their first health check passes, so those
+// still within the grace period are not considered unhealthy. It also
+// remembers until when the backends being removed are drained, so that the
+// reconciles in the meantime do not remove them.
+type backendTracker struct {
+	mu         sync.Mutex
+	addedAt    map[string]time.Time
+	drainUntil map[string]time.Time
+}
+
+func backendKey(nodeBalancerID int, address string) string {
+	return fmt.Sprintf("%d/%s", nodeBalancerID, address)
+}
+
+// observe records the time backends were first seen on the NodeBalancer.
+// Addresses that were already known keep their original time.
+func (b *backendTracker) observe(nodeBalancerID int, addresses []string, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.addedAt == nil {
+		b.addedAt = make(map[string]time.Time)
+	}
+	for _, address := range addresses {
+		key := backendKey(nodeBalancerID, address)
+		if _, ok := b.addedAt[key]; !ok {
+			b.addedAt[key] = now
+		}
+	}
+}
+
+// forget drops everything known about the NodeBalancer's backends.
+func (b *backendTracker) forget(nodeBalancerID int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prefix := fmt.Sprintf("%d/", nodeBalancerID)
+	for key := range b.addedAt {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.addedAt, key)
+		}
+	}
+	for key := range b.drainUntil {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.drainUntil, key)
+		}
+	}
+}
+
+// drain records that the backend was set to drain mode, and is drained at until.
+func (b *backendTracker) drain(nodeBalancerID int, address string, until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.drainUntil == nil {
+		b.drainUntil = make(map[string]time.Time)
+	}
+	b.drainUntil[backendKey(nodeBalancerID, address)] = until
+}
+
+// drainRemaining returns how long the backend is still being drained for.
+// Backends not known to the tracker (e.g. after a restart, or drained out of
+// band) are considered drained.
+func (b *backendTracker) drainRemaining(nodeBalancerID int, address string, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := backendKey(nodeBalancerID, address)
+	until, ok := b.drainUntil[key]
+	if !ok {
+		return 0
+	}
+	if remaining := until.Sub(now); remaining > 0 {
+		return remaining
+	}
+	delete(b.drainUntil, key)
+	return 0
+}
+
+// unhealthy returns the nodes that are DOWN and were added more than grace ago.
+// Nodes not known to the tracker (e.g. after a restart) are not given a grace period.
+func (b *backendTracker) unhealthy(nodeBalancerID int, nodes []linodego.NodeBalancerNode, grace time.Duration, now time.Time) []linodego.NodeBalancerNode {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var down []linodego.NodeBalancerNode
+	for _, node := range nodes {
+		if node.Status != nodeBalancerNodeDown {
+			continue
+		}
+		if addedAt, ok := b.addedAt[backendKey(nodeBalancerID, node.Address)]; ok && now.Sub(addedAt) < grace {
+			continue
+		}
+		down = append(down, node)
+	}
+	return down
+}
+
+// retainedBackend returns the backend to keep in rotation when replacing the
+// nodes of a NodeBalancer config with the wanted addresses would leave it
+// without a healthy backend: none of the wanted addresses is currently UP, but
+// one of the backends being removed is. Backends in drain mode are only picked
+// when no other healthy backend is being removed. It returns nil when the
+// replacement keeps a healthy backend or none was healthy to begin with.
+func retainedBackend(nodes []linodego.NodeBalancerNode, wanted map[string]bool) *linodego.NodeBalancerNode {
+	var retained *linodego.NodeBalancerNode
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Status != nodeBalancerNodeUp {
+			continue
+		}
+		if wanted[node.Address] {
+			return nil
+		}
+		if retained == nil || (retained.Mode == linodego.ModeDrain && node.Mode != linodego.ModeDrain) {
+			retained = node
+		}
+	}
+	return retained
+}
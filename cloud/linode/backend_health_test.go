@@ -0,0 +1,130 @@
+package linode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackendTrackerUnhealthy(t *testing.T) {
+	const (
+		nbID  = 123
+		grace = time.Minute
+	)
+	now := time.Now()
+
+	tracker := backendTracker{}
+	tracker.observe(nbID, []string{"10.0.0.1:30000"}, now.Add(-2*grace))
+	tracker.observe(nbID, []string{"10.0.0.1:30000", "10.0.0.2:30000"}, now.Add(-grace/2))
+
+	nodes := []linodego.NodeBalancerNode{
+		{Address: "10.0.0.1:30000", Status: "DOWN"},
+		{Address: "10.0.0.2:30000", Status: "DOWN"},
+		{Address: "10.0.0.3:30000", Status: "DOWN"},
+		{Address: "10.0.0.4:30000", Status: "UP"},
+	}
+
+	t.Run("backend within grace period is not unhealthy", func(t *testing.T) {
+		down := tracker.unhealthy(nbID, nodes, grace, now)
+		assert.Len(t, down, 2)
+		for _, node := range down {
+			assert.NotEqual(t, "10.0.0.2:30000", node.Address)
+		}
+	})
+
+	t.Run("backend past grace period is unhealthy", func(t *testing.T) {
+		down := tracker.unhealthy(nbID, nodes, grace, now.Add(grace))
+		assert.Len(t, down, 3)
+	})
+
+	t.Run("grace period is scoped to the NodeBalancer", func(t *testing.T) {
+		down := tracker.unhealthy(nbID+1, nodes, grace, now)
+		assert.Len(t, down, 3)
+	})
+
+	t.Run("forgotten NodeBalancer has no grace period", func(t *testing.T) {
+		tracker.forget(nbID)
+		down := tracker.unhealthy(nbID, nodes, grace, now)
+		assert.Len(t, down, 3)
+	})
+}
+
+func TestBackendTrackerDrain(t *testing.T) {
+	const (
+		nbID   = 123
+		period = time.Minute
+	)
+	now := time.Now()
+
+	tracker := backendTracker{}
+	tracker.drain(nbID, "10.0.0.1:30000", now.Add(period))
+
+	assert.Equal(t, period, tracker.drainRemaining(nbID, "10.0.0.1:30000", now))
+	assert.Equal(t, period/2, tracker.drainRemaining(nbID, "10.0.0.1:30000", now.Add(period/2)))
+	assert.Zero(t, tracker.drainRemaining(nbID, "10.0.0.2:30000", now), "unknown backends are drained")
+	assert.Zero(t, tracker.drainRemaining(nbID+1, "10.0.0.1:30000", now), "drains are scoped to the NodeBalancer")
+
+	assert.Zero(t, tracker.drainRemaining(nbID, "10.0.0.1:30000", now.Add(period)))
+	assert.Empty(t, tracker.drainUntil, "expected drained backends to be dropped")
+
+	tracker.drain(nbID, "10.0.0.1:30000", now.Add(period))
+	tracker.forget(nbID)
+	assert.Zero(t, tracker.drainRemaining(nbID, "10.0.0.1:30000", now))
+}
+
+func TestRetainedBackend(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		nodes  []linodego.NodeBalancerNode
+		wanted map[string]bool
+		expect string
+	}{
+		{
+			name: "wanted backend is healthy",
+			nodes: []linodego.NodeBalancerNode{
+				{ID: 1, Address: "10.0.0.1:30000", Status: "UP"},
+				{ID: 2, Address: "10.0.0.2:30000", Status: "UP"},
+			},
+			wanted: map[string]bool{"10.0.0.2:30000": true, "10.0.0.3:30000": true},
+		},
+		{
+			name: "no backend is healthy",
+			nodes: []linodego.NodeBalancerNode{
+				{ID: 1, Address: "10.0.0.1:30000", Status: "DOWN"},
+			},
+			wanted: map[string]bool{"10.0.0.3:30000": true},
+		},
+		{
+			name: "only removed backends are healthy",
+			nodes: []linodego.NodeBalancerNode{
+				{ID: 1, Address: "10.0.0.1:30000", Status: "DOWN"},
+				{ID: 2, Address: "10.0.0.2:30000", Status: "UP"},
+				{ID: 3, Address: "10.0.0.3:30000", Status: "unknown"},
+			},
+			wanted: map[string]bool{"10.0.0.3:30000": true},
+			expect: "10.0.0.2:30000",
+		},
+		{
+			name: "accepting backend is preferred over draining one",
+			nodes: []linodego.NodeBalancerNode{
+				{ID: 1, Address: "10.0.0.1:30000", Status: "UP", Mode: linodego.ModeDrain},
+				{ID: 2, Address: "10.0.0.2:30000", Status: "UP", Mode: linodego.ModeAccept},
+			},
+			wanted: map[string]bool{"10.0.0.3:30000": true},
+			expect: "10.0.0.2:30000",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			retained := retainedBackend(tc.nodes, tc.wanted)
+			if tc.expect == "" {
+				assert.Nil(t, retained)
+				return
+			}
+			if assert.NotNil(t, retained) {
+				assert.Equal(t, tc.expect, retained.Address)
+			}
+		})
+	}
+}
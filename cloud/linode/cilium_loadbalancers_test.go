@@ -156,7 +156,7 @@ func testNoBGPNodeLabel(t *testing.T, mc *mocks.MockClient) {
 	ciliumClient := &fakev2alpha1.FakeCiliumV2alpha1{Fake: &kubeClient.CiliumFakeClientset.Fake}
 	addService(t, kubeClient, svc)
 	addNodes(t, kubeClient, nodes)
-	lb := &loadbalancers{mc, zone, kubeClient, ciliumClient, ciliumLBType}
+	lb := &loadbalancers{client: mc, zone: zone, kubeClient: kubeClient, ciliumClient: ciliumClient, loadBalancerType: ciliumLBType}
 
 	filter := map[string]string{"label": fmt.Sprintf("%s-%s", ipHolderLabelPrefix, zone)}
 	rawFilter, _ := json.Marshal(filter)
@@ -198,7 +198,7 @@ func testUnsupportedRegion(t *testing.T, mc *mocks.MockClient) {
 	kubeClient, _ := k8sClient.NewFakeClientset()
 	ciliumClient := &fakev2alpha1.FakeCiliumV2alpha1{Fake: &kubeClient.CiliumFakeClientset.Fake}
 	addService(t, kubeClient, svc)
-	lb := &loadbalancers{mc, "us-foobar", kubeClient, ciliumClient, ciliumLBType}
+	lb := &loadbalancers{client: mc, zone: "us-foobar", kubeClient: kubeClient, ciliumClient: ciliumClient, loadBalancerType: ciliumLBType}
 
 	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err == nil {
@@ -217,7 +217,7 @@ func testCreateWithExistingIPHolder(t *testing.T, mc *mocks.MockClient) {
 	ciliumClient := &fakev2alpha1.FakeCiliumV2alpha1{Fake: &kubeClient.CiliumFakeClientset.Fake}
 	addService(t, kubeClient, svc)
 	addNodes(t, kubeClient, nodes)
-	lb := &loadbalancers{mc, zone, kubeClient, ciliumClient, ciliumLBType}
+	lb := &loadbalancers{client: mc, zone: zone, kubeClient: kubeClient, ciliumClient: ciliumClient, loadBalancerType: ciliumLBType}
 
 	filter := map[string]string{"label": fmt.Sprintf("%s-%s", ipHolderLabelPrefix, zone)}
 	rawFilter, _ := json.Marshal(filter)
@@ -255,7 +255,7 @@ func testCreateWithNoExistingIPHolder(t *testing.T, mc *mocks.MockClient) {
 	ciliumClient := &fakev2alpha1.FakeCiliumV2alpha1{Fake: &kubeClient.CiliumFakeClientset.Fake}
 	addService(t, kubeClient, svc)
 	addNodes(t, kubeClient, nodes)
-	lb := &loadbalancers{mc, zone, kubeClient, ciliumClient, ciliumLBType}
+	lb := &loadbalancers{client: mc, zone: zone, kubeClient: kubeClient, ciliumClient: ciliumClient, loadBalancerType: ciliumLBType}
 
 	filter := map[string]string{"label": fmt.Sprintf("%s-%s", ipHolderLabelPrefix, zone)}
 	rawFilter, _ := json.Marshal(filter)
@@ -294,7 +294,7 @@ func testEnsureCiliumLoadBalancerDeleted(t *testing.T, mc *mocks.MockClient) {
 	ciliumClient := &fakev2alpha1.FakeCiliumV2alpha1{Fake: &kubeClient.CiliumFakeClientset.Fake}
 	addService(t, kubeClient, svc)
 	addNodes(t, kubeClient, nodes)
-	lb := &loadbalancers{mc, zone, kubeClient, ciliumClient, ciliumLBType}
+	lb := &loadbalancers{client: mc, zone: zone, kubeClient: kubeClient, ciliumClient: ciliumClient, loadBalancerType: ciliumLBType}
 
 	dummySharedIP := "45.76.101.26"
 	svc.Status.LoadBalancer = v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: dummySharedIP}}}
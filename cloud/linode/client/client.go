@@ -18,6 +18,11 @@ const (
 	DefaultClientTimeout = 120 * time.Second
 )
 
+// Version is the version of linode-cloud-controller-manager reported in the
+// User-Agent of Linode API requests. It is overridden at build time using
+// -ldflags "-X github.com/linode/linode-cloud-controller-manager/cloud/linode/client.Version=<version>".
+var Version = "dev"
+
 type Client interface {
 	GetInstance(context.Context, int) (*linodego.Instance, error)
 	ListInstances(context.Context, *linodego.ListOptions) ([]linodego.Instance, error)
@@ -39,6 +44,8 @@ type Client interface {
 	DeleteNodeBalancer(context.Context, int) error
 	ListNodeBalancers(context.Context, *linodego.ListOptions) ([]linodego.NodeBalancer, error)
 	ListNodeBalancerNodes(context.Context, int, int, *linodego.ListOptions) ([]linodego.NodeBalancerNode, error)
+	UpdateNodeBalancerNode(context.Context, int, int, int, linodego.NodeBalancerNodeUpdateOptions) (*linodego.NodeBalancerNode, error)
+	DeleteNodeBalancerNode(context.Context, int, int, int) error
 
 	CreateNodeBalancerConfig(context.Context, int, linodego.NodeBalancerConfigCreateOptions) (*linodego.NodeBalancerConfig, error)
 	DeleteNodeBalancerConfig(context.Context, int, int) error
@@ -57,9 +64,21 @@ type Client interface {
 // linodego.Client implements Client
 var _ Client = (*linodego.Client)(nil)
 
-// New creates a new linode client with a given token and default timeout
-func New(token string, timeout time.Duration) (*linodego.Client, error) {
-	userAgent := fmt.Sprintf("linode-cloud-controller-manager %s", linodego.DefaultUserAgent)
+// UserAgent returns the User-Agent sent with every Linode API request. It
+// identifies the CCM version and, when known, the cluster it is running in so
+// that API traffic can be correlated to a cluster.
+func UserAgent(clusterID string) string {
+	userAgent := fmt.Sprintf("linode-cloud-controller-manager/%s", Version)
+	if clusterID != "" {
+		userAgent = fmt.Sprintf("%s (cluster %s)", userAgent, clusterID)
+	}
+	return fmt.Sprintf("%s %s", userAgent, linodego.DefaultUserAgent)
+}
+
+// New creates a new linode client with a given token and default timeout.
+// clusterID is included in the User-Agent of all requests made by the client.
+func New(token string, timeout time.Duration, clusterID string) (*linodego.Client, error) {
+	userAgent := UserAgent(clusterID)
 	apiURL := os.Getenv("LINODE_URL")
 
 	linodeClient := linodego.NewClient(&http.Client{Timeout: timeout})
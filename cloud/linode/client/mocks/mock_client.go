@@ -195,6 +195,20 @@ func (mr *MockClientMockRecorder) DeleteNodeBalancerConfig(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNodeBalancerConfig", reflect.TypeOf((*MockClient)(nil).DeleteNodeBalancerConfig), arg0, arg1, arg2)
 }
 
+// DeleteNodeBalancerNode mocks base method.
+func (m *MockClient) DeleteNodeBalancerNode(arg0 context.Context, arg1, arg2, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNodeBalancerNode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNodeBalancerNode indicates an expected call of DeleteNodeBalancerNode.
+func (mr *MockClientMockRecorder) DeleteNodeBalancerNode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNodeBalancerNode", reflect.TypeOf((*MockClient)(nil).DeleteNodeBalancerNode), arg0, arg1, arg2, arg3)
+}
+
 // GetFirewall mocks base method.
 func (m *MockClient) GetFirewall(arg0 context.Context, arg1 int) (*linodego.Firewall, error) {
 	m.ctrl.T.Helper()
@@ -448,3 +462,18 @@ func (mr *MockClientMockRecorder) UpdateNodeBalancer(arg0, arg1, arg2 interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeBalancer", reflect.TypeOf((*MockClient)(nil).UpdateNodeBalancer), arg0, arg1, arg2)
 }
+
+// UpdateNodeBalancerNode mocks base method.
+func (m *MockClient) UpdateNodeBalancerNode(arg0 context.Context, arg1, arg2, arg3 int, arg4 linodego.NodeBalancerNodeUpdateOptions) (*linodego.NodeBalancerNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNodeBalancerNode", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*linodego.NodeBalancerNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNodeBalancerNode indicates an expected call of UpdateNodeBalancerNode.
+func (mr *MockClientMockRecorder) UpdateNodeBalancerNode(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeBalancerNode", reflect.TypeOf((*MockClient)(nil).UpdateNodeBalancerNode), arg0, arg1, arg2, arg3, arg4)
+}
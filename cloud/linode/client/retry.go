@@ -0,0 +1,377 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/linode/linodego"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultRateLimitBackoff is the delay before retrying a failed request
+	// for the first time, doubled before each following retry.
+	DefaultRateLimitBackoff = time.Second
+	// DefaultRateLimitMaxBackoff caps the delay between retries of a rate
+	// limited request, including the delay requested with Retry-After.
+	DefaultRateLimitMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy is how requests rate limited by the Linode API, i.e. failing
+// with HTTP 429, or failing with a transient error are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts of a rate limited request.
+	// Rate limited requests are not retried if it is 1 or less.
+	Attempts int
+	// TransientAttempts is the maximum number of attempts of a request failing
+	// with a transient error: a server error, a timeout or a dropped
+	// connection. Such requests are not retried if it is 1 or less.
+	TransientAttempts int
+	// Backoff is the delay before the first retry, doubled before each
+	// following one unless the API requests a longer one with Retry-After.
+	// Each delay is randomized between half and all of it, so that requests
+	// failing together are not retried together.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+}
+
+// isRateLimited reports whether err is a Linode API rate limit error.
+func isRateLimited(err error) bool {
+	var apiErr *linodego.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests
+}
+
+// isTransient reports whether err is a failure of the Linode API or of the
+// connection to it which may not happen again: a server error, a timeout, or a
+// refused or reset connection. Client errors are not transient.
+func isTransient(err error) bool {
+	var apiErr *linodego.Error
+	if errors.As(err, &apiErr) && apiErr.Code >= http.StatusBadRequest {
+		return apiErr.Code >= http.StatusInternalServerError
+	}
+	if isConnectionRefused(err) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	// linodego only keeps the message of requests failing without a response
+	return apiErr != nil && apiErr.Code == linodego.ErrorFromError &&
+		(strings.Contains(apiErr.Message, "connection reset") || strings.Contains(apiErr.Message, "Client.Timeout"))
+}
+
+// isConnectionRefused reports whether err is a connection to the Linode API
+// being refused, in which case the request was not sent.
+func isConnectionRefused(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var apiErr *linodego.Error
+	return errors.As(err, &apiErr) && apiErr.Code == linodego.ErrorFromError && strings.Contains(apiErr.Message, "connection refused")
+}
+
+// retryAfter returns the delay requested with the Retry-After header of the
+// response err was returned for, if any. Only delays in seconds are supported,
+// which is what the Linode API sends.
+func retryAfter(err error) time.Duration {
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) || apiErr.Response == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(apiErr.Response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// delay returns how long to wait before retrying a request after its attempt
+// failed with err, where backoff is the delay of the exponential backoff.
+func (p RetryPolicy) delay(err error, backoff time.Duration) time.Duration {
+	delay := max(jitter(backoff), retryAfter(err))
+	if p.MaxBackoff > 0 {
+		delay = min(delay, p.MaxBackoff)
+	}
+	return delay
+}
+
+// jitter returns a random delay between half and all of d.
+func jitter(d time.Duration) time.Duration {
+	if d < 2 {
+		return d
+	}
+	return d - rand.N(d/2)
+}
+
+// maxAttempts returns how many times a request failing with err may be
+// attempted, where transient reports whether err is a transient failure that
+// may be retried.
+func (p RetryPolicy) maxAttempts(err error, transient func(error) bool) int {
+	switch {
+	case isRateLimited(err):
+		return p.Attempts
+	case transient(err):
+		return p.TransientAttempts
+	default:
+		return 1
+	}
+}
+
+// Retry calls fn, retrying it as long as it fails with a rate limit error, up
+// to p.Attempts times, or with a transient error, up to p.TransientAttempts
+// times. The last error is returned once the attempts are used up, or the
+// context error if ctx is done while waiting to retry.
+func Retry[T any](ctx context.Context, p RetryPolicy, fn func() (T, error)) (T, error) {
+	return retryIf(ctx, p, isTransient, fn)
+}
+
+// retryIf calls fn as Retry does, with transient reporting which errors are
+// transient failures.
+func retryIf[T any](ctx context.Context, p RetryPolicy, transient func(error) bool, fn func() (T, error)) (T, error) {
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil {
+			return result, nil
+		}
+		attempts := p.maxAttempts(err, transient)
+		if attempt >= attempts {
+			return result, err
+		}
+
+		delay := p.delay(err, backoff)
+		klog.V(3).Infof("Linode API request failed, retrying in %s (attempt %d of %d): %s", delay, attempt+1, attempts, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// retry calls fn as Retry does, for requests returning only an error.
+func retry(ctx context.Context, p RetryPolicy, fn func() error) error {
+	_, err := Retry(ctx, p, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// retryCreate calls fn as Retry does, for requests creating a resource. Those
+// are only retried on transient errors when the connection was refused, as the
+// resource may otherwise have been created, and be created twice.
+func retryCreate[T any](ctx context.Context, p RetryPolicy, fn func() (T, error)) (T, error) {
+	return retryIf(ctx, p, isConnectionRefused, fn)
+}
+
+// retryClient is a Client retrying the requests of its underlying Client which
+// are rate limited or fail with a transient error.
+type retryClient struct {
+	client Client
+	policy RetryPolicy
+}
+
+var _ Client = (*retryClient)(nil)
+
+// WithRetries returns a Client making the requests of client, retried with
+// policy when they are rate limited by the Linode API or fail with a transient
+// error.
+func WithRetries(client Client, policy RetryPolicy) Client {
+	if policy.Attempts <= 1 && policy.TransientAttempts <= 1 {
+		return client
+	}
+	return &retryClient{client: client, policy: policy}
+}
+
+func (c *retryClient) GetInstance(ctx context.Context, linodeID int) (*linodego.Instance, error) {
+	return Retry(ctx, c.policy, func() (*linodego.Instance, error) {
+		return c.client.GetInstance(ctx, linodeID)
+	})
+}
+
+func (c *retryClient) ListInstances(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Instance, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.Instance, error) {
+		return c.client.ListInstances(ctx, opts)
+	})
+}
+
+func (c *retryClient) CreateInstance(ctx context.Context, opts linodego.InstanceCreateOptions) (*linodego.Instance, error) {
+	return retryCreate(ctx, c.policy, func() (*linodego.Instance, error) {
+		return c.client.CreateInstance(ctx, opts)
+	})
+}
+
+func (c *retryClient) GetInstanceIPAddresses(ctx context.Context, linodeID int) (*linodego.InstanceIPAddressResponse, error) {
+	return Retry(ctx, c.policy, func() (*linodego.InstanceIPAddressResponse, error) {
+		return c.client.GetInstanceIPAddresses(ctx, linodeID)
+	})
+}
+
+func (c *retryClient) AddInstanceIPAddress(ctx context.Context, linodeID int, public bool) (*linodego.InstanceIP, error) {
+	return retryCreate(ctx, c.policy, func() (*linodego.InstanceIP, error) {
+		return c.client.AddInstanceIPAddress(ctx, linodeID, public)
+	})
+}
+
+func (c *retryClient) DeleteInstanceIPAddress(ctx context.Context, linodeID int, ipAddress string) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.DeleteInstanceIPAddress(ctx, linodeID, ipAddress)
+	})
+}
+
+func (c *retryClient) ShareIPAddresses(ctx context.Context, opts linodego.IPAddressesShareOptions) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.ShareIPAddresses(ctx, opts)
+	})
+}
+
+func (c *retryClient) UpdateInstanceConfigInterface(ctx context.Context, linodeID, configID, interfaceID int, opts linodego.InstanceConfigInterfaceUpdateOptions) (*linodego.InstanceConfigInterface, error) {
+	return Retry(ctx, c.policy, func() (*linodego.InstanceConfigInterface, error) {
+		return c.client.UpdateInstanceConfigInterface(ctx, linodeID, configID, interfaceID, opts)
+	})
+}
+
+func (c *retryClient) ListVPCs(ctx context.Context, opts *linodego.ListOptions) ([]linodego.VPC, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.VPC, error) {
+		return c.client.ListVPCs(ctx, opts)
+	})
+}
+
+func (c *retryClient) ListVPCIPAddresses(ctx context.Context, vpcID int, opts *linodego.ListOptions) ([]linodego.VPCIP, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.VPCIP, error) {
+		return c.client.ListVPCIPAddresses(ctx, vpcID, opts)
+	})
+}
+
+func (c *retryClient) CreateNodeBalancer(ctx context.Context, opts linodego.NodeBalancerCreateOptions) (*linodego.NodeBalancer, error) {
+	return retryCreate(ctx, c.policy, func() (*linodego.NodeBalancer, error) {
+		return c.client.CreateNodeBalancer(ctx, opts)
+	})
+}
+
+func (c *retryClient) GetNodeBalancer(ctx context.Context, nodeBalancerID int) (*linodego.NodeBalancer, error) {
+	return Retry(ctx, c.policy, func() (*linodego.NodeBalancer, error) {
+		return c.client.GetNodeBalancer(ctx, nodeBalancerID)
+	})
+}
+
+func (c *retryClient) UpdateNodeBalancer(ctx context.Context, nodeBalancerID int, opts linodego.NodeBalancerUpdateOptions) (*linodego.NodeBalancer, error) {
+	return Retry(ctx, c.policy, func() (*linodego.NodeBalancer, error) {
+		return c.client.UpdateNodeBalancer(ctx, nodeBalancerID, opts)
+	})
+}
+
+func (c *retryClient) DeleteNodeBalancer(ctx context.Context, nodeBalancerID int) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.DeleteNodeBalancer(ctx, nodeBalancerID)
+	})
+}
+
+func (c *retryClient) ListNodeBalancers(ctx context.Context, opts *linodego.ListOptions) ([]linodego.NodeBalancer, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.NodeBalancer, error) {
+		return c.client.ListNodeBalancers(ctx, opts)
+	})
+}
+
+func (c *retryClient) ListNodeBalancerNodes(ctx context.Context, nodeBalancerID, configID int, opts *linodego.ListOptions) ([]linodego.NodeBalancerNode, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.NodeBalancerNode, error) {
+		return c.client.ListNodeBalancerNodes(ctx, nodeBalancerID, configID, opts)
+	})
+}
+
+func (c *retryClient) UpdateNodeBalancerNode(ctx context.Context, nodeBalancerID, configID, nodeID int, opts linodego.NodeBalancerNodeUpdateOptions) (*linodego.NodeBalancerNode, error) {
+	return Retry(ctx, c.policy, func() (*linodego.NodeBalancerNode, error) {
+		return c.client.UpdateNodeBalancerNode(ctx, nodeBalancerID, configID, nodeID, opts)
+	})
+}
+
+func (c *retryClient) DeleteNodeBalancerNode(ctx context.Context, nodeBalancerID, configID, nodeID int) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.DeleteNodeBalancerNode(ctx, nodeBalancerID, configID, nodeID)
+	})
+}
+
+func (c *retryClient) CreateNodeBalancerConfig(ctx context.Context, nodeBalancerID int, opts linodego.NodeBalancerConfigCreateOptions) (*linodego.NodeBalancerConfig, error) {
+	return retryCreate(ctx, c.policy, func() (*linodego.NodeBalancerConfig, error) {
+		return c.client.CreateNodeBalancerConfig(ctx, nodeBalancerID, opts)
+	})
+}
+
+func (c *retryClient) DeleteNodeBalancerConfig(ctx context.Context, nodeBalancerID, configID int) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.DeleteNodeBalancerConfig(ctx, nodeBalancerID, configID)
+	})
+}
+
+func (c *retryClient) ListNodeBalancerConfigs(ctx context.Context, nodeBalancerID int, opts *linodego.ListOptions) ([]linodego.NodeBalancerConfig, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.NodeBalancerConfig, error) {
+		return c.client.ListNodeBalancerConfigs(ctx, nodeBalancerID, opts)
+	})
+}
+
+func (c *retryClient) RebuildNodeBalancerConfig(ctx context.Context, nodeBalancerID, configID int, opts linodego.NodeBalancerConfigRebuildOptions) (*linodego.NodeBalancerConfig, error) {
+	return Retry(ctx, c.policy, func() (*linodego.NodeBalancerConfig, error) {
+		return c.client.RebuildNodeBalancerConfig(ctx, nodeBalancerID, configID, opts)
+	})
+}
+
+func (c *retryClient) ListNodeBalancerFirewalls(ctx context.Context, nodebalancerID int, opts *linodego.ListOptions) ([]linodego.Firewall, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.Firewall, error) {
+		return c.client.ListNodeBalancerFirewalls(ctx, nodebalancerID, opts)
+	})
+}
+
+func (c *retryClient) ListFirewallDevices(ctx context.Context, firewallID int, opts *linodego.ListOptions) ([]linodego.FirewallDevice, error) {
+	return Retry(ctx, c.policy, func() ([]linodego.FirewallDevice, error) {
+		return c.client.ListFirewallDevices(ctx, firewallID, opts)
+	})
+}
+
+func (c *retryClient) DeleteFirewallDevice(ctx context.Context, firewallID, deviceID int) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.DeleteFirewallDevice(ctx, firewallID, deviceID)
+	})
+}
+
+func (c *retryClient) CreateFirewallDevice(ctx context.Context, firewallID int, opts linodego.FirewallDeviceCreateOptions) (*linodego.FirewallDevice, error) {
+	return retryCreate(ctx, c.policy, func() (*linodego.FirewallDevice, error) {
+		return c.client.CreateFirewallDevice(ctx, firewallID, opts)
+	})
+}
+
+func (c *retryClient) CreateFirewall(ctx context.Context, opts linodego.FirewallCreateOptions) (*linodego.Firewall, error) {
+	return retryCreate(ctx, c.policy, func() (*linodego.Firewall, error) {
+		return c.client.CreateFirewall(ctx, opts)
+	})
+}
+
+func (c *retryClient) DeleteFirewall(ctx context.Context, fwid int) error {
+	return retry(ctx, c.policy, func() error {
+		return c.client.DeleteFirewall(ctx, fwid)
+	})
+}
+
+func (c *retryClient) GetFirewall(ctx context.Context, firewallID int) (*linodego.Firewall, error) {
+	return Retry(ctx, c.policy, func() (*linodego.Firewall, error) {
+		return c.client.GetFirewall(ctx, firewallID)
+	})
+}
+
+func (c *retryClient) UpdateFirewallRules(ctx context.Context, firewallID int, rules linodego.FirewallRuleSet) (*linodego.FirewallRuleSet, error) {
+	return Retry(ctx, c.policy, func() (*linodego.FirewallRuleSet, error) {
+		return c.client.UpdateFirewallRules(ctx, firewallID, rules)
+	})
+}
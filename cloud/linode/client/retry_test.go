@@ -0,0 +1,199 @@
+package client_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client"
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
+)
+
+func rateLimitError(retryAfter string) error {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &linodego.Error{
+		Code:     http.StatusTooManyRequests,
+		Message:  "Too Many Requests",
+		Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header},
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	policy := client.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	attempt := func(errs ...error) (func() (int, error), *int) {
+		calls := 0
+		return func() (int, error) {
+			calls++
+			if calls <= len(errs) {
+				return 0, errs[calls-1]
+			}
+			return calls, nil
+		}, &calls
+	}
+
+	t.Run("retries rate limited requests", func(t *testing.T) {
+		fn, calls := attempt(rateLimitError(""), rateLimitError(""))
+		result, err := client.Retry(ctx, policy, fn)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("gives up after the maximum attempts", func(t *testing.T) {
+		fn, calls := attempt(rateLimitError(""), rateLimitError(""), rateLimitError(""))
+		_, err := client.Retry(ctx, policy, fn)
+		assert.Equal(t, rateLimitError(""), err)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		fn, calls := attempt(&linodego.Error{Code: http.StatusInternalServerError})
+		_, err := client.Retry(ctx, policy, fn)
+		assert.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		policy := policy
+		policy.TransientAttempts = 3
+		fn, calls := attempt(&linodego.Error{Code: http.StatusBadGateway}, syscall.ECONNRESET)
+		result, err := client.Retry(ctx, policy, fn)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		policy := policy
+		policy.TransientAttempts = 3
+		fn, calls := attempt(&linodego.Error{Code: http.StatusNotFound})
+		_, err := client.Retry(ctx, policy, fn)
+		assert.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("does not retry when retries are disabled", func(t *testing.T) {
+		fn, calls := attempt(rateLimitError(""))
+		_, err := client.Retry(ctx, client.RetryPolicy{Attempts: 1}, fn)
+		assert.Error(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("waits for Retry-After up to the maximum backoff", func(t *testing.T) {
+		fn, calls := attempt(rateLimitError("60"))
+		start := time.Now()
+		_, err := client.Retry(ctx, policy, fn)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, *calls)
+		assert.GreaterOrEqual(t, time.Since(start), policy.MaxBackoff)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		fn, calls := attempt(rateLimitError(""))
+		_, err := client.Retry(ctx, client.RetryPolicy{Attempts: 3, Backoff: time.Hour}, fn)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestWithRetries(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mocks.NewMockClient(ctrl)
+	linodeClient := client.WithRetries(mock, client.RetryPolicy{Attempts: 3, Backoff: time.Millisecond})
+
+	t.Run("retries instance lookups", func(t *testing.T) {
+		gomock.InOrder(
+			mock.EXPECT().GetInstance(gomock.Any(), 123).Times(1).Return(nil, rateLimitError("")),
+			mock.EXPECT().GetInstance(gomock.Any(), 123).Times(1).Return(&linodego.Instance{ID: 123}, nil),
+		)
+		instance, err := linodeClient.GetInstance(ctx, 123)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, instance.ID)
+	})
+
+	t.Run("retries NodeBalancer mutations", func(t *testing.T) {
+		gomock.InOrder(
+			mock.EXPECT().DeleteNodeBalancer(gomock.Any(), 456).Times(2).Return(rateLimitError("")),
+			mock.EXPECT().DeleteNodeBalancer(gomock.Any(), 456).Times(1).Return(nil),
+		)
+		assert.NoError(t, linodeClient.DeleteNodeBalancer(ctx, 456))
+	})
+
+	t.Run("does not retry creates on server errors", func(t *testing.T) {
+		linodeClient := client.WithRetries(mock, client.RetryPolicy{TransientAttempts: 3, Backoff: time.Millisecond})
+		mock.EXPECT().CreateNodeBalancer(gomock.Any(), gomock.Any()).Times(1).Return(nil, &linodego.Error{Code: http.StatusInternalServerError})
+		_, err := linodeClient.CreateNodeBalancer(ctx, linodego.NodeBalancerCreateOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("retries creates on refused connections", func(t *testing.T) {
+		linodeClient := client.WithRetries(mock, client.RetryPolicy{TransientAttempts: 3, Backoff: time.Millisecond})
+		gomock.InOrder(
+			mock.EXPECT().CreateNodeBalancer(gomock.Any(), gomock.Any()).Times(1).Return(nil, syscall.ECONNREFUSED),
+			mock.EXPECT().CreateNodeBalancer(gomock.Any(), gomock.Any()).Times(1).Return(&linodego.NodeBalancer{ID: 456}, nil),
+		)
+		nb, err := linodeClient.CreateNodeBalancer(ctx, linodego.NodeBalancerCreateOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, 456, nb.ID)
+	})
+
+	t.Run("returns the client when retries are disabled", func(t *testing.T) {
+		assert.Same(t, mock, client.WithRetries(mock, client.RetryPolicy{Attempts: 1}))
+	})
+}
+
+// failingTransport fails the first failures requests with a server error, and
+// answers the following ones with body.
+type failingTransport struct {
+	failures int
+	body     string
+	calls    int
+}
+
+func (f *failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.calls++
+	status, body := http.StatusOK, f.body
+	if f.calls <= f.failures {
+		status, body = http.StatusInternalServerError, `{"errors": [{"reason": "Internal Server Error"}]}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func TestWithRetriesTransientErrors(t *testing.T) {
+	ctx := context.Background()
+	transport := &failingTransport{failures: 2, body: `{"id": 123}`}
+	linodeClient := linodego.NewClient(&http.Client{Transport: transport})
+	linodeClient.SetBaseURL("https://api.linode.test")
+	linodeClient.SetRetryCount(0)
+
+	retryClient := client.WithRetries(&linodeClient, client.RetryPolicy{TransientAttempts: 3, Backoff: time.Millisecond})
+	instance, err := retryClient.GetInstance(ctx, 123)
+	assert.NoError(t, err)
+	assert.Equal(t, 123, instance.ID)
+	assert.Equal(t, 3, transport.calls)
+}
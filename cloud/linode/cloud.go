@@ -1,17 +1,25 @@
 package linode
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/linode/linodego"
 	"github.com/spf13/pflag"
 	"golang.org/x/exp/slices"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/klog/v2"
 
 	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client"
 )
@@ -23,20 +31,125 @@ const (
 	regionEnv          = "LINODE_REGION"
 	ciliumLBType       = "cilium-bgp"
 	nodeBalancerLBType = "nodebalancer"
+
+	// policies for handling a TLS secret which has been deleted
+	tlsSecretMissingKeepLastGood = "keep-last-good"
+	tlsSecretMissingFail         = "fail"
+
+	// sources of the addresses used as NodeBalancer backends
+	backendIPSourceNode     = "node"
+	backendIPSourceInstance = "instance"
+
+	// policies for Services for which no nodes are available as backends
+	noBackendNodesKeep  = "keep"
+	noBackendNodesDefer = "defer"
+
+	// policies for the backends of unschedulable (cordoned) nodes
+	unschedulableNodesAccept = "accept"
+	unschedulableNodesDrain  = "drain"
+
+	// policies for nodes which resolve to the backend address of another node
+	duplicateBackendAddressKeepFirst = "keep-first"
+	duplicateBackendAddressFail      = "fail"
+
+	// policies for NodeBalancer configs for ports which are not in the Service
+	configPolicyManageOwned = "manage-only-owned"
+	configPolicyManageAll   = "manage-all"
+
+	// policies for the tags of NodeBalancers not created by the CCM
+	adoptedTagsPreserve = "preserve"
+	adoptedTagsReplace  = "replace"
+
+	// policies for Service ports below privilegedPortLimit
+	privilegedPortsAllow  = "allow"
+	privilegedPortsReject = "reject"
+	privilegedPortLimit   = 1024
 )
 
 var supportedLoadBalancerTypes = []string{ciliumLBType, nodeBalancerLBType}
 
+var supportedTLSSecretMissingPolicies = []string{tlsSecretMissingKeepLastGood, tlsSecretMissingFail}
+
+var supportedBackendIPSources = []string{backendIPSourceNode, backendIPSourceInstance}
+
+var supportedNoBackendNodesPolicies = []string{noBackendNodesKeep, noBackendNodesDefer}
+
+var supportedUnschedulableNodesPolicies = []string{unschedulableNodesAccept, unschedulableNodesDrain}
+
+var supportedDuplicateBackendAddressPolicies = []string{duplicateBackendAddressKeepFirst, duplicateBackendAddressFail}
+
+var supportedConfigPolicies = []string{configPolicyManageOwned, configPolicyManageAll}
+
+var supportedAdoptedTagsPolicies = []string{adoptedTagsPreserve, adoptedTagsReplace}
+
+var supportedPrivilegedPortsPolicies = []string{privilegedPortsAllow, privilegedPortsReject}
+
+var supportedConditionStatuses = []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}
+
+var supportedCheckPathProtocols = []linodego.ConfigProtocol{linodego.ProtocolTCP, linodego.ProtocolHTTP, linodego.ProtocolHTTPS}
+
 // Options is a configuration object for this cloudprovider implementation.
 // We expect it to be initialized with flags external to this package, likely in
 // main.go
 var Options struct {
-	KubeconfigFlag        *pflag.Flag
-	LinodeGoDebug         bool
-	EnableRouteController bool
-	VPCName               string
-	LoadBalancerType      string
-	BGPNodeSelector       string
+	KubeconfigFlag                *pflag.Flag
+	LinodeGoDebug                 bool
+	EnableRouteController         bool
+	VPCName                       string
+	LoadBalancerType              string
+	BGPNodeSelector               string
+	ClusterID                     string
+	BackendHealthGracePeriod      time.Duration
+	BackendIPPreference           string
+	BackendIPSource               string
+	CertExpiryWarningWindow       time.Duration
+	TLSSecretMissingPolicy        string
+	NodeBalancerProvisionTimeout  time.Duration
+	BackendDrainPeriod            time.Duration
+	ServiceNamespaces             []string
+	ExcludedServiceNamespaces     []string
+	NodeBalancerLabelTags         map[string]string
+	RequireProviderID             bool
+	InstanceIDCacheConfigMap      string
+	NoBackendNodesPolicy          string
+	RejectRegionMismatch          bool
+	DefaultCheckPaths             map[string]string
+	NodeBalancerIPRequeueInterval time.Duration
+	EnablePprof                   bool
+	PprofAddress                  string
+	DuplicateBackendAddressPolicy string
+	InstanceLookupConcurrency     int
+	MaxReconcileRetries           int
+	ReconcileRetryCooldown        time.Duration
+	BackendRemovalBatchSize       int
+	BackendRemovalBatchDelay      time.Duration
+	NodeBalancerConfigPolicy      string
+	InstanceClassLabel            bool
+	AdoptedTagsPolicy             string
+	AccountUsageInterval          time.Duration
+	AccountNodeBalancerLimit      int
+	RequiredNodeConditions        map[string]string
+	ExcludedNodeTaints            []string
+	PrivilegedPortsPolicy         string
+	ServiceAPIQPS                 float64
+	ServiceAPIBurst               int
+	AllowCrossNamespaceTLSSecrets bool
+	PreviousClusterID             string
+	NodeBalancerTransferQuota     int
+	TransferWarningThreshold      float64
+	AnnotateNodeBalancerID        bool
+	InstanceCacheTTL              time.Duration
+	MinBackends                   int
+	ZoneFromRegion                bool
+	APIRateLimitAttempts          int
+	APITransientErrorAttempts     int
+	APIRetryBackoff               time.Duration
+	UnschedulableNodesPolicy      string
+	TransitionalInstancesShutdown bool
+	TLSSecretRetryInterval        time.Duration
+	BackendNodeSelector           string
+	AnnotateEffectiveConfig       bool
+	UseMetadataService            bool
 }
 
 // vpcDetails is set when VPCName options flag is set.
@@ -103,23 +216,37 @@ func newCloud() (cloudprovider.Interface, error) {
 		}
 	}
 
-	linodeClient, err := client.New(apiToken, timeout)
+	linodeClient, err := client.New(apiToken, timeout, Options.ClusterID)
 	if err != nil {
 		return nil, fmt.Errorf("client was not created succesfully: %w", err)
 	}
 
+	linodeClient.OnBeforeRequest(waitServiceRateLimit)
+
 	if Options.LinodeGoDebug {
 		linodeClient.SetDebug(true)
 	}
 
+	apiClient := client.WithRetries(linodeClient, client.RetryPolicy{
+		Attempts:          Options.APIRateLimitAttempts,
+		TransientAttempts: Options.APITransientErrorAttempts,
+		Backoff:           Options.APIRetryBackoff,
+		MaxBackoff:        client.DefaultRateLimitMaxBackoff,
+	})
+
 	if Options.VPCName != "" {
-		err := vpcInfo.setDetails(linodeClient, Options.VPCName)
+		err := vpcInfo.setDetails(apiClient, Options.VPCName)
 		if err != nil {
 			return nil, fmt.Errorf("failed finding VPC ID: %w", err)
 		}
 	}
 
-	routes, err := newRoutes(linodeClient)
+	linodeInstances := newInstances(apiClient)
+	if Options.UseMetadataService {
+		linodeInstances.metadata = newMetadataClient()
+		linodeInstances.localNodeName = localNodeName()
+	}
+	routes, err := newRoutes(apiClient, linodeInstances)
 	if err != nil {
 		return nil, fmt.Errorf("routes client was not created successfully: %w", err)
 	}
@@ -132,11 +259,107 @@ func newCloud() (cloudprovider.Interface, error) {
 		)
 	}
 
+	if Options.TLSSecretMissingPolicy != "" && !slices.Contains(supportedTLSSecretMissingPolicies, Options.TLSSecretMissingPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported TLS secret missing policy %s. Options are %v",
+			Options.TLSSecretMissingPolicy,
+			supportedTLSSecretMissingPolicies,
+		)
+	}
+
+	if Options.BackendIPSource != "" && !slices.Contains(supportedBackendIPSources, Options.BackendIPSource) {
+		return nil, fmt.Errorf(
+			"unsupported backend IP source %s. Options are %v",
+			Options.BackendIPSource,
+			supportedBackendIPSources,
+		)
+	}
+
+	if Options.NoBackendNodesPolicy != "" && !slices.Contains(supportedNoBackendNodesPolicies, Options.NoBackendNodesPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported no backend nodes policy %s. Options are %v",
+			Options.NoBackendNodesPolicy,
+			supportedNoBackendNodesPolicies,
+		)
+	}
+
+	if Options.UnschedulableNodesPolicy != "" && !slices.Contains(supportedUnschedulableNodesPolicies, Options.UnschedulableNodesPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported unschedulable nodes policy %s. Options are %v",
+			Options.UnschedulableNodesPolicy,
+			supportedUnschedulableNodesPolicies,
+		)
+	}
+
+	if Options.DuplicateBackendAddressPolicy != "" && !slices.Contains(supportedDuplicateBackendAddressPolicies, Options.DuplicateBackendAddressPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported duplicate backend address policy %s. Options are %v",
+			Options.DuplicateBackendAddressPolicy,
+			supportedDuplicateBackendAddressPolicies,
+		)
+	}
+
+	if Options.NodeBalancerConfigPolicy != "" && !slices.Contains(supportedConfigPolicies, Options.NodeBalancerConfigPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported NodeBalancer config policy %s. Options are %v",
+			Options.NodeBalancerConfigPolicy,
+			supportedConfigPolicies,
+		)
+	}
+
+	if Options.AdoptedTagsPolicy != "" && !slices.Contains(supportedAdoptedTagsPolicies, Options.AdoptedTagsPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported adopted NodeBalancer tags policy %s. Options are %v",
+			Options.AdoptedTagsPolicy,
+			supportedAdoptedTagsPolicies,
+		)
+	}
+
+	if Options.PrivilegedPortsPolicy != "" && !slices.Contains(supportedPrivilegedPortsPolicies, Options.PrivilegedPortsPolicy) {
+		return nil, fmt.Errorf(
+			"unsupported privileged ports policy %s. Options are %v",
+			Options.PrivilegedPortsPolicy,
+			supportedPrivilegedPortsPolicies,
+		)
+	}
+
+	if Options.NodeBalancerTransferQuota > 0 && (Options.TransferWarningThreshold <= 0 || Options.TransferWarningThreshold > 1) {
+		return nil, fmt.Errorf("invalid NodeBalancer transfer warning threshold %v, must be greater than 0 and at most 1", Options.TransferWarningThreshold)
+	}
+
+	for conditionType, status := range Options.RequiredNodeConditions {
+		if !slices.Contains(supportedConditionStatuses, v1.ConditionStatus(status)) {
+			return nil, fmt.Errorf(
+				"unsupported status %s for required node condition %s. Options are %v",
+				status,
+				conditionType,
+				supportedConditionStatuses,
+			)
+		}
+	}
+
+	if _, err := labels.Parse(Options.BackendNodeSelector); err != nil {
+		return nil, fmt.Errorf("invalid backend node selector %q: %w", Options.BackendNodeSelector, err)
+	}
+
+	for protocol, path := range Options.DefaultCheckPaths {
+		if !slices.Contains(supportedCheckPathProtocols, linodego.ConfigProtocol(protocol)) {
+			return nil, fmt.Errorf(
+				"unsupported default check path protocol %s. Options are %v",
+				protocol,
+				supportedCheckPathProtocols,
+			)
+		}
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid default check path %q for protocol %s, must start with /", path, protocol)
+		}
+	}
+
 	// create struct that satisfies cloudprovider.Interface
 	lcloud := &linodeCloud{
-		client:        linodeClient,
-		instances:     newInstances(linodeClient),
-		loadbalancers: newLoadbalancers(linodeClient, region),
+		client:        apiClient,
+		instances:     linodeInstances,
+		loadbalancers: newLoadbalancers(apiClient, region),
 		routes:        routes,
 	}
 	return lcloud, nil
@@ -147,12 +370,63 @@ func (c *linodeCloud) Initialize(clientBuilder cloudprovider.ControllerClientBui
 	sharedInformer := informers.NewSharedInformerFactory(kubeclient, 0)
 	serviceInformer := sharedInformer.Core().V1().Services()
 	nodeInformer := sharedInformer.Core().V1().Nodes()
+	secretInformer := newSecretMetadataInformer(metadata.NewForConfigOrDie(clientBuilder.ConfigOrDie("linode-shared-informers")))
+
+	lbs := c.loadbalancers.(*loadbalancers)
+	lbs.serviceLister = serviceInformer.Lister()
+	lbs.nodeLister = nodeInformer.Lister()
+	// the client and recorder are set before the controllers reconcile
+	// Services concurrently, instead of lazily
+	lbs.kubeClient = kubeclient
+	lbs.eventRecorder = newEventRecorder(kubeclient)
 
-	serviceController := newServiceController(c.loadbalancers.(*loadbalancers), serviceInformer)
+	// the secret controller adds an index to the service informer, which must
+	// happen before the informer is started
+	secretController := newSecretController(lbs, secretInformer, serviceInformer, nodeInformer)
+
+	serviceController := newServiceController(lbs, serviceInformer)
 	go serviceController.Run(stopCh)
 
-	nodeController := newNodeController(kubeclient, c.client, nodeInformer)
+	go secretController.Run(stopCh)
+
+	// the instance ID cache must be set before the instances are shared with
+	// the node controller
+	if Options.InstanceIDCacheConfigMap != "" {
+		if err := c.initInstanceIDCache(kubeclient, stopCh); err != nil {
+			klog.Errorf("instance ID cache is disabled: %s", err)
+		}
+	}
+
+	nodeController := newNodeController(kubeclient, c.instances.(*instances), nodeInformer)
 	go nodeController.Run(stopCh)
+
+	go runPprofServer(stopCh)
+
+	go runAccountUsageReporter(c.client, stopCh)
+
+	// re-tag the NodeBalancers of a previous cluster ID in the background, the
+	// controllers find them by service status and do not wait for it
+	go migrateClusterTag(c.client)
+}
+
+// initInstanceIDCache loads the instance ID cache persisted in
+// Options.InstanceIDCacheConfigMap for the instances to use, and flushes its
+// changes until stopCh is closed.
+func (c *linodeCloud) initInstanceIDCache(kubeclient kubernetes.Interface, stopCh <-chan struct{}) error {
+	instances, ok := c.instances.(*instances)
+	if !ok {
+		return fmt.Errorf("unexpected instances type %T", c.instances)
+	}
+	idCache, err := newInstanceIDCache(kubeclient, Options.InstanceIDCacheConfigMap)
+	if err != nil {
+		return err
+	}
+	if err = idCache.load(context.Background()); err != nil {
+		return fmt.Errorf("failed to load instance ID cache: %w", err)
+	}
+	instances.idCache = idCache
+	go idCache.run(stopCh)
+	return nil
 }
 
 func (c *linodeCloud) LoadBalancer() (cloudprovider.LoadBalancer, bool) {
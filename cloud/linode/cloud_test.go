@@ -1,10 +1,15 @@
 package linode
 
 import (
+	"context"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/linode/linodego"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client"
 )
 
 func TestNewCloudRouteControllerDisabled(t *testing.T) {
@@ -28,3 +33,29 @@ func TestNewCloudRouteControllerDisabled(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestNewCloudUserAgent(t *testing.T) {
+	fake := newFake(t)
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	t.Setenv("LINODE_API_TOKEN", "dummyapitoken")
+	t.Setenv("LINODE_REGION", "us-east")
+	t.Setenv("LINODE_URL", ts.URL+"/"+apiVersion)
+
+	Options.VPCName = ""
+	Options.EnableRouteController = false
+	Options.ClusterID = "test-cluster"
+	defer func() { Options.ClusterID = "" }()
+
+	cloud, err := newCloud()
+	assert.NoError(t, err)
+
+	lcloud := cloud.(*linodeCloud)
+	_, err = lcloud.client.ListNodeBalancers(context.TODO(), nil)
+	assert.NoError(t, err)
+
+	expected := "linode-cloud-controller-manager/" + client.Version + " (cluster test-cluster) " + linodego.DefaultUserAgent
+	assert.Equal(t, expected, client.UserAgent("test-cluster"))
+	assert.Equal(t, map[string]struct{}{expected: {}}, fake.userAgents)
+}
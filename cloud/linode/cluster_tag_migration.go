@@ -0,0 +1,72 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/linode/linodego"
+	"k8s.io/klog/v2"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client"
+)
+
+// clusterTagMigrationTimeout bounds the re-tagging of NodeBalancers on startup.
+const clusterTagMigrationTimeout = 2 * time.Minute
+
+// retagNodeBalancers replaces the oldTag of the NodeBalancers tagged with it by
+// newTag, and returns how many were re-tagged. It is used to carry the
+// NodeBalancers of a cluster over a change of its cluster ID, which they are
+// tagged with. Failing NodeBalancers do not stop the others from being re-tagged.
+func retagNodeBalancers(ctx context.Context, linodeClient client.Client, oldTag, newTag string) (int, error) {
+	filter := fmt.Sprintf(`{"tags": "%s"}`, oldTag)
+	nbs, err := linodeClient.ListNodeBalancers(ctx, &linodego.ListOptions{Filter: filter})
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	retagged := 0
+	for _, nb := range nbs {
+		tags := make([]string, 0, len(nb.Tags))
+		for _, tag := range nb.Tags {
+			if tag == oldTag {
+				tag = newTag
+			}
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		update := nb.GetUpdateOptions()
+		update.Tags = &tags
+		if _, err = linodeClient.UpdateNodeBalancer(ctx, nb.ID, update); err != nil {
+			errs = append(errs, fmt.Errorf("NodeBalancer (%d): %w", nb.ID, err))
+			continue
+		}
+		klog.Infof("re-tagged NodeBalancer (%d) from cluster %s to cluster %s", nb.ID, oldTag, newTag)
+		retagged++
+	}
+	return retagged, errors.Join(errs...)
+}
+
+// migrateClusterTag re-tags the NodeBalancers of Options.PreviousClusterID with
+// Options.ClusterID, if both are set. Errors are logged, the NodeBalancers left
+// behind are re-tagged on the next start.
+func migrateClusterTag(linodeClient client.Client) {
+	if Options.PreviousClusterID == "" || Options.ClusterID == "" || Options.PreviousClusterID == Options.ClusterID {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTagMigrationTimeout)
+	defer cancel()
+
+	retagged, err := retagNodeBalancers(ctx, linodeClient, Options.PreviousClusterID, Options.ClusterID)
+	if err != nil {
+		klog.Errorf("failed to re-tag NodeBalancers from cluster %s to cluster %s: %s", Options.PreviousClusterID, Options.ClusterID, err)
+		return
+	}
+	klog.Infof("re-tagged %d NodeBalancers from cluster %s to cluster %s", retagged, Options.PreviousClusterID, Options.ClusterID)
+}
@@ -0,0 +1,128 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/linode/linodego"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+// redacted replaces the TLS certificates and keys of the effective config.
+const redacted = "<redacted>"
+
+// effectiveConfig is the NodeBalancer configuration applied for a Service,
+// once its annotations are resolved against the CCM flags and defaults.
+type effectiveConfig struct {
+	NodeBalancerID     int                   `json:"nodeBalancerID"`
+	Region             string                `json:"region"`
+	ClientConnThrottle int                   `json:"clientConnThrottle"`
+	Ports              []effectivePortConfig `json:"ports"`
+}
+
+// effectivePortConfig is the configuration applied for a port of a Service.
+type effectivePortConfig struct {
+	Port          int      `json:"port"`
+	Protocol      string   `json:"protocol"`
+	ProxyProtocol string   `json:"proxyProtocol"`
+	Algorithm     string   `json:"algorithm,omitempty"`
+	Stickiness    string   `json:"stickiness,omitempty"`
+	Check         string   `json:"check"`
+	CheckPath     string   `json:"checkPath,omitempty"`
+	CheckBody     string   `json:"checkBody,omitempty"`
+	CheckInterval int      `json:"checkInterval"`
+	CheckTimeout  int      `json:"checkTimeout"`
+	CheckAttempts int      `json:"checkAttempts"`
+	CheckPassive  bool     `json:"checkPassive"`
+	CipherSuite   string   `json:"cipherSuite,omitempty"`
+	TLSSecretName string   `json:"tlsSecretName,omitempty"`
+	TLSHostnames  []string `json:"tlsHostnames,omitempty"`
+	SSLCert       string   `json:"sslCert,omitempty"`
+	SSLKey        string   `json:"sslKey,omitempty"`
+}
+
+// newEffectiveConfig returns the effective configuration of nb for service,
+// with configs the NodeBalancer configs built for its ports. The TLS
+// certificates and keys of the configs are redacted.
+func newEffectiveConfig(service *v1.Service, nb *linodego.NodeBalancer, configs []linodego.NodeBalancerConfig) effectiveConfig {
+	effective := effectiveConfig{
+		NodeBalancerID:     nb.ID,
+		Region:             nb.Region,
+		ClientConnThrottle: nb.ClientConnThrottle,
+		Ports:              make([]effectivePortConfig, 0, len(configs)),
+	}
+	for _, config := range configs {
+		port := effectivePortConfig{
+			Port:          config.Port,
+			Protocol:      string(config.Protocol),
+			ProxyProtocol: string(config.ProxyProtocol),
+			Algorithm:     string(config.Algorithm),
+			Stickiness:    string(config.Stickiness),
+			Check:         string(config.Check),
+			CheckPath:     config.CheckPath,
+			CheckBody:     config.CheckBody,
+			CheckInterval: config.CheckInterval,
+			CheckTimeout:  config.CheckTimeout,
+			CheckAttempts: config.CheckAttempts,
+			CheckPassive:  config.CheckPassive,
+			CipherSuite:   string(config.CipherSuite),
+		}
+		// the port config was parsed when the config was built
+		if portConfig, err := getPortConfig(service, config.Port); err == nil && config.Protocol == linodego.ProtocolHTTPS {
+			port.TLSSecretName = portConfig.TLSSecretName
+			port.TLSHostnames = portConfig.TLSHostnames
+		}
+		if config.SSLCert != "" {
+			port.SSLCert = redacted
+		}
+		if config.SSLKey != "" {
+			port.SSLKey = redacted
+		}
+		effective.Ports = append(effective.Ports, port)
+	}
+	return effective
+}
+
+// reportEffectiveConfig logs the effective configuration of nb for service,
+// and sets it as the AnnLinodeEffectiveConfig annotation of service when
+// Options.AnnotateEffectiveConfig is enabled. Failing to annotate the Service
+// does not fail the reconcile.
+func (l *loadbalancers) reportEffectiveConfig(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer, configs []linodego.NodeBalancerConfig) {
+	data, err := json.Marshal(newEffectiveConfig(service, nb, configs))
+	if err != nil {
+		klog.Warningf("failed to encode the effective configuration of NodeBalancer (%d) for service (%s): %s", nb.ID, getServiceNn(service), err)
+		return
+	}
+	klog.Infof("effective configuration of NodeBalancer (%d) for service (%s): %s", nb.ID, getServiceNn(service), data)
+
+	if !Options.AnnotateEffectiveConfig || service.Annotations[annotations.AnnLinodeEffectiveConfig] == string(data) {
+		return
+	}
+	if err = l.annotateEffectiveConfig(ctx, service, string(data)); err != nil {
+		klog.Warningf("failed to annotate service (%s) with the effective configuration of NodeBalancer (%d): %s", getServiceNn(service), nb.ID, err)
+	}
+}
+
+// annotateEffectiveConfig sets config as the AnnLinodeEffectiveConfig
+// annotation of service.
+func (l *loadbalancers) annotateEffectiveConfig(ctx context.Context, service *v1.Service, config string) error {
+	if err := l.retrieveKubeClient(); err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{annotations.AnnLinodeEffectiveConfig: config},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = l.kubeClient.CoreV1().Services(service.Namespace).Patch(ctx, service.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,122 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+func TestNewEffectiveConfig(t *testing.T) {
+	Options.DefaultCheckPaths = map[string]string{"http": "/healthz"}
+	defer func() { Options.DefaultCheckPaths = nil }()
+
+	kubeClient := fake.NewSimpleClientset()
+	addTLSSecret(t, kubeClient)
+	lb := &loadbalancers{kubeClient: kubeClient}
+	nb := &linodego.NodeBalancer{ID: 1, Region: "us-west", ClientConnThrottle: 20}
+
+	for _, test := range []struct {
+		name        string
+		annotations map[string]string
+		expected    []effectivePortConfig
+	}{
+		{
+			name: "defaults",
+			expected: []effectivePortConfig{
+				{Port: 80, Protocol: "tcp", ProxyProtocol: "none", Check: "connection", CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 2, CheckPassive: true},
+			},
+		},
+		{
+			name: "port configs override service annotations which override the CCM flags",
+			annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol:           "http",
+				annotations.AnnLinodeDefaultProxyProtocol:      "v1",
+				annotations.AnnLinodeHealthCheckType:           "http",
+				annotations.AnnLinodeHealthCheckAttempts:       "4",
+				annotations.AnnLinodePortConfigPrefix + "443":  `{ "protocol": "https", "tls-secret-name": "tls-secret" }`,
+				annotations.AnnLinodePortConfigPrefix + "8443": `{ "protocol": "tcp", "proxy-protocol": "v2" }`,
+				annotations.AnnLinodePortConfigPrefix + "9443": `{ "protocol": "tcp" }`,
+			},
+			expected: []effectivePortConfig{
+				{Port: 80, Protocol: "http", ProxyProtocol: "none", Check: "http", CheckPath: "/healthz", CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 4, CheckPassive: true},
+				{Port: 443, Protocol: "https", ProxyProtocol: "none", Check: "http", CheckPath: "/", CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 4, CheckPassive: true, TLSSecretName: "tls-secret", SSLCert: redacted, SSLKey: redacted},
+				{Port: 8443, Protocol: "tcp", ProxyProtocol: "v2", Check: "http", CheckPath: "/", CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 4, CheckPassive: true},
+				{Port: 9443, Protocol: "tcp", ProxyProtocol: "v1", Check: "http", CheckPath: "/", CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 4, CheckPassive: true},
+			},
+		},
+		{
+			name: "check path annotation overrides the CCM flag",
+			annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol: "http",
+				annotations.AnnLinodeHealthCheckType: "http",
+				annotations.AnnLinodeCheckPath:       "/ready",
+			},
+			expected: []effectivePortConfig{
+				{Port: 80, Protocol: "http", ProxyProtocol: "none", Check: "http", CheckPath: "/ready", CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 2, CheckPassive: true},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: test.annotations}}
+
+			configs := make([]linodego.NodeBalancerConfig, 0, len(test.expected))
+			for _, port := range test.expected {
+				config, err := lb.buildNodeBalancerConfig(context.TODO(), svc, port.Port, 1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				configs = append(configs, config)
+			}
+
+			effective := newEffectiveConfig(svc, nb, configs)
+			assert.Equal(t, effectiveConfig{NodeBalancerID: 1, Region: "us-west", ClientConnThrottle: 20, Ports: test.expected}, effective)
+
+			data, err := json.Marshal(effective)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.NotContains(t, string(data), "PRIVATE KEY")
+			assert.NotContains(t, string(data), "BEGIN CERTIFICATE")
+		})
+	}
+}
+
+func TestReportEffectiveConfig(t *testing.T) {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	kubeClient := fake.NewSimpleClientset(svc)
+	lb := &loadbalancers{kubeClient: kubeClient}
+	nb := &linodego.NodeBalancer{ID: 1, Region: "us-west"}
+	configs := []linodego.NodeBalancerConfig{{Port: 80, Protocol: linodego.ProtocolTCP}}
+
+	getAnnotation := func() string {
+		t.Helper()
+		current, err := kubeClient.CoreV1().Services(svc.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return current.Annotations[annotations.AnnLinodeEffectiveConfig]
+	}
+
+	t.Run("not annotated by default", func(t *testing.T) {
+		lb.reportEffectiveConfig(context.TODO(), svc, nb, configs)
+		assert.Empty(t, getAnnotation())
+	})
+
+	t.Run("annotated when enabled", func(t *testing.T) {
+		Options.AnnotateEffectiveConfig = true
+		defer func() { Options.AnnotateEffectiveConfig = false }()
+
+		lb.reportEffectiveConfig(context.TODO(), svc, nb, configs)
+		annotation := getAnnotation()
+		assert.True(t, strings.HasPrefix(annotation, `{"nodeBalancerID":1,"region":"us-west"`), annotation)
+	})
+}
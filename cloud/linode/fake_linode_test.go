@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -26,8 +27,16 @@ type fakeAPI struct {
 	fw  map[int]*linodego.Firewall               // map of firewallID -> firewall
 	fwd map[int]map[int]*linodego.FirewallDevice // map of firewallID -> firewallDeviceID:FirewallDevice
 
-	requests map[fakeRequest]struct{}
-	mux      *http.ServeMux
+	instances     map[int]*linodego.Instance
+	instancesCode int // status code listing instances fails with, if set
+
+	pendingNodeBalancerIPs bool // NodeBalancers are created without an IP assigned, if set
+	// orders the listed NodeBalancer configs, if set
+	configsOrder func(a, b linodego.NodeBalancerConfig) int
+
+	requests   map[fakeRequest]struct{}
+	userAgents map[string]struct{}
+	mux        *http.ServeMux
 }
 
 type fakeRequest struct {
@@ -38,14 +47,16 @@ type fakeRequest struct {
 
 func newFake(t *testing.T) *fakeAPI {
 	fake := &fakeAPI{
-		t:        t,
-		nb:       make(map[string]*linodego.NodeBalancer),
-		nbc:      make(map[string]*linodego.NodeBalancerConfig),
-		nbn:      make(map[string]*linodego.NodeBalancerNode),
-		fw:       make(map[int]*linodego.Firewall),
-		fwd:      make(map[int]map[int]*linodego.FirewallDevice),
-		requests: make(map[fakeRequest]struct{}),
-		mux:      http.NewServeMux(),
+		t:          t,
+		nb:         make(map[string]*linodego.NodeBalancer),
+		nbc:        make(map[string]*linodego.NodeBalancerConfig),
+		nbn:        make(map[string]*linodego.NodeBalancerNode),
+		fw:         make(map[int]*linodego.Firewall),
+		fwd:        make(map[int]map[int]*linodego.FirewallDevice),
+		instances:  make(map[int]*linodego.Instance),
+		requests:   make(map[fakeRequest]struct{}),
+		userAgents: make(map[string]struct{}),
+		mux:        http.NewServeMux(),
 	}
 	fake.setupRoutes()
 	return fake
@@ -53,6 +64,7 @@ func newFake(t *testing.T) *fakeAPI {
 
 func (f *fakeAPI) ResetRequests() {
 	f.requests = make(map[fakeRequest]struct{})
+	f.userAgents = make(map[string]struct{})
 }
 
 func (f *fakeAPI) recordRequest(r *http.Request, urlPath string) {
@@ -64,6 +76,7 @@ func (f *fakeAPI) recordRequest(r *http.Request, urlPath string) {
 		Method: r.Method,
 		Body:   string(bodyBytes),
 	}] = struct{}{}
+	f.userAgents[r.UserAgent()] = struct{}{}
 }
 
 func (f *fakeAPI) didRequestOccur(method, path, body string) bool {
@@ -92,7 +105,8 @@ func (f *fakeAPI) setupRoutes() {
 			}
 			for _, n := range f.nb {
 				if (n.Label != nil && fs["label"] != "" && *n.Label == fs["label"]) ||
-					(fs["ipv4"] != "" && n.IPv4 != nil && *n.IPv4 == fs["ipv4"]) {
+					(fs["ipv4"] != "" && n.IPv4 != nil && *n.IPv4 == fs["ipv4"]) ||
+					(fs["tags"] != "" && slices.Contains(n.Tags, fs["tags"])) {
 					data = append(data, *n)
 				}
 			}
@@ -109,6 +123,34 @@ func (f *fakeAPI) setupRoutes() {
 		_, _ = w.Write(rr)
 	})
 
+	f.mux.HandleFunc("GET /v4/linode/instances", func(w http.ResponseWriter, r *http.Request) {
+		if f.instancesCode != 0 {
+			w.WriteHeader(f.instancesCode)
+			rr, _ := json.Marshal(linodego.APIError{
+				Errors: []linodego.APIErrorReason{
+					{Reason: http.StatusText(f.instancesCode)},
+				},
+			})
+			_, _ = w.Write(rr)
+			return
+		}
+
+		data := []linodego.Instance{}
+		for _, instance := range f.instances {
+			data = append(data, *instance)
+		}
+		resp := linodego.InstancesPagedResponse{
+			PageOptions: &linodego.PageOptions{
+				Page:    1,
+				Pages:   1,
+				Results: len(data),
+			},
+			Data: data,
+		}
+		rr, _ := json.Marshal(resp)
+		_, _ = w.Write(rr)
+	})
+
 	f.mux.HandleFunc("GET /v4/nodebalancers/{nodeBalancerId}", func(w http.ResponseWriter, r *http.Request) {
 		nb, found := f.nb[r.PathValue("nodeBalancerId")]
 		if !found {
@@ -157,14 +199,14 @@ func (f *fakeAPI) setupRoutes() {
 		_, _ = w.Write(resp)
 	})
 
-	// TODO: note that we discard `nodeBalancerId`
 	f.mux.HandleFunc("GET /v4/nodebalancers/{nodeBalancerId}/configs", func(w http.ResponseWriter, r *http.Request) {
-		res := 0
 		data := []linodego.NodeBalancerConfig{}
 		filter := r.Header.Get("X-Filter")
 		if filter == "" {
 			for _, n := range f.nbc {
-				data = append(data, *n)
+				if strconv.Itoa(n.NodeBalancerID) == r.PathValue("nodeBalancerId") {
+					data = append(data, *n)
+				}
 			}
 		} else {
 			var fs map[string]string
@@ -178,13 +220,15 @@ func (f *fakeAPI) setupRoutes() {
 				}
 			}
 		}
+		if f.configsOrder != nil {
+			slices.SortFunc(data, f.configsOrder)
+		} else {
+			slices.SortFunc(data, func(a, b linodego.NodeBalancerConfig) int { return a.ID - b.ID })
+		}
+		data, pageOptions := paginate(r, data)
 		resp := linodego.NodeBalancerConfigsPagedResponse{
-			PageOptions: &linodego.PageOptions{
-				Page:    1,
-				Pages:   1,
-				Results: res,
-			},
-			Data: data,
+			PageOptions: pageOptions,
+			Data:        data,
 		}
 		rr, err := json.Marshal(resp)
 		if err != nil {
@@ -194,7 +238,6 @@ func (f *fakeAPI) setupRoutes() {
 	})
 
 	f.mux.HandleFunc("GET /v4/nodebalancers/{nodeBalancerId}/configs/{configId}/nodes", func(w http.ResponseWriter, r *http.Request) {
-		res := 0
 		nbcID, err := strconv.Atoi(r.PathValue("configId"))
 		if err != nil {
 			f.t.Fatal(err)
@@ -207,14 +250,12 @@ func (f *fakeAPI) setupRoutes() {
 				data = append(data, *nbn)
 			}
 		}
+		slices.SortFunc(data, func(a, b linodego.NodeBalancerNode) int { return a.ID - b.ID })
 
+		data, pageOptions := paginate(r, data)
 		resp := linodego.NodeBalancerNodesPagedResponse{
-			PageOptions: &linodego.PageOptions{
-				Page:    1,
-				Pages:   1,
-				Results: res,
-			},
-			Data: data,
+			PageOptions: pageOptions,
+			Data:        data,
 		}
 		rr, _ := json.Marshal(resp)
 		_, _ = w.Write(rr)
@@ -257,15 +298,20 @@ func (f *fakeAPI) setupRoutes() {
 		}
 
 		ip := net.IPv4(byte(rand.Intn(100)), byte(rand.Intn(100)), byte(rand.Intn(100)), byte(rand.Intn(100))).String()
+		ipv6 := fmt.Sprintf("2600:3c00::f03c:91ff:fe%02x:%04x", rand.Intn(256), rand.Intn(65536))
 		hostname := fmt.Sprintf("nb-%s.%s.linode.com", strings.Replace(ip, ".", "-", 4), strings.ToLower(nbco.Region))
 		nb := linodego.NodeBalancer{
 			ID:       rand.Intn(9999),
 			Label:    nbco.Label,
 			Region:   nbco.Region,
 			IPv4:     &ip,
+			IPv6:     &ipv6,
 			Hostname: &hostname,
 			Tags:     nbco.Tags,
 		}
+		if f.pendingNodeBalancerIPs {
+			nb.IPv4, nb.IPv6, nb.Hostname = nil, nil, nil
+		}
 
 		if nbco.ClientConnThrottle != nil {
 			nb.ClientConnThrottle = *nbco.ClientConnThrottle
@@ -273,6 +319,7 @@ func (f *fakeAPI) setupRoutes() {
 		f.nb[strconv.Itoa(nb.ID)] = &nb
 
 		for _, nbcco := range nbco.Configs {
+			f.validateUDPConfig(nbcco.Protocol, nbcco.ProxyProtocol, nbcco.SSLCert, nbcco.SSLKey)
 			if nbcco.Protocol == "https" {
 				if !strings.Contains(nbcco.SSLCert, "BEGIN CERTIFICATE") {
 					f.t.Fatal("HTTPS port declared without calid ssl cert", nbcco.SSLCert)
@@ -306,7 +353,7 @@ func (f *fakeAPI) setupRoutes() {
 
 			for _, nbnco := range nbcco.Nodes {
 				nbn := linodego.NodeBalancerNode{
-					ID:             rand.Intn(99999),
+					ID:             f.newNodeID(),
 					Address:        nbnco.Address,
 					Label:          nbnco.Label,
 					Weight:         nbnco.Weight,
@@ -337,6 +384,7 @@ func (f *fakeAPI) setupRoutes() {
 		if err := json.NewDecoder(r.Body).Decode(nbcco); err != nil {
 			f.t.Fatal(err)
 		}
+		f.validateUDPConfig(nbcco.Protocol, nbcco.ProxyProtocol, nbcco.SSLCert, nbcco.SSLKey)
 		nbid, err := strconv.Atoi(r.PathValue("nodeBalancerId"))
 		if err != nil {
 			f.t.Fatal(err)
@@ -377,6 +425,7 @@ func (f *fakeAPI) setupRoutes() {
 		if err := json.NewDecoder(r.Body).Decode(nbcco); err != nil {
 			f.t.Fatal(err)
 		}
+		f.validateUDPConfig(nbcco.Protocol, nbcco.ProxyProtocol, nbcco.SSLCert, nbcco.SSLKey)
 		nbid, err := strconv.Atoi(r.PathValue("nodeBalancerId"))
 		if err != nil {
 			f.t.Fatal(err)
@@ -385,7 +434,10 @@ func (f *fakeAPI) setupRoutes() {
 		if err != nil {
 			f.t.Fatal(err)
 		}
-		if nbcco.Protocol == "https" {
+		// the API keeps the existing certificate of an https config when none is sent
+		existing, ok := f.nbc[strconv.Itoa(nbcid)]
+		keepCert := ok && existing.Protocol == "https" && nbcco.SSLCert == "" && nbcco.SSLKey == ""
+		if nbcco.Protocol == "https" && !keepCert {
 			if !strings.Contains(nbcco.SSLCert, "BEGIN CERTIFICATE") {
 				f.t.Fatal("HTTPS port declared without calid ssl cert", nbcco.SSLCert)
 			}
@@ -424,7 +476,7 @@ func (f *fakeAPI) setupRoutes() {
 
 		for _, n := range nbcco.Nodes {
 			node := linodego.NodeBalancerNode{
-				ID:             rand.Intn(99999),
+				ID:             f.newNodeID(),
 				Address:        n.Address,
 				Label:          n.Label,
 				Weight:         n.Weight,
@@ -550,6 +602,7 @@ func (f *fakeAPI) setupRoutes() {
 		if err := json.NewDecoder(r.Body).Decode(nbcco); err != nil {
 			f.t.Fatal(err)
 		}
+		f.validateUDPConfig(nbcco.Protocol, nbcco.ProxyProtocol, nbcco.SSLCert, nbcco.SSLKey)
 		nbcid, err := strconv.Atoi(r.PathValue("configId"))
 		if err != nil {
 			f.t.Fatal(err)
@@ -584,7 +637,7 @@ func (f *fakeAPI) setupRoutes() {
 
 		for _, n := range nbcco.Nodes {
 			node := linodego.NodeBalancerNode{
-				ID:             rand.Intn(99999),
+				ID:             f.newNodeID(),
 				Address:        n.Address,
 				Label:          n.Label,
 				Weight:         n.Weight,
@@ -603,6 +656,45 @@ func (f *fakeAPI) setupRoutes() {
 		_, _ = w.Write(resp)
 	})
 
+	f.mux.HandleFunc("PUT /v4/nodebalancers/{nodeBalancerId}/configs/{configId}/nodes/{nodeId}", func(w http.ResponseWriter, r *http.Request) {
+		nbnuo := new(linodego.NodeBalancerNodeUpdateOptions)
+		if err := json.NewDecoder(r.Body).Decode(nbnuo); err != nil {
+			f.t.Fatal(err)
+		}
+
+		node, found := f.nbn[r.PathValue("nodeId")]
+		if !found {
+			w.WriteHeader(404)
+			resp := linodego.APIError{
+				Errors: []linodego.APIErrorReason{
+					{Reason: "Not Found"},
+				},
+			}
+			rr, _ := json.Marshal(resp)
+			_, _ = w.Write(rr)
+			return
+		}
+
+		if nbnuo.Address != "" {
+			node.Address = nbnuo.Address
+		}
+		if nbnuo.Label != "" {
+			node.Label = nbnuo.Label
+		}
+		if nbnuo.Weight != 0 {
+			node.Weight = nbnuo.Weight
+		}
+		if nbnuo.Mode != "" {
+			node.Mode = nbnuo.Mode
+		}
+
+		resp, err := json.Marshal(node)
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		_, _ = w.Write(resp)
+	})
+
 	f.mux.HandleFunc("PUT /v4/networking/firewalls/{firewallID}/rules", func(w http.ResponseWriter, r *http.Request) {
 		fwrs := new(linodego.FirewallRuleSet)
 		if err := json.NewDecoder(r.Body).Decode(fwrs); err != nil {
@@ -687,6 +779,53 @@ func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	f.mux.ServeHTTP(w, r)
 }
 
+// newNodeID returns an ID for a new NodeBalancer node which is not in use.
+func (f *fakeAPI) newNodeID() int {
+	for {
+		id := rand.Intn(99999)
+		if _, ok := f.nbn[strconv.Itoa(id)]; !ok {
+			return id
+		}
+	}
+}
+
+// fakePageSize is the number of results per page the API returns when the
+// page_size query parameter is not set.
+const fakePageSize = 100
+
+// paginate returns the page of data requested with the page and page_size query
+// parameters of r, and the page options to report with it, as the API does.
+// data must be sorted for pages to be consistent across requests.
+func paginate[T any](r *http.Request, data []T) ([]T, *linodego.PageOptions) {
+	pageSize := fakePageSize
+	if size, err := strconv.Atoi(r.URL.Query().Get("page_size")); err == nil && size > 0 {
+		pageSize = size
+	}
+	page := 1
+	if p, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && p > 0 {
+		page = p
+	}
+
+	pages := max(1, (len(data)+pageSize-1)/pageSize)
+	start := min((page-1)*pageSize, len(data))
+	end := min(start+pageSize, len(data))
+	return data[start:end], &linodego.PageOptions{Page: page, Pages: pages, Results: len(data)}
+}
+
+// validateUDPConfig fails the test when a udp config is sent with settings
+// the API rejects for it, which only apply to TCP based protocols.
+func (f *fakeAPI) validateUDPConfig(protocol linodego.ConfigProtocol, proxyProtocol linodego.ConfigProxyProtocol, sslCert, sslKey string) {
+	if protocol != "udp" {
+		return
+	}
+	if proxyProtocol != "" && proxyProtocol != linodego.ProxyProtocolNone {
+		f.t.Fatal("UDP port declared with proxy protocol", proxyProtocol)
+	}
+	if sslCert != "" || sslKey != "" {
+		f.t.Fatal("UDP port declared with an ssl cert")
+	}
+}
+
 func createFirewallDevice(fwId int, f *fakeAPI, fdco linodego.FirewallDeviceCreateOptions) linodego.FirewallDevice {
 	fwd := linodego.FirewallDevice{
 		ID: fdco.ID,
@@ -248,6 +248,12 @@ func (l *LinodeClient) UpdateNodeBalancerFirewall(
 	}
 
 	// No firewall ID or ACL annotation, see if there are firewalls attached to our nb
+	return l.DetachNodeBalancerFirewall(ctx, nb)
+}
+
+// DetachNodeBalancerFirewall removes the firewall attached to nb, if any, and
+// deletes it once nothing else is attached to it.
+func (l *LinodeClient) DetachNodeBalancerFirewall(ctx context.Context, nb *linodego.NodeBalancer) error {
 	firewalls, err := l.Client.ListNodeBalancerFirewalls(ctx, nb.ID, &linodego.ListOptions{})
 	if err != nil {
 		return err
@@ -0,0 +1,162 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appscode/go/wait"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// instanceIDCacheFlushInterval is how often changes to the instance ID cache
+// are written to its ConfigMap, so that the lookups of many nodes on startup
+// are persisted in a single write.
+const instanceIDCacheFlushInterval = 30 * time.Second
+
+// instanceIDCache persists the IDs of the linodes backing nodes, by node name,
+// in a ConfigMap. It is loaded on startup so that nodes can be looked up by ID
+// before the instance cache is first filled, which requires listing all
+// linodes. Entries are only trusted until the linode they point at is not found,
+// and are dropped when their node is deleted.
+type instanceIDCache struct {
+	mu         sync.Mutex
+	kubeClient kubernetes.Interface
+	namespace  string
+	name       string
+	ids        map[string]int
+	// dirty is set when ids changed since they were last persisted
+	dirty bool
+}
+
+// newInstanceIDCache returns a cache persisted in the ConfigMap referenced as
+// <namespace>/<name>.
+func newInstanceIDCache(kubeClient kubernetes.Interface, ref string) (*instanceIDCache, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid instance ID cache ConfigMap %q, expected <namespace>/<name>", ref)
+	}
+	return &instanceIDCache{
+		kubeClient: kubeClient,
+		namespace:  namespace,
+		name:       name,
+		ids:        make(map[string]int),
+	}, nil
+}
+
+// load reads the persisted IDs, ignoring malformed entries.
+func (c *instanceIDCache) load(ctx context.Context) error {
+	configMap, err := c.kubeClient.CoreV1().ConfigMaps(c.namespace).Get(ctx, c.name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for nodeName, raw := range configMap.Data {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			klog.Warningf("ignoring malformed entry %s=%q of instance ID cache %s/%s", nodeName, raw, c.namespace, c.name)
+			continue
+		}
+		c.ids[nodeName] = id
+	}
+	klog.Infof("loaded %d entries from instance ID cache %s/%s", len(c.ids), c.namespace, c.name)
+	return nil
+}
+
+// get returns the ID of the linode last known to back the node.
+func (c *instanceIDCache) get(nodeName string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, ok := c.ids[nodeName]
+	return id, ok
+}
+
+// set records the ID of the linode backing the node, to be persisted on the
+// next flush if it changed.
+func (c *instanceIDCache) set(nodeName string, id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.ids[nodeName]; ok && current == id {
+		return
+	}
+	c.ids[nodeName] = id
+	c.dirty = true
+}
+
+// forget drops the entry of the node, which is stale or whose node was
+// deleted, to be persisted on the next flush.
+func (c *instanceIDCache) forget(nodeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.ids[nodeName]; !ok {
+		return
+	}
+	delete(c.ids, nodeName)
+	c.dirty = true
+}
+
+// run flushes the cache every instanceIDCacheFlushInterval until stopCh is closed.
+func (c *instanceIDCache) run(stopCh <-chan struct{}) {
+	wait.Until(func() { c.flush(context.Background()) }, instanceIDCacheFlushInterval, stopCh)
+}
+
+// flush persists the cached IDs if they changed since the last flush. Failures
+// are only logged and retried on the next flush, as the cache is an
+// optimization and is rebuilt from lookups.
+func (c *instanceIDCache) flush(ctx context.Context) {
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return
+	}
+	c.dirty = false
+	data := make(map[string]string, len(c.ids))
+	for nodeName, id := range c.ids {
+		data[nodeName] = strconv.Itoa(id)
+	}
+	c.mu.Unlock()
+
+	if err := c.persist(ctx, data); err != nil {
+		klog.Warningf("failed to persist instance ID cache %s/%s: %s", c.namespace, c.name, err)
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+	}
+}
+
+// persist writes data to the ConfigMap.
+func (c *instanceIDCache) persist(ctx context.Context, data map[string]string) error {
+
+	configMaps := c.kubeClient.CoreV1().ConfigMaps(c.namespace)
+	configMap, err := configMaps.Get(ctx, c.name, metav1.GetOptions{})
+	switch {
+	case k8serrors.IsNotFound(err):
+		_, err = configMaps.Create(ctx, &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: c.namespace, Name: c.name},
+			Data:       data,
+		}, metav1.CreateOptions{})
+	case err == nil:
+		if maps.Equal(configMap.Data, data) {
+			return nil
+		}
+		configMap.Data = data
+		_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package linode
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
+)
+
+func newInstanceIDCacheConfigMap(data map[string]string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "instance-ids"},
+		Data:       data,
+	}
+}
+
+func TestInstanceIDCache(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+
+	newWarmedInstances := func(t *testing.T, kubeClient *fake.Clientset) *instances {
+		t.Helper()
+		idCache, err := newInstanceIDCache(kubeClient, "kube-system/instance-ids")
+		assert.NoError(t, err)
+		assert.NoError(t, idCache.load(ctx))
+
+		instances := newInstances(client)
+		instances.idCache = idCache
+		return instances
+	}
+
+	t.Run("invalid ConfigMap reference", func(t *testing.T) {
+		_, err := newInstanceIDCache(fake.NewSimpleClientset(), "instance-ids")
+		assert.Error(t, err)
+	})
+
+	t.Run("warmed cache avoids listing instances", func(t *testing.T) {
+		kubeClient := fake.NewSimpleClientset(newInstanceIDCacheConfigMap(map[string]string{
+			"node-a": "123",
+			"node-b": "malformed",
+		}))
+		instances := newWarmedInstances(t, kubeClient)
+
+		client.EXPECT().GetInstance(gomock.Any(), 123).Times(1).Return(&linodego.Instance{ID: 123, Label: "node-a"}, nil)
+
+		exists, err := instances.InstanceExists(ctx, nodeWithName("node-a"))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		// the fetched instance is reused until the cache TTL expires
+		exists, err = instances.InstanceExists(ctx, nodeWithName("node-a"))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("stale entry is refreshed on not found", func(t *testing.T) {
+		kubeClient := fake.NewSimpleClientset(newInstanceIDCacheConfigMap(map[string]string{"node-a": "999"}))
+		instances := newWarmedInstances(t, kubeClient)
+
+		client.EXPECT().GetInstance(gomock.Any(), 999).Times(1).Return(nil, &linodego.Error{Code: http.StatusNotFound})
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{{ID: 123, Label: "node-a"}}, nil)
+
+		exists, err := instances.InstanceExists(ctx, nodeWithName("node-a"))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		instances.idCache.flush(ctx)
+		configMap, err := kubeClient.CoreV1().ConfigMaps("kube-system").Get(ctx, "instance-ids", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"node-a": "123"}, configMap.Data)
+	})
+
+	t.Run("lookups are persisted in a single write", func(t *testing.T) {
+		kubeClient := fake.NewSimpleClientset()
+		instances := newWarmedInstances(t, kubeClient)
+
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{
+			{ID: 456, Label: "node-c"},
+			{ID: 789, Label: "node-d"},
+		}, nil)
+
+		for _, name := range []string{"node-c", "node-d"} {
+			exists, err := instances.InstanceExists(ctx, nodeWithName(name))
+			assert.NoError(t, err)
+			assert.True(t, exists)
+		}
+
+		kubeClient.ClearActions()
+		instances.idCache.flush(ctx)
+		instances.idCache.flush(ctx)
+		writes := 0
+		for _, action := range kubeClient.Actions() {
+			if action.GetVerb() == "create" || action.GetVerb() == "update" {
+				writes++
+			}
+		}
+		assert.Equal(t, 1, writes)
+
+		configMap, err := kubeClient.CoreV1().ConfigMaps("kube-system").Get(ctx, "instance-ids", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"node-c": "456", "node-d": "789"}, configMap.Data)
+	})
+
+	t.Run("deleted nodes are pruned", func(t *testing.T) {
+		kubeClient := fake.NewSimpleClientset(newInstanceIDCacheConfigMap(map[string]string{"node-a": "123", "node-b": "456"}))
+		instances := newWarmedInstances(t, kubeClient)
+
+		instances.forgetNode("node-b")
+		instances.idCache.flush(ctx)
+
+		configMap, err := kubeClient.CoreV1().ConfigMaps("kube-system").Get(ctx, "instance-ids", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"node-a": "123"}, configMap.Data)
+	})
+}
@@ -2,7 +2,10 @@ package linode
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"slices"
 	"strconv"
@@ -14,6 +17,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	cloudprovider "k8s.io/cloud-provider"
+	cloudproviderapi "k8s.io/cloud-provider/api"
 	"k8s.io/klog/v2"
 
 	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client"
@@ -28,6 +32,8 @@ type nodeIP struct {
 type linodeInstance struct {
 	instance *linodego.Instance
 	ips      []nodeIP
+	// fetched is when the instance was fetched by ID, before the cache was first filled
+	fetched time.Time
 }
 
 type nodeCache struct {
@@ -48,32 +54,46 @@ func (nc *nodeCache) getInstanceAddresses(instance linodego.Instance, vpcips []s
 	}
 
 	for _, ip := range instance.IPv4 {
-		ipType := v1.NodeExternalIP
-		if ip.IsPrivate() {
-			ipType = v1.NodeInternalIP
-		}
-		ips = append(ips, nodeIP{ip: ip.String(), ipType: ipType})
+		ips = append(ips, nodeIP{ip: ip.String(), ipType: getAddressType(*ip)})
 	}
 
 	if instance.IPv6 != "" {
-		ips = append(ips, nodeIP{ip: strings.TrimSuffix(instance.IPv6, "/128"), ipType: v1.NodeExternalIP})
+		ip := strings.TrimSuffix(instance.IPv6, "/128")
+		ipType := v1.NodeExternalIP
+		if parsed := net.ParseIP(ip); parsed != nil {
+			ipType = getAddressType(parsed)
+		}
+		ips = append(ips, nodeIP{ip: ip, ipType: ipType})
 	}
 
 	return ips
 }
 
+// getAddressType classifies ip as internal when it is not routable on the
+// internet: RFC 1918 IPv4 and unique local IPv6 (fc00::/7) ranges, and
+// link-local addresses. Any other address, such as global unicast IPv6, is
+// external.
+func getAddressType(ip net.IP) v1.NodeAddressType {
+	if ip.IsPrivate() || ip.IsLinkLocalUnicast() {
+		return v1.NodeInternalIP
+	}
+	return v1.NodeExternalIP
+}
+
 // refreshInstances conditionally loads all instances from the Linode API and caches them.
-// It does not refresh if the last update happened less than `nodeCache.ttl` ago.
-func (nc *nodeCache) refreshInstances(ctx context.Context, client client.Client) error {
+// It does not refresh if the last update happened less than `nodeCache.ttl` ago,
+// and after notBefore.
+func (nc *nodeCache) refreshInstances(ctx context.Context, client client.Client, notBefore time.Time) error {
 	nc.Lock()
 	defer nc.Unlock()
 
-	if time.Since(nc.lastUpdate) < nc.ttl {
+	if time.Since(nc.lastUpdate) < nc.ttl && nc.lastUpdate.After(notBefore) {
 		return nil
 	}
 
 	instances, err := client.ListInstances(ctx, nil)
 	if err != nil {
+		observeInstanceAPIError(err)
 		return err
 	}
 
@@ -83,6 +103,7 @@ func (nc *nodeCache) refreshInstances(ctx context.Context, client client.Client)
 	if vpcID != 0 {
 		resp, err := client.ListVPCIPAddresses(ctx, vpcID, linodego.NewListOptions(0, ""))
 		if err != nil {
+			observeInstanceAPIError(err)
 			return err
 		}
 		for _, r := range resp {
@@ -116,21 +137,61 @@ type instances struct {
 	client client.Client
 
 	nodeCache *nodeCache
+	idCache   *instanceIDCache
+
+	// metadata reads the ID of the linode backing localNodeName, the node
+	// the CCM runs on, when Options.UseMetadataService is set.
+	metadata      *metadataClient
+	localNodeName string
+
+	// lookupSlots bounds the number of concurrent instance lookups, so that
+	// the burst of lookups on startup does not trip the API rate limits. It is
+	// nil when lookups are unbounded.
+	lookupSlots chan struct{}
+}
+
+// invalidate drops the cached linode id, e.g. once the API reports it deleted.
+func (nc *nodeCache) invalidate(id int) {
+	nc.Lock()
+	defer nc.Unlock()
+	delete(nc.nodes, id)
 }
 
 func newInstances(client client.Client) *instances {
-	timeout := 15
+	ttl := 15 * time.Second
 	if raw, ok := os.LookupEnv("LINODE_INSTANCE_CACHE_TTL"); ok {
 		if t, _ := strconv.Atoi(raw); t > 0 {
-			timeout = t
+			ttl = time.Duration(t) * time.Second
 		}
 	}
-	klog.V(3).Infof("TTL for nodeCache set to %d", timeout)
+	if Options.InstanceCacheTTL > 0 {
+		ttl = Options.InstanceCacheTTL
+	}
+	klog.V(3).Infof("TTL for nodeCache set to %s", ttl)
+	registerMetrics()
 
-	return &instances{client, &nodeCache{
+	i := &instances{client: client, nodeCache: &nodeCache{
 		nodes: make(map[int]linodeInstance, 0),
-		ttl:   time.Duration(timeout) * time.Second,
+		ttl:   ttl,
 	}}
+	if Options.InstanceLookupConcurrency > 0 {
+		i.lookupSlots = make(chan struct{}, Options.InstanceLookupConcurrency)
+	}
+	return i
+}
+
+// acquireLookupSlot blocks until fewer than Options.InstanceLookupConcurrency
+// lookups are in flight, or ctx is done. The returned func releases the slot.
+func (i *instances) acquireLookupSlot(ctx context.Context) (func(), error) {
+	if i.lookupSlots == nil {
+		return func() {}, nil
+	}
+	select {
+	case i.lookupSlots <- struct{}{}:
+		return func() { <-i.lookupSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 type instanceNoIPAddressesError struct {
@@ -141,6 +202,38 @@ func (e instanceNoIPAddressesError) Error() string {
 	return fmt.Sprintf("instance %d has no IP addresses", e.id)
 }
 
+// providerIDRequiredError is returned when looking up the linode of a node
+// without a provider ID, while provider IDs are required.
+type providerIDRequiredError struct {
+	node string
+}
+
+func (e providerIDRequiredError) Error() string {
+	return fmt.Sprintf("node %s has no provider ID; check that its kubelet runs with --cloud-provider=external", e.node)
+}
+
+// instanceMetadataIncompleteError is returned for the InstanceMetadata of an
+// uninitialized node whose linode lacks fields the node is labelled with.
+type instanceMetadataIncompleteError struct {
+	id      int
+	missing []string
+}
+
+func (e instanceMetadataIncompleteError) Error() string {
+	return fmt.Sprintf("instance %d has no %s yet", e.id, strings.Join(e.missing, " or "))
+}
+
+// isNodeUninitialized reports whether node still awaits initialization by the
+// CCM, which sets its provider ID.
+func isNodeUninitialized(node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == cloudproviderapi.TaintExternalCloudProvider {
+			return true
+		}
+	}
+	return false
+}
+
 func (i *instances) linodeByIP(kNode *v1.Node) (*linodego.Instance, error) {
 	i.nodeCache.RLock()
 	defer i.nodeCache.RUnlock()
@@ -159,6 +252,10 @@ func (i *instances) linodeByIP(kNode *v1.Node) (*linodego.Instance, error) {
 				return node.instance, nil
 			}
 		}
+		// IPv6-only nodes are matched by their SLAAC address
+		if node.instance.IPv6 != "" && slices.Contains(kNodeAddresses, strings.TrimSuffix(node.instance.IPv6, "/128")) {
+			return node.instance, nil
+		}
 	}
 
 	return nil, cloudprovider.InstanceNotFound
@@ -188,7 +285,7 @@ func (i *instances) linodeByID(id int) (*linodego.Instance, error) {
 
 // listAllInstances returns all instances in nodeCache
 func (i *instances) listAllInstances(ctx context.Context) ([]linodego.Instance, error) {
-	if err := i.nodeCache.refreshInstances(ctx, i.client); err != nil {
+	if err := i.nodeCache.refreshInstances(ctx, i.client, time.Time{}); err != nil {
 		return nil, err
 	}
 
@@ -199,8 +296,114 @@ func (i *instances) listAllInstances(ctx context.Context) ([]linodego.Instance,
 	return instances, nil
 }
 
-func (i *instances) lookupLinode(ctx context.Context, node *v1.Node) (*linodego.Instance, error) {
-	if err := i.nodeCache.refreshInstances(ctx, i.client); err != nil {
+// lookupLinodeByCachedID looks up the linode of node by the ID persisted in the
+// instance ID cache, or its provider ID, until the instance cache is first
+// filled. This spares listing all linodes right after a restart. A persisted ID
+// whose linode is not found is dropped, so that the node is looked up again.
+func (i *instances) lookupLinodeByCachedID(ctx context.Context, node *v1.Node) (*linodego.Instance, bool) {
+	// VPC addresses are only known from listing the VPC
+	if i.idCache == nil || vpcInfo.getID() != 0 {
+		return nil, false
+	}
+
+	i.nodeCache.RLock()
+	filled := !i.nodeCache.lastUpdate.IsZero()
+	i.nodeCache.RUnlock()
+	if filled {
+		return nil, false
+	}
+
+	id, err := parseProviderID(node.Spec.ProviderID)
+	fromProviderID := err == nil
+	if !fromProviderID {
+		if Options.RequireProviderID && !isNodeUninitialized(node) {
+			return nil, false
+		}
+		var ok bool
+		if id, ok = i.idCache.get(node.Name); !ok {
+			return nil, false
+		}
+	}
+
+	i.nodeCache.RLock()
+	cached, ok := i.nodeCache.nodes[id]
+	i.nodeCache.RUnlock()
+	if ok && time.Since(cached.fetched) < i.nodeCache.ttl {
+		return cached.instance, true
+	}
+
+	instance, err := i.client.GetInstance(ctx, id)
+	if err != nil {
+		if IgnoreLinodeAPIError(err, http.StatusNotFound) == nil {
+			i.nodeCache.invalidate(id)
+			if !fromProviderID {
+				klog.Infof("linode %d cached for node %s no longer exists, looking the node up again", id, node.Name)
+				i.idCache.forget(node.Name)
+			}
+		}
+		return nil, false
+	}
+
+	i.nodeCache.Lock()
+	i.nodeCache.nodes[id] = linodeInstance{
+		instance: instance,
+		ips:      i.nodeCache.getInstanceAddresses(*instance, nil),
+		fetched:  time.Now(),
+	}
+	i.nodeCache.Unlock()
+	return instance, true
+}
+
+// forgetNode drops the persisted linode ID of a deleted node.
+func (i *instances) forgetNode(nodeName string) {
+	if i.idCache != nil {
+		i.idCache.forget(nodeName)
+	}
+}
+
+// lookupLocalLinode looks up the linode of node by the ID the metadata service
+// reports, when node is the one the CCM runs on, rather than by its name, which
+// may not match the label of its linode. Metadata service failures are logged
+// and the node is then looked up by name or IP.
+func (i *instances) lookupLocalLinode(ctx context.Context, node *v1.Node) (*linodego.Instance, bool) {
+	if i.metadata == nil || node.Name != i.localNodeName {
+		return nil, false
+	}
+
+	id, err := i.metadata.instanceID(ctx)
+	if err != nil {
+		klog.Warningf("failed to look up the linode of node %s with the metadata service: %s", node.Name, err)
+		return nil, false
+	}
+	instance, err := i.linodeByID(id)
+	if err != nil {
+		klog.Warningf("linode %d reported by the metadata service for node %s was not found", id, node.Name)
+		return nil, false
+	}
+	return instance, true
+}
+
+func (i *instances) lookupLinode(ctx context.Context, node *v1.Node) (instance *linodego.Instance, err error) {
+	release, err := i.acquireLookupSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	defer func(start time.Time) { observeInstanceLookup(start, err) }(time.Now())
+	defer func() {
+		if err == nil && i.idCache != nil {
+			i.idCache.set(node.Name, instance.ID)
+		}
+	}()
+
+	if instance, ok := i.lookupLinodeByCachedID(ctx, node); ok {
+		return instance, nil
+	}
+
+	// a node created after the cache was filled may be backed by a linode
+	// recreated with the label of a deleted one, which must not be matched
+	if err := i.nodeCache.refreshInstances(ctx, i.client, node.CreationTimestamp.Time); err != nil {
 		return nil, err
 	}
 
@@ -220,7 +423,18 @@ func (i *instances) lookupLinode(ctx context.Context, node *v1.Node) (*linodego.
 
 		return i.linodeByID(id)
 	}
-	instance := i.linodeByName(nodeName)
+	// Nodes awaiting initialization get their provider ID from the CCM; any
+	// other node without one has a misconfigured kubelet, and matching it to a
+	// linode by name or IP risks acting on the wrong linode.
+	if Options.RequireProviderID && providerID == "" && !isNodeUninitialized(node) {
+		err := providerIDRequiredError{node.Name}
+		klog.Errorf("MISCONFIGURED NODE: %s; skipping lookup of its linode", err)
+		return nil, err
+	}
+	if instance, ok := i.lookupLocalLinode(ctx, node); ok {
+		return instance, nil
+	}
+	instance = i.linodeByName(nodeName)
 	if instance != nil {
 		return instance, nil
 	}
@@ -234,6 +448,10 @@ func (i *instances) InstanceExists(ctx context.Context, node *v1.Node) (bool, er
 		if err == cloudprovider.InstanceNotFound {
 			return false, nil
 		}
+		// never have a node deleted because its linode could not be looked up
+		if errors.As(err, &providerIDRequiredError{}) {
+			return true, nil
+		}
 		sentry.CaptureError(ctx, err)
 		return false, err
 	}
@@ -241,10 +459,23 @@ func (i *instances) InstanceExists(ctx context.Context, node *v1.Node) (bool, er
 	return true, nil
 }
 
+// transitionalInstanceStatuses are the statuses of linodes which are not
+// running for a while, e.g. during host maintenance, which are reported as shut
+// down with Options.TransitionalInstancesShutdown.
+var transitionalInstanceStatuses = []linodego.InstanceStatus{
+	linodego.InstanceMigrating,
+	linodego.InstanceRebooting,
+	linodego.InstanceProvisioning,
+}
+
 func (i *instances) InstanceShutdown(ctx context.Context, node *v1.Node) (bool, error) {
 	ctx = sentry.SetHubOnContext(ctx)
 	instance, err := i.lookupLinode(ctx, node)
 	if err != nil {
+		// never have a node tainted as shut down because its linode could not be looked up
+		if errors.As(err, &providerIDRequiredError{}) {
+			return false, nil
+		}
 		sentry.CaptureError(ctx, err)
 		return false, err
 	}
@@ -255,6 +486,9 @@ func (i *instances) InstanceShutdown(ctx context.Context, node *v1.Node) (bool,
 		instance.Status == linodego.InstanceShuttingDown {
 		return true, nil
 	}
+	if Options.TransitionalInstancesShutdown && slices.Contains(transitionalInstanceStatuses, instance.Status) {
+		return true, nil
+	}
 
 	return false, nil
 }
@@ -267,7 +501,7 @@ func (i *instances) InstanceMetadata(ctx context.Context, node *v1.Node) (*cloud
 		return nil, err
 	}
 
-	ips, err := i.getLinodeAddresses(ctx, node)
+	ips, err := i.getLinodeAddresses(ctx, linode)
 	if err != nil {
 		sentry.CaptureError(ctx, err)
 		return nil, err
@@ -279,31 +513,46 @@ func (i *instances) InstanceMetadata(ctx context.Context, node *v1.Node) (*cloud
 		return nil, err
 	}
 
+	// The cloud node controller removes the uninitialized taint once
+	// InstanceMetadata succeeds, so fail until the node can be fully labelled
+	// rather than letting workloads schedule onto a node missing its labels.
+	if isNodeUninitialized(node) {
+		var missing []string
+		if linode.Type == "" {
+			missing = append(missing, "type")
+		}
+		if linode.Region == "" {
+			missing = append(missing, "region")
+		}
+		if len(missing) > 0 {
+			err := instanceMetadataIncompleteError{linode.ID, missing}
+			sentry.CaptureError(ctx, err)
+			return nil, err
+		}
+	}
+
 	addresses := []v1.NodeAddress{{Type: v1.NodeHostName, Address: linode.Label}}
 
 	for _, ip := range ips {
 		addresses = append(addresses, v1.NodeAddress{Type: ip.ipType, Address: ip.ip})
 	}
 
-	// note that Zone is omitted as it's not a thing in Linode
+	// Zones are not a thing in Linode, so the zone is only set, to the
+	// region, when requested for topology features requiring a zone label.
 	meta := &cloudprovider.InstanceMetadata{
 		ProviderID:    fmt.Sprintf("%v%v", providerIDPrefix, linode.ID),
 		NodeAddresses: addresses,
 		InstanceType:  linode.Type,
 		Region:        linode.Region,
 	}
+	if Options.ZoneFromRegion {
+		meta.Zone = linode.Region
+	}
 
 	return meta, nil
 }
 
-func (i *instances) getLinodeAddresses(ctx context.Context, node *v1.Node) ([]nodeIP, error) {
-	ctx = sentry.SetHubOnContext(ctx)
-	instance, err := i.lookupLinode(ctx, node)
-	if err != nil {
-		sentry.CaptureError(ctx, err)
-		return nil, err
-	}
-
+func (i *instances) getLinodeAddresses(ctx context.Context, instance *linodego.Instance) ([]nodeIP, error) {
 	i.nodeCache.RLock()
 	defer i.nodeCache.RUnlock()
 	linodeInstance, ok := i.nodeCache.nodes[instance.ID]
@@ -4,8 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
@@ -13,7 +18,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
 	cloudprovider "k8s.io/cloud-provider"
+	cloudproviderapi "k8s.io/cloud-provider/api"
+	cloudnodecontroller "k8s.io/cloud-provider/controllers/node"
+	controllersmetrics "k8s.io/component-base/metrics/prometheus/controllers"
+	"k8s.io/component-base/metrics/testutil"
 )
 
 func nodeWithProviderID(providerID string) *v1.Node {
@@ -140,6 +151,23 @@ func TestMetadataRetrieval(t *testing.T) {
 				Address: privateIPv4.String(),
 			},
 		}, meta.NodeAddresses)
+		assert.Empty(t, meta.Zone)
+	})
+
+	t.Run("should return region as zone when enabled", func(t *testing.T) {
+		Options.ZoneFromRegion = true
+		defer func() { Options.ZoneFromRegion = false }()
+
+		instances := newInstances(client)
+		publicIPv4 := net.ParseIP("45.76.101.25")
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{
+			{ID: 123, Label: "mock-instance", Type: "g6-standard-1", Region: "us-east", IPv4: []*net.IP{&publicIPv4}},
+		}, nil)
+
+		meta, err := instances.InstanceMetadata(ctx, nodeWithName("mock-instance"))
+		assert.NoError(t, err)
+		assert.Equal(t, "us-east", meta.Region)
+		assert.Equal(t, "us-east", meta.Zone)
 	})
 
 	ipTests := []struct {
@@ -164,6 +192,27 @@ func TestMetadataRetrieval(t *testing.T) {
 			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "32.74.121.25"}, {Type: v1.NodeExternalIP, Address: "2600:3c06::f03c:94ff:fe1e:e072"}},
 			nil,
 		},
+		{
+			"only ipv6",
+			nil,
+			"2600:3c06::f03c:94ff:fe1e:e072/128",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "2600:3c06::f03c:94ff:fe1e:e072"}},
+			nil,
+		},
+		{
+			"one public ipv4, one unique local ipv6",
+			[]string{"32.74.121.25"},
+			"fd12:3456:789a::1",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "32.74.121.25"}, {Type: v1.NodeInternalIP, Address: "fd12:3456:789a::1"}},
+			nil,
+		},
+		{
+			"one public ipv4, one link-local ipv6",
+			[]string{"32.74.121.25"},
+			"fe80::f03c:94ff:fe1e:e072/128",
+			[]v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "32.74.121.25"}, {Type: v1.NodeInternalIP, Address: "fe80::f03c:94ff:fe1e:e072"}},
+			nil,
+		},
 		{
 			"one public, no private",
 			[]string{"32.74.121.25"},
@@ -289,6 +338,21 @@ func TestMetadataRetrieval(t *testing.T) {
 			})
 		}
 	}
+
+	t.Run("gets linode by IPv6 address", func(t *testing.T) {
+		instances := newInstances(client)
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{
+			{ID: 3456, Label: "other-instance", IPv6: "2600:3c06::1/128"},
+			{ID: 12345, Label: "expected-instance", IPv6: "2600:3c06::f03c:94ff:fe1e:e072/128"},
+		}, nil)
+		node := v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"}, Status: v1.NodeStatus{Addresses: []v1.NodeAddress{{
+			Type:    v1.NodeExternalIP,
+			Address: "2600:3c06::f03c:94ff:fe1e:e072",
+		}}}}
+		meta, err := instances.InstanceMetadata(ctx, &node)
+		assert.NoError(t, err)
+		assert.Equal(t, providerIDPrefix+"12345", meta.ProviderID)
+	})
 }
 
 func TestMalformedProviders(t *testing.T) {
@@ -377,4 +441,431 @@ func TestInstanceShutdown(t *testing.T) {
 		assert.NoError(t, err)
 		assert.False(t, shutdown)
 	})
+
+	for _, status := range transitionalInstanceStatuses {
+		t.Run(fmt.Sprintf("returns whether instance is shut down when %s, as configured", status), func(t *testing.T) {
+			instances := newInstances(client)
+			id := 12345
+			node := nodeWithProviderID(providerIDPrefix + strconv.Itoa(id))
+			client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{
+				{ID: id, Label: "transitional-linode", Status: status},
+			}, nil)
+			shutdown, err := instances.InstanceShutdown(ctx, node)
+
+			assert.NoError(t, err)
+			assert.False(t, shutdown)
+
+			Options.TransitionalInstancesShutdown = true
+			defer func() { Options.TransitionalInstancesShutdown = false }()
+			shutdown, err = instances.InstanceShutdown(ctx, node)
+
+			assert.NoError(t, err)
+			assert.True(t, shutdown)
+		})
+	}
+}
+
+func TestRequireProviderID(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+
+	Options.RequireProviderID = true
+	defer func() { Options.RequireProviderID = false }()
+
+	name := "some-name"
+	offline := []linodego.Instance{{ID: 123, Label: name, Status: linodego.InstanceOffline}}
+
+	t.Run("node without provider ID is reported as existing", func(t *testing.T) {
+		instances := newInstances(client)
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{}, nil)
+
+		exists, err := instances.InstanceExists(ctx, nodeWithName(name))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("node without provider ID is not reported as shut down", func(t *testing.T) {
+		instances := newInstances(client)
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return(offline, nil)
+
+		shutdown, err := instances.InstanceShutdown(ctx, nodeWithName(name))
+		assert.NoError(t, err)
+		assert.False(t, shutdown)
+	})
+
+	t.Run("node without provider ID is not matched by name", func(t *testing.T) {
+		instances := newInstances(client)
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return(offline, nil)
+
+		meta, err := instances.InstanceMetadata(ctx, nodeWithName(name))
+		assert.ErrorAs(t, err, &providerIDRequiredError{})
+		assert.Nil(t, meta)
+	})
+
+	t.Run("uninitialized node is matched by name", func(t *testing.T) {
+		instances := newInstances(client)
+		node := nodeWithName(name)
+		node.Spec.Taints = []v1.Taint{{Key: cloudproviderapi.TaintExternalCloudProvider, Effect: v1.TaintEffectNoSchedule}}
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return(offline, nil)
+
+		shutdown, err := instances.InstanceShutdown(ctx, node)
+		assert.NoError(t, err)
+		assert.True(t, shutdown)
+	})
+
+	t.Run("node with provider ID is looked up", func(t *testing.T) {
+		instances := newInstances(client)
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{}, nil)
+
+		exists, err := instances.InstanceExists(ctx, nodeWithProviderID(providerIDPrefix+"123"))
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestInstanceLookupMetrics(t *testing.T) {
+	ctx := context.TODO()
+	fake := newFake(t)
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	client := linodego.NewClient(http.DefaultClient)
+	client.SetBaseURL(ts.URL)
+
+	publicIP := net.ParseIP("45.76.101.25")
+	fake.instances[123] = &linodego.Instance{ID: 123, Label: "found-instance", IPv4: []*net.IP{&publicIP}}
+
+	counter := func(result string) float64 {
+		t.Helper()
+		value, err := testutil.GetCounterMetricValue(instanceLookupTotal.WithLabelValues(result))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	lookups := func() uint64 {
+		t.Helper()
+		count, err := testutil.GetHistogramMetricCount(instanceLookupDuration.ObserverMetric)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	t.Run("successful lookups are counted", func(t *testing.T) {
+		instances := newInstances(&client)
+		found, observed := counter(instanceLookupFound), lookups()
+
+		_, err := instances.InstanceMetadata(ctx, nodeWithProviderID(providerIDPrefix+"123"))
+		assert.NoError(t, err)
+		_, err = instances.InstanceExists(ctx, nodeWithName("found-instance"))
+		assert.NoError(t, err)
+
+		assert.Equal(t, found+2, counter(instanceLookupFound))
+		assert.Equal(t, observed+2, lookups())
+	})
+
+	t.Run("not found lookups are counted", func(t *testing.T) {
+		instances := newInstances(&client)
+		notFound := counter(instanceLookupNotFound)
+
+		exists, err := instances.InstanceExists(ctx, nodeWithProviderID(providerIDPrefix+"456"))
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		assert.Equal(t, notFound+1, counter(instanceLookupNotFound))
+	})
+
+	t.Run("API errors are counted by status code", func(t *testing.T) {
+		fake.instancesCode = http.StatusInternalServerError
+		defer func() { fake.instancesCode = 0 }()
+
+		instances := newInstances(&client)
+		failed := counter(instanceLookupError)
+		apiErrors, err := testutil.GetCounterMetricValue(instanceAPIErrorsTotal.WithLabelValues("500"))
+		assert.NoError(t, err)
+
+		_, err = instances.InstanceExists(ctx, nodeWithProviderID(providerIDPrefix+"123"))
+		assert.Error(t, err)
+
+		assert.Equal(t, failed+1, counter(instanceLookupError))
+		value, err := testutil.GetCounterMetricValue(instanceAPIErrorsTotal.WithLabelValues("500"))
+		assert.NoError(t, err)
+		assert.Equal(t, apiErrors+1, value)
+	})
+}
+
+func TestInstanceLookupConcurrency(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+
+	const limit, nodes = 3, 12
+
+	Options.InstanceLookupConcurrency = limit
+	defer func() { Options.InstanceLookupConcurrency = 0 }()
+
+	ids := map[string]string{}
+	for id := 1; id <= nodes; id++ {
+		ids[fmt.Sprintf("node-%d", id)] = strconv.Itoa(id)
+	}
+	idCache, err := newInstanceIDCache(fake.NewSimpleClientset(newInstanceIDCacheConfigMap(ids)), "kube-system/instance-ids")
+	assert.NoError(t, err)
+	assert.NoError(t, idCache.load(ctx))
+
+	instances := newInstances(client)
+	instances.idCache = idCache
+
+	var inFlight, maxInFlight atomic.Int32
+	client.EXPECT().GetInstance(gomock.Any(), gomock.Any()).Times(nodes).DoAndReturn(
+		func(_ context.Context, id int) (*linodego.Instance, error) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				observed := maxInFlight.Load()
+				if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return &linodego.Instance{ID: id, Label: fmt.Sprintf("node-%d", id)}, nil
+		})
+
+	var wg sync.WaitGroup
+	for id := 1; id <= nodes; id++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			exists, err := instances.InstanceExists(ctx, nodeWithName(name))
+			assert.NoError(t, err)
+			assert.True(t, exists)
+		}(fmt.Sprintf("node-%d", id))
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(limit))
+	assert.Positive(t, maxInFlight.Load())
+
+	t.Run("cancelled context while waiting for a slot", func(t *testing.T) {
+		for range limit {
+			instances.lookupSlots <- struct{}{}
+		}
+		defer func() {
+			for range limit {
+				<-instances.lookupSlots
+			}
+		}()
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := instances.InstanceExists(cancelled, nodeWithName("node-1"))
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// the node controller and routes share the instances, and so the limit
+		controller := newNodeController(fake.NewSimpleClientset(), instances, nil)
+		assert.ErrorIs(t, controller.handleNode(cancelled, nodeWithName("node-1")), context.Canceled)
+
+		Options.EnableRouteController = false
+		vpcRoutes, err := newRoutes(client, instances)
+		assert.NoError(t, err)
+		assert.Same(t, instances, vpcRoutes.(*routes).instances)
+	})
+}
+
+func TestInstanceCache(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+
+	t.Run("lookups within the TTL are served from the cache", func(t *testing.T) {
+		instances := newInstances(client)
+		node := nodeWithProviderID(providerIDPrefix + "123")
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{{ID: 123, Label: "mock"}}, nil)
+
+		for range 3 {
+			exists, err := instances.InstanceExists(ctx, node)
+			assert.NoError(t, err)
+			assert.True(t, exists)
+		}
+	})
+
+	t.Run("TTL is configurable", func(t *testing.T) {
+		Options.InstanceCacheTTL = time.Millisecond
+		defer func() { Options.InstanceCacheTTL = 0 }()
+
+		instances := newInstances(client)
+		node := nodeWithProviderID(providerIDPrefix + "123")
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(2).Return([]linodego.Instance{{ID: 123, Label: "mock"}}, nil)
+
+		for range 2 {
+			exists, err := instances.InstanceExists(ctx, node)
+			assert.NoError(t, err)
+			assert.True(t, exists)
+			time.Sleep(2 * time.Millisecond)
+		}
+	})
+
+	t.Run("node created after the cache was filled refreshes it", func(t *testing.T) {
+		instances := newInstances(client)
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{{ID: 123, Label: "node-a"}}, nil)
+		exists, err := instances.InstanceExists(ctx, nodeWithName("node-a"))
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		// the linode was recreated under the same label
+		node := nodeWithName("node-a")
+		node.CreationTimestamp = metav1.Now()
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{{ID: 456, Label: "node-a"}}, nil)
+		for range 2 {
+			instance, err := instances.lookupLinode(ctx, node)
+			assert.NoError(t, err)
+			assert.Equal(t, 456, instance.ID)
+		}
+	})
+
+	t.Run("deleted linode is dropped from the cache", func(t *testing.T) {
+		idCache, err := newInstanceIDCache(fake.NewSimpleClientset(), "kube-system/instance-ids")
+		assert.NoError(t, err)
+		instances := newInstances(client)
+		instances.idCache = idCache
+		instances.nodeCache.nodes[123] = linodeInstance{
+			instance: &linodego.Instance{ID: 123, Label: "mock"},
+			fetched:  time.Now().Add(-time.Hour),
+		}
+
+		client.EXPECT().GetInstance(gomock.Any(), 123).Times(1).Return(nil, &linodego.Error{Code: http.StatusNotFound})
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{}, nil)
+
+		exists, err := instances.InstanceExists(ctx, nodeWithProviderID(providerIDPrefix+"123"))
+		assert.NoError(t, err)
+		assert.False(t, exists)
+		assert.NotContains(t, instances.nodeCache.nodes, 123)
+	})
+}
+
+func TestUninitializedTaintRemoval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	publicIP := net.ParseIP("45.76.101.25")
+	privateIP := net.ParseIP("192.168.133.65")
+
+	// runCloudNodeController runs the upstream cloud node controller, which
+	// removes the uninitialized taint, against a tainted node and the linodes
+	// returned by listInstances.
+	runCloudNodeController := func(t *testing.T, listInstances func() ([]linodego.Instance, error)) (*fake.Clientset, chan struct{}) {
+		t.Helper()
+		client := mocks.NewMockClient(ctrl)
+		listed := make(chan struct{}, 1)
+		client.EXPECT().ListInstances(gomock.Any(), nil).AnyTimes().DoAndReturn(
+			func(context.Context, *linodego.ListOptions) ([]linodego.Instance, error) {
+				select {
+				case listed <- struct{}{}:
+				default:
+				}
+				return listInstances()
+			})
+
+		kubeClient := fake.NewSimpleClientset(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "mock-instance"},
+			Spec: v1.NodeSpec{Taints: []v1.Taint{{
+				Key:    cloudproviderapi.TaintExternalCloudProvider,
+				Value:  "true",
+				Effect: v1.TaintEffectNoSchedule,
+			}}},
+		})
+		informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+		cnc, err := cloudnodecontroller.NewCloudNodeController(
+			informerFactory.Core().V1().Nodes(),
+			kubeClient,
+			&linodeCloud{instances: newInstances(client)},
+			time.Hour,
+			1,
+		)
+		assert.NoError(t, err)
+
+		stopCh := make(chan struct{})
+		t.Cleanup(func() { close(stopCh) })
+		informerFactory.Start(stopCh)
+		go cnc.Run(stopCh, controllersmetrics.NewControllerManagerMetrics("test"))
+		return kubeClient, listed
+	}
+
+	nodeTainted := func(t *testing.T, kubeClient *fake.Clientset) bool {
+		t.Helper()
+		node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), "mock-instance", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return isNodeUninitialized(node)
+	}
+
+	t.Run("taint is kept while the lookup fails", func(t *testing.T) {
+		kubeClient, listed := runCloudNodeController(t, func() ([]linodego.Instance, error) {
+			return nil, &linodego.Error{Code: http.StatusInternalServerError}
+		})
+
+		<-listed
+		assert.Never(t, func() bool { return !nodeTainted(t, kubeClient) }, 500*time.Millisecond, 50*time.Millisecond)
+	})
+
+	t.Run("taint is kept while the linode lacks labelled fields", func(t *testing.T) {
+		kubeClient, listed := runCloudNodeController(t, func() ([]linodego.Instance, error) {
+			return []linodego.Instance{{ID: 123, Label: "mock-instance", IPv4: []*net.IP{&publicIP, &privateIP}}}, nil
+		})
+
+		<-listed
+		assert.Never(t, func() bool { return !nodeTainted(t, kubeClient) }, 500*time.Millisecond, 50*time.Millisecond)
+	})
+
+	t.Run("taint is removed once metadata is fetched", func(t *testing.T) {
+		kubeClient, _ := runCloudNodeController(t, func() ([]linodego.Instance, error) {
+			return []linodego.Instance{{
+				ID:     123,
+				Label:  "mock-instance",
+				Type:   "g6-standard-1",
+				Region: "us-east",
+				IPv4:   []*net.IP{&publicIP, &privateIP},
+			}}, nil
+		})
+
+		assert.Eventually(t, func() bool { return !nodeTainted(t, kubeClient) }, 5*time.Second, 50*time.Millisecond)
+
+		node, err := kubeClient.CoreV1().Nodes().Get(context.TODO(), "mock-instance", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, providerIDPrefix+"123", node.Spec.ProviderID)
+		assert.Equal(t, "g6-standard-1", node.Labels[v1.LabelInstanceTypeStable])
+		assert.Equal(t, "us-east", node.Labels[v1.LabelTopologyRegion])
+	})
+}
+
+func TestInstanceMetadataUninitializedNode(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+
+	publicIP := net.ParseIP("45.76.101.25")
+	node := nodeWithName("mock-instance")
+	node.Spec.Taints = []v1.Taint{{Key: cloudproviderapi.TaintExternalCloudProvider, Effect: v1.TaintEffectNoSchedule}}
+
+	instances := newInstances(client)
+	client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return([]linodego.Instance{
+		{ID: 123, Label: "mock-instance", Type: "g6-standard-1", IPv4: []*net.IP{&publicIP}},
+	}, nil)
+
+	_, err := instances.InstanceMetadata(ctx, node)
+	assert.ErrorAs(t, err, &instanceMetadataIncompleteError{})
+	assert.EqualError(t, err, "instance 123 has no region yet")
+
+	// initialized nodes keep their addresses updated regardless
+	meta, err := instances.InstanceMetadata(ctx, nodeWithName("mock-instance"))
+	assert.NoError(t, err)
+	assert.Equal(t, providerIDPrefix+"123", meta.ProviderID)
 }
@@ -1,15 +1,24 @@
 package linode
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	ciliumclient "github.com/cilium/cilium/pkg/k8s/client/clientset/versioned/typed/cilium.io/v2alpha1"
@@ -17,10 +26,19 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1apply "k8s.io/client-go/applyconfigurations/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/cloud-provider/api"
 	"k8s.io/klog/v2"
 
 	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
@@ -29,7 +47,120 @@ import (
 	"github.com/linode/linode-cloud-controller-manager/sentry"
 )
 
-var errNoNodesAvailable = errors.New("no nodes available for nodebalancer")
+var (
+	errNoNodesAvailable       = errors.New("no nodes available for nodebalancer")
+	errProvisioningTimeout    = errors.New("timed out provisioning nodebalancer")
+	errRegionMismatch         = errors.New("nodebalancer is not in the region of the cluster")
+	errInvalidTLSKeyPair      = errors.New("invalid TLS certificate and private key pair")
+	errPrivilegedPort         = errors.New("privileged ports are not allowed")
+	errIdleTimeoutUnsupported = errors.New("the NodeBalancer idle timeout is not supported by the Linode API")
+	errServiceRemoved         = errors.New("service was removed during the reconcile")
+	errCrossNamespaceSecret   = errors.New("TLS secrets of other namespaces are not allowed")
+	errTLSSecretNotFound      = errors.New("TLS secret not found")
+	errTLSSecretUnreadable    = errors.New("TLS secret cannot be read")
+	errMultipleTLSSecrets     = errors.New("NodeBalancer configs serve a single TLS certificate per port")
+	errTLSHostnameMismatch    = errors.New("TLS certificate does not cover the SNI hostname")
+	errNodeBalancerOwned      = errors.New("NodeBalancer was created for another service")
+
+	errDuplicateBackendAddress = errors.New("duplicate backend address")
+)
+
+// linodePrivateSubnet is the range Linode private IPv4 addresses are allocated from
+var linodePrivateSubnet = &net.IPNet{IP: net.IPv4(192, 168, 128, 0), Mask: net.CIDRMask(17, 32)}
+
+// bounds of the NodeBalancer health check interval, in seconds, and the number
+// of backends checked per interval when scaling the interval with the backends
+const (
+	minCheckInterval             = 2
+	maxCheckInterval             = 3600
+	checkIntervalScalingBackends = 10
+)
+
+// bounds of the NodeBalancer health check timeout, in seconds, and attempts
+const (
+	minCheckTimeout  = 1
+	maxCheckTimeout  = 30
+	minCheckAttempts = 1
+	maxCheckAttempts = 30
+)
+
+// bounds of the NodeBalancer idle connection timeout, in seconds
+const (
+	minIdleTimeout = 1
+	maxIdleTimeout = 3600
+)
+
+// bounds of the NodeBalancer client connection throttle, in connections per
+// second from the same client IP
+const (
+	minConnThrottle = 0
+	maxConnThrottle = 20
+)
+
+// bounds of the length of the NodeBalancer tags set by annotation
+const (
+	minTagLength = 3
+	maxTagLength = 50
+)
+
+// backend IP types which may be ordered by the backend IP preference
+const (
+	backendIPTypeVPC     = "vpc"
+	backendIPTypePrivate = "private"
+	backendIPTypePublic  = "public"
+)
+
+const (
+	// eventSourceComponent is the component reported on Events emitted for Services
+	eventSourceComponent = "linode-cloud-controller-manager"
+
+	// defaultNodeBalancerIPRequeueInterval is how long to wait before checking
+	// again for the IP of a NodeBalancer which is still being provisioned
+	defaultNodeBalancerIPRequeueInterval = 5 * time.Second
+
+	// serviceStatusFieldManager owns the Service status fields applied by the
+	// CCM, apart from the status updates of the service controller
+	serviceStatusFieldManager = "linode-ccm-ingress"
+
+	eventReasonNodeBalancerRecreated = "NodeBalancerRecreated"
+	eventReasonBackendsUnhealthy     = "NodeBalancerBackendsUnhealthy"
+	eventReasonCertificateExpiring   = "CertificateExpiring"
+	eventReasonTLSSecretMissing      = "TLSSecretMissing"
+	eventReasonProvisioningTimeout   = "NodeBalancerProvisioningTimeout"
+	eventReasonInvalidPortProtocol   = "InvalidPortProtocol"
+	eventReasonBackendRetained       = "NodeBalancerBackendRetained"
+	eventReasonNoBackendNodes        = "NoBackendNodes"
+	eventReasonRegionMismatch        = "NodeBalancerRegionMismatch"
+	eventReasonRetriesExhausted      = "ReconcileRetriesExhausted"
+	eventReasonDuplicatePort         = "DuplicateServicePort"
+	eventReasonCrossRegionBackends   = "NodeBalancerCrossRegionBackends"
+	eventReasonHealthCheckDrift      = "NodeBalancerHealthCheckDrift"
+	eventReasonInvalidTLSKeyPair     = "InvalidTLSKeyPair"
+	eventReasonPrivilegedPort        = "PrivilegedPortRejected"
+	eventReasonUnsupportedSetting    = "UnsupportedNodeBalancerSetting"
+	eventReasonCrossNamespaceSecret  = "CrossNamespaceTLSSecret"
+	eventReasonInvalidAnnotation     = "InvalidAnnotation"
+	eventReasonTransferQuota         = "NodeBalancerTransferQuota"
+	eventReasonBelowMinBackends      = "NodeBalancerBelowMinBackends"
+	eventReasonTLSHostnameMismatch   = "TLSHostnameMismatch"
+	eventReasonTLSSecretUnreadable   = "TLSSecretUnreadable"
+	eventReasonNodeBalancerNotFound  = "NodeBalancerNotFound"
+	eventReasonNodeBalancerReleased  = "NodeBalancerReleased"
+	eventReasonNodeBalancerOwned     = "NodeBalancerOwnedByOtherService"
+
+	// audit tags record the last applied Service resourceVersion and when it was applied
+	auditTagResourceVersionPrefix = "ccm-rv:"
+	auditTagAppliedAtPrefix       = "ccm-applied:"
+	auditTagTimeFormat            = "20060102T150405Z"
+
+	// ownerTagPrefix identifies the Service a NodeBalancer was created for, so
+	// that one created after its provisioning request timed out can be adopted
+	ownerTagPrefix = "ccm-svc:"
+
+	// configPortTagPrefix marks the ports of the NodeBalancer configs managed
+	// for the Service, so that configs added out of band are left alone
+	configPortTagPrefix = "ccm-port:"
+)
 
 type lbNotFoundError struct {
 	serviceNn      string
@@ -47,18 +178,37 @@ type loadbalancers struct {
 	client           client.Client
 	zone             string
 	kubeClient       kubernetes.Interface
+	serviceLister    corelisters.ServiceLister
+	nodeLister       corelisters.NodeLister
 	ciliumClient     ciliumclient.CiliumV2alpha1Interface
 	loadBalancerType string
+	eventRecorder    record.EventRecorder
+	backends         backendTracker
+	reconciled       reconcileTracker
+	pendingIPs       pendingNodeBalancers
+	tlsSecretRetries tlsSecretRetries
+	retries          retryBudget
+	rateLimiters     serviceRateLimiters
+	locks            serviceLocks
+
+	// initMu guards the lazy initialization of kubeClient and eventRecorder,
+	// which Initialize sets unless the CCM runs without controllers
+	initMu sync.Mutex
 }
 
 type portConfigAnnotation struct {
 	TLSSecretName string `json:"tls-secret-name"`
-	Protocol      string `json:"protocol"`
-	ProxyProtocol string `json:"proxy-protocol"`
+	// TLSSecrets are the TLS secrets of the port keyed by SNI hostname
+	TLSSecrets    map[string]string `json:"tls-secrets"`
+	Protocol      string            `json:"protocol"`
+	ProxyProtocol string            `json:"proxy-protocol"`
 }
 
 type portConfig struct {
 	TLSSecretName string
+	// TLSHostnames are the SNI hostnames the certificate of TLSSecretName
+	// must cover, sorted
+	TLSHostnames  []string
 	Protocol      linodego.ConfigProtocol
 	ProxyProtocol linodego.ConfigProxyProtocol
 	Port          int
@@ -66,6 +216,7 @@ type portConfig struct {
 
 // newLoadbalancers returns a cloudprovider.LoadBalancer whose concrete type is a *loadbalancer.
 func newLoadbalancers(client client.Client, zone string) cloudprovider.LoadBalancer {
+	registerMetrics()
 	return &loadbalancers{client: client, zone: zone, loadBalancerType: Options.LoadBalancerType}
 }
 
@@ -78,7 +229,13 @@ func (l *loadbalancers) getNodeBalancerForService(ctx context.Context, service *
 		sentry.SetTag(ctx, "load_balancer_id", rawID)
 		return l.getNodeBalancerByID(ctx, service, id)
 	}
-	return l.getNodeBalancerByStatus(ctx, service)
+	nb, err := l.getNodeBalancerByStatus(ctx, service)
+	if _, notFound := err.(lbNotFoundError); notFound {
+		if id, ok := l.pendingIPs.get(service); ok {
+			return l.getNodeBalancerByID(ctx, service, id)
+		}
+	}
+	return nb, err
 }
 
 func (l *loadbalancers) getLatestServiceLoadBalancerStatus(ctx context.Context, service *v1.Service) (v1.LoadBalancerStatus, error) {
@@ -95,17 +252,96 @@ func (l *loadbalancers) getLatestServiceLoadBalancerStatus(ctx context.Context,
 }
 
 // getNodeBalancerByStatus attempts to get the NodeBalancer from the IPv4 specified in the
-// most recent LoadBalancer status.
+// most recent LoadBalancer status. Entries which match no NodeBalancer, e.g.
+// stale ones left by a previous NodeBalancer, are skipped.
 func (l *loadbalancers) getNodeBalancerByStatus(ctx context.Context, service *v1.Service) (nb *linodego.NodeBalancer, err error) {
+	err = lbNotFoundError{serviceNn: getServiceNn(service)}
 	for _, ingress := range service.Status.LoadBalancer.Ingress {
-		if ingress.IP != "" {
-			return l.getNodeBalancerByIPv4(ctx, service, ingress.IP)
+		switch {
+		case strings.Contains(ingress.IP, ":"):
+			// the IPv6 ingress of a dual-stack Service follows its IPv4 one
+			continue
+		case ingress.IP != "":
+			nb, err = l.getNodeBalancerByIPv4(ctx, service, ingress.IP)
+		case ingress.Hostname != "":
+			nb, err = l.getNodeBalancerByHostname(ctx, service, ingress.Hostname)
+		default:
+			continue
 		}
-		if ingress.Hostname != "" {
-			return l.getNodeBalancerByHostname(ctx, service, ingress.Hostname)
+		if _, notFound := err.(lbNotFoundError); !notFound {
+			return nb, err
 		}
 	}
-	return nil, lbNotFoundError{serviceNn: getServiceNn(service)}
+	return nil, err
+}
+
+// replaceStaleIngress publishes the addresses of nb as the ingress of service
+// right away when its status lists other addresses, e.g. of a previous
+// NodeBalancer, rather than leaving them in place until the reconcile succeeds.
+// The ingress is replaced as a whole, so stale entries are removed rather than
+// kept next to the current ones.
+func (l *loadbalancers) replaceStaleIngress(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer) {
+	current := service.Status.LoadBalancer.Ingress
+	if len(current) == 0 || !isNodeBalancerReady(nb) {
+		return
+	}
+	status := makeLoadBalancerStatus(service, nb)
+	if ingressEqual(current, status.Ingress) {
+		return
+	}
+
+	klog.Infof("replacing stale ingress %v of service (%s) with the addresses of NodeBalancer (%d)", current, getServiceNn(service), nb.ID)
+	if err := l.updateServiceLoadBalancerStatus(ctx, service, status); err != nil {
+		klog.Warningf("failed to update LoadBalancer status for service (%s): %s", getServiceNn(service), err)
+	}
+}
+
+// ingressEqual reports whether a and b list the same addresses in the same order.
+func ingressEqual(a, b []v1.LoadBalancerIngress) bool {
+	return slices.EqualFunc(a, b, func(x, y v1.LoadBalancerIngress) bool {
+		return x.IP == y.IP && x.Hostname == y.Hostname
+	})
+}
+
+// serviceRemoved reports whether service was deleted, or is no longer of type
+// LoadBalancer, since the reconcile started. Services missing from the
+// informer cache are looked up on the API server, as the cache may not have
+// caught up with a new Service yet. Without a cache, it always reports false.
+func (l *loadbalancers) serviceRemoved(ctx context.Context, service *v1.Service) bool {
+	if l.serviceLister == nil {
+		return false
+	}
+
+	current, err := l.serviceLister.Services(service.Namespace).Get(service.Name)
+	if k8serrors.IsNotFound(err) {
+		if err = l.retrieveKubeClient(); err == nil {
+			current, err = l.kubeClient.CoreV1().Services(service.Namespace).Get(ctx, service.Name, metav1.GetOptions{})
+		}
+	}
+	switch {
+	case k8serrors.IsNotFound(err):
+		return true
+	case err != nil:
+		klog.Warningf("unable to check whether service (%s) still exists: %s", getServiceNn(service), err)
+		return false
+	}
+	return current.UID != service.UID || current.DeletionTimestamp != nil || current.Spec.Type != v1.ServiceTypeLoadBalancer
+}
+
+// deleteNodeBalancerOfRemovedService deletes nb, just created for service which
+// has since been removed, and returns errServiceRemoved unless the deletion failed.
+func (l *loadbalancers) deleteNodeBalancerOfRemovedService(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer) error {
+	serviceNn := getServiceNn(service)
+	if err := l.client.DeleteNodeBalancer(ctx, nb.ID); err != nil {
+		klog.Errorf("failed to delete NodeBalancer (%d) created for removed service (%s): %s", nb.ID, serviceNn, err)
+		sentry.CaptureError(ctx, err)
+		return err
+	}
+	l.backends.forget(nb.ID)
+	l.pendingIPs.forget(service)
+
+	klog.Infof("deleted NodeBalancer (%d) as service (%s) was removed while it was created", nb.ID, serviceNn)
+	return fmt.Errorf("%w: %s", errServiceRemoved, serviceNn)
 }
 
 // cleanupOldNodeBalancer removes the service's disowned NodeBalancer if there is one.
@@ -140,11 +376,19 @@ func (l *loadbalancers) cleanupOldNodeBalancer(ctx context.Context, service *v1.
 		return nil
 	}
 
-	if err := l.client.DeleteNodeBalancer(ctx, previousNB.ID); err != nil {
+	// the previous NodeBalancer no longer matches the annotation, so it is only
+	// known to be created for the Service by its owner tag
+	adopted := !slices.Contains(previousNB.Tags, getOwnerTag(service))
+	if err := l.removeNodeBalancer(ctx, service, previousNB, adopted); err != nil {
 		return err
 	}
+	l.backends.forget(previousNB.ID)
 
-	klog.Infof("successfully deleted old NodeBalancer (%d) for service (%s)", previousNB.ID, getServiceNn(service))
+	if adopted {
+		klog.Infof("successfully released old NodeBalancer (%d) for service (%s)", previousNB.ID, getServiceNn(service))
+	} else {
+		klog.Infof("successfully deleted old NodeBalancer (%d) for service (%s)", previousNB.ID, getServiceNn(service))
+	}
 	return nil
 }
 
@@ -164,6 +408,10 @@ func (l *loadbalancers) GetLoadBalancer(ctx context.Context, clusterName string,
 	sentry.SetTag(ctx, "cluster_name", clusterName)
 	sentry.SetTag(ctx, "service", service.Name)
 
+	if !isNamespaceManaged(service.Namespace) {
+		return nil, false, nil
+	}
+
 	// Handle LoadBalancers backed by Cilium
 	if l.loadBalancerType == ciliumLBType {
 		return &v1.LoadBalancerStatus{
@@ -197,6 +445,15 @@ func (l *loadbalancers) EnsureLoadBalancer(ctx context.Context, clusterName stri
 	sentry.SetTag(ctx, "service", service.Name)
 	serviceNn := getServiceNn(service)
 
+	// the service controller and the controllers of this package reconcile
+	// Services concurrently
+	defer l.locks.lock(service)()
+
+	if !isNamespaceManaged(service.Namespace) {
+		klog.V(3).Infof("ignoring LoadBalancer Service %s outside of the managed namespaces", serviceNn)
+		return nil, cloudprovider.ImplementedElsewhere
+	}
+
 	// Handle LoadBalancers backed by Cilium
 	if l.loadBalancerType == ciliumLBType {
 		klog.Infof("handling LoadBalancer Service %s as %s", serviceNn, ciliumLBClass)
@@ -240,25 +497,95 @@ func (l *loadbalancers) EnsureLoadBalancer(ctx context.Context, clusterName stri
 	}
 
 	// Handle LoadBalancers backed by NodeBalancers
+	if err = l.retries.exhausted(service, nodes, Options.MaxReconcileRetries, Options.ReconcileRetryCooldown, time.Now()); err != nil {
+		klog.V(3).Info(err)
+		return nil, err
+	}
+	defer func() { l.observeReconcile(service, nodes, err) }()
+	defer func() { err = l.requeueMissingTLSSecret(service, err) }()
+	if ctx, err = l.rateLimiters.withServiceRateLimit(ctx, service); err != nil {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidAnnotation, "%s", err)
+		return nil, err
+	}
+
+	if err = l.validateServicePorts(service); err != nil {
+		return nil, err
+	}
+	if err = l.validateIdleTimeout(service); err != nil {
+		return nil, err
+	}
+	if err = l.validateServiceAnnotations(service); err != nil {
+		return nil, err
+	}
+
 	var nb *linodego.NodeBalancer
 
 	nb, err = l.getNodeBalancerForService(ctx, service)
 	switch err.(type) {
 	case lbNotFoundError:
-		if service.GetAnnotations()[annotations.AnnLinodeNodeBalancerID] != "" {
-			// a load balancer annotation has been created so a NodeBalancer is coming, error out and retry later
-			klog.Infof("NodeBalancer created but not available yet, waiting...")
+		if rawID := service.GetAnnotations()[annotations.AnnLinodeNodeBalancerID]; rawID != "" {
+			// the NodeBalancer selected by the annotation is never created in
+			// its place, error out and retry later in case it is being created
+			klog.Errorf("NodeBalancer (%s) selected by the %s annotation of service (%s) does not exist", rawID, annotations.AnnLinodeNodeBalancerID, serviceNn)
+			l.recordEvent(service, v1.EventTypeWarning, eventReasonNodeBalancerNotFound,
+				"NodeBalancer (%s) selected by the %s annotation does not exist", rawID, annotations.AnnLinodeNodeBalancerID)
+			sentry.CaptureError(ctx, err)
+			return nil, err
+		}
+
+		nb, err = l.getAdoptableNodeBalancer(ctx, clusterName, service)
+		switch err.(type) {
+		case nil:
+			klog.Infof("adopting existing NodeBalancer (%d) for service (%s)", nb.ID, serviceNn)
+			l.replaceStaleIngress(ctx, service, nb)
+			if err = l.updateNodeBalancer(ctx, clusterName, service, nodes, nb); err != nil {
+				sentry.CaptureError(ctx, err)
+				return nil, err
+			}
+		case lbNotFoundError:
+			nb = nil
+		default:
 			sentry.CaptureError(ctx, err)
 			return nil, err
 		}
+		if nb != nil {
+			break
+		}
 
+		if l.serviceRemoved(ctx, service) {
+			klog.Infof("service (%s) was removed, not creating a NodeBalancer for it", serviceNn)
+			return nil, fmt.Errorf("%w: %s", errServiceRemoved, serviceNn)
+		}
 		if nb, err = l.buildLoadBalancerRequest(ctx, clusterName, service, nodes); err != nil {
 			sentry.CaptureError(ctx, err)
 			return nil, err
 		}
 		klog.Infof("created new NodeBalancer (%d) for service (%s)", nb.ID, serviceNn)
 
+		// the Service may have been deleted while the NodeBalancer was being
+		// created, leaving nothing to delete it afterwards
+		if l.serviceRemoved(ctx, service) {
+			return nil, l.deleteNodeBalancerOfRemovedService(ctx, service, nb)
+		}
+
+		// the Service already had an ingress, so the NodeBalancer it pointed to
+		// is gone and the one we just created comes with a new address
+		if previous := ingressAddress(service.Status.LoadBalancer.Ingress); previous != "" {
+			if isNodeBalancerReady(nb) {
+				l.recordEvent(service, v1.EventTypeWarning, eventReasonNodeBalancerRecreated,
+					"NodeBalancer for service was not found and has been recreated as NodeBalancer (%d); its external address changes from %s to %s",
+					nb.ID, previous, ingressAddress(makeLoadBalancerStatus(service, nb).Ingress))
+
+				l.replaceStaleIngress(ctx, service, nb)
+			} else {
+				l.recordEvent(service, v1.EventTypeWarning, eventReasonNodeBalancerRecreated,
+					"NodeBalancer for service was not found and has been recreated as NodeBalancer (%d); its external address changes from %s once one is assigned",
+					nb.ID, previous)
+			}
+		}
+
 	case nil:
+		l.replaceStaleIngress(ctx, service, nb)
 		if err = l.updateNodeBalancer(ctx, clusterName, service, nodes, nb); err != nil {
 			sentry.CaptureError(ctx, err)
 			return nil, err
@@ -269,10 +596,31 @@ func (l *loadbalancers) EnsureLoadBalancer(ctx context.Context, clusterName stri
 		return nil, err
 	}
 
+	if !isNodeBalancerReady(nb) {
+		l.pendingIPs.set(service, nb.ID)
+		retryAfter := nodeBalancerIPRetryAfter(nb, time.Now())
+		klog.Infof("NodeBalancer (%d) for service (%s) has no IP or hostname assigned yet, checking again in %s", nb.ID, serviceNn, retryAfter)
+		return nil, api.NewRetryError(fmt.Sprintf("NodeBalancer (%d) has no IP or hostname assigned yet", nb.ID), retryAfter)
+	}
+
+	l.pendingIPs.forget(service)
+
 	klog.Infof("NodeBalancer (%d) has been ensured for service (%s)", nb.ID, serviceNn)
 	lbStatus = makeLoadBalancerStatus(service, nb)
+	l.reportTransferUsage(service, nb)
+	l.warnBelowMinBackends(service, nb, nodes)
+	// failing to annotate the Service does not fail the reconcile
+	if Options.AnnotateNodeBalancerID {
+		if err := l.annotateNodeBalancerID(ctx, service, nb); err != nil {
+			klog.Warningf("failed to annotate service (%s) with NodeBalancer (%d): %s", serviceNn, nb.ID, err)
+		}
+	}
 
-	if !l.shouldPreserveNodeBalancer(service) {
+	preserve, err := l.shouldPreserveNodeBalancer(service)
+	if err != nil {
+		return nil, err
+	}
+	if !preserve {
 		if err := l.cleanupOldNodeBalancer(ctx, service); err != nil {
 			sentry.CaptureError(ctx, err)
 			return nil, err
@@ -290,11 +638,68 @@ func (l *loadbalancers) updateNodeBalancer(
 	nodes []*v1.Node,
 	nb *linodego.NodeBalancer,
 ) (err error) {
+	nodes = eligibleBackendNodes(service, nodes)
 	if len(nodes) == 0 {
-		return fmt.Errorf("%w: service %s", errNoNodesAvailable, getServiceNn(service))
+		if Options.NoBackendNodesPolicy == noBackendNodesDefer {
+			l.recordEvent(service, v1.EventTypeWarning, eventReasonNoBackendNodes,
+				"no nodes are available as backends, keeping NodeBalancer (%d) as is until there is at least one", nb.ID)
+			return fmt.Errorf("%w: service %s", errNoNodesAvailable, getServiceNn(service))
+		}
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonNoBackendNodes,
+			"no nodes are available as backends, updating NodeBalancer (%d) without backends", nb.ID)
+	}
+
+	// an adopted or preserved NodeBalancer may be in another region, where
+	// the nodes are not reachable as backends
+	placement := l.selectNodeBalancerRegion(service, nodes)
+	switch {
+	case len(nodes) == 0 || nb.Region == "":
+		reportRegionMismatch(service, false)
+	case placement.region != "" && nb.Region != placement.region && placement.counts[nb.Region] == 0:
+		reportRegionMismatch(service, true)
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonRegionMismatch,
+			"NodeBalancer (%d) is in region %s but its backends are in region %s, they are unreachable",
+			nb.ID, nb.Region, placement.region)
+		if Options.RejectRegionMismatch {
+			return fmt.Errorf("%w: NodeBalancer (%d) is in region %s, backends are in region %s", errRegionMismatch, nb.ID, nb.Region, placement.region)
+		}
+	default:
+		l.warnRegionMismatch(service, placement.in(nb.Region))
+	}
+
+	backendIPs, err := l.resolveBackendIPs(ctx, service, nodes)
+	if err != nil {
+		sentry.CaptureError(ctx, err)
+		return err
+	}
+	drained := drainedBackendNodes(nodes)
+	fingerprint, err := reconcileFingerprint(service, backendIPs, drained, nb.ID)
+	if err != nil {
+		return err
+	}
+	if l.reconciled.isCurrent(service, fingerprint) {
+		nbCfgs, err := l.client.ListNodeBalancerConfigs(ctx, nb.ID, nil)
+		if err != nil {
+			return err
+		}
+		drifted, err := healthChecksDrifted(service, nbCfgs, len(nodes))
+		if err != nil {
+			return err
+		}
+		if !drifted {
+			klog.V(3).Infof("skipping update of NodeBalancer (%d) for service (%s): nothing changed since the last update", nb.ID, getServiceNn(service))
+			reportConfigCount(service, nb.ID, len(nbCfgs))
+			l.reportCertExpiries(ctx, service)
+			return l.deleteOrphanedBackends(ctx, service, backendIPs, nb, nbCfgs)
+		}
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonHealthCheckDrift,
+			"health check settings of NodeBalancer (%d) were changed out of band, restoring them", nb.ID)
 	}
 
-	connThrottle := getConnectionThrottle(service)
+	connThrottle, err := getConnectionThrottle(service)
+	if err != nil {
+		return err
+	}
 	if connThrottle != nb.ClientConnThrottle {
 		update := nb.GetUpdateOptions()
 		update.ClientConnThrottle = &connThrottle
@@ -305,10 +710,31 @@ func (l *loadbalancers) updateNodeBalancer(
 		}
 	}
 
+	// the configs owned before this update, which may be deleted once their
+	// port is removed from the Service
+	ownedPorts := getOwnedConfigPorts(nb.Tags)
+
 	tags := l.GetLoadBalancerTags(ctx, clusterName, service)
-	if !reflect.DeepEqual(nb.Tags, tags) {
+	auditTags, err := getAuditTags(service, nb.Tags, time.Now())
+	if err != nil {
+		return err
+	}
+	nbTags := append(append([]string{}, tags...), auditTags...)
+	if ownerTag := getOwnerTag(service); slices.Contains(nb.Tags, ownerTag) {
+		nbTags = append(nbTags, ownerTag)
+	}
+	servicePorts := make([]int, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		servicePorts = append(servicePorts, int(port.Port))
+	}
+	nbTags = append(nbTags, getConfigPortTags(servicePorts)...)
+	if isAdoptedNodeBalancer(service, nb) && Options.AdoptedTagsPolicy != adoptedTagsReplace {
+		// keep the tags the adopted NodeBalancer was given out of band
+		nbTags = append(nbTags, getForeignTags(nb.Tags, nbTags)...)
+	}
+	if !reflect.DeepEqual(nb.Tags, nbTags) {
 		update := nb.GetUpdateOptions()
-		update.Tags = &tags
+		update.Tags = &nbTags
 		nb, err = l.client.UpdateNodeBalancer(ctx, nb.ID, update)
 		if err != nil {
 			sentry.CaptureError(ctx, err)
@@ -330,39 +756,59 @@ func (l *loadbalancers) updateNodeBalancer(
 	}
 
 	// Delete any configs for ports that have been removed from the Service
-	if err = l.deleteUnusedConfigs(ctx, nbCfgs, service.Spec.Ports); err != nil {
+	deletedCfgs, err := l.deleteUnusedConfigs(ctx, nbCfgs, service.Spec.Ports, ownedPorts)
+	if err != nil {
+		sentry.CaptureError(ctx, err)
+		return err
+	}
+
+	// Drain the backends which are about to be removed before the configs are rebuilt
+	if err = l.drainRemovedBackends(ctx, service, backendIPs, nb, nbCfgs); err != nil {
+		if !errors.As(err, new(*api.RetryError)) {
+			sentry.CaptureError(ctx, err)
+		}
+		return err
+	}
+
+	// Delete the removed backends in batches rather than all at once by
+	// rebuilding the configs
+	if err = l.removeStaleBackends(ctx, service, backendIPs, nb, nbCfgs); err != nil {
 		sentry.CaptureError(ctx, err)
 		return err
 	}
 
+	// Match the existing configs to the Service's ports by port, so that the
+	// order they are listed in does not matter
+	nbCfgsByPort := make(map[int]*linodego.NodeBalancerConfig, len(nbCfgs))
+	for i := range nbCfgs {
+		nbCfgsByPort[nbCfgs[i].Port] = &nbCfgs[i]
+	}
+
 	// Add or overwrite configs for each of the Service's ports
+	backendsRetained := false
+	createdCfgs := 0
+	appliedCfgs := make([]linodego.NodeBalancerConfig, 0, len(service.Spec.Ports))
 	for _, port := range service.Spec.Ports {
-		if port.Protocol == v1.ProtocolUDP {
-			err := fmt.Errorf("error updating NodeBalancer Config: ports with the UDP protocol are not supported")
+		if err := l.validatePortProtocol(service, port); err != nil {
+			err = fmt.Errorf("error updating NodeBalancer Config: %w", err)
 			sentry.CaptureError(ctx, err)
 			return err
 		}
 
 		// Construct a new config for this port
-		newNBCfg, err := l.buildNodeBalancerConfig(ctx, service, int(port.Port))
+		newNBCfg, err := l.buildNodeBalancerConfig(ctx, service, int(port.Port), len(nodes))
 		if err != nil {
 			sentry.CaptureError(ctx, err)
 			return err
 		}
 
 		// Look for an existing config for this port
-		var currentNBCfg *linodego.NodeBalancerConfig
-		for i := range nbCfgs {
-			nbc := nbCfgs[i]
-			if nbc.Port == int(port.Port) {
-				currentNBCfg = &nbc
-				break
-			}
-		}
+		currentNBCfg := nbCfgsByPort[int(port.Port)]
 		oldNBNodeIDs := make(map[string]int)
+		var currentNBNodes []linodego.NodeBalancerNode
 		if currentNBCfg != nil {
 			// Obtain list of current NB nodes and convert it to map of node IDs
-			currentNBNodes, err := l.client.ListNodeBalancerNodes(ctx, nb.ID, currentNBCfg.ID, nil)
+			currentNBNodes, err = l.client.ListNodeBalancerNodes(ctx, nb.ID, currentNBCfg.ID, nil)
 			if err != nil {
 				// This error can be ignored, because if we fail to get nodes we can anyway rebuild the config from scratch,
 				// it would just cause the NB to reload config even if the node list did not change, so we prefer to send IDs when it is posible.
@@ -372,130 +818,526 @@ func (l *loadbalancers) updateNodeBalancer(
 				oldNBNodeIDs[node.Address] = node.ID
 			}
 			klog.Infof("Nodebalancer %d had nodes %v", nb.ID, oldNBNodeIDs)
+
+			if down := l.backends.unhealthy(nb.ID, currentNBNodes, Options.BackendHealthGracePeriod, time.Now()); len(down) > 0 {
+				l.recordEvent(service, v1.EventTypeWarning, eventReasonBackendsUnhealthy,
+					"%d of %d backends for port %d of NodeBalancer (%d) are failing health checks",
+					len(down), len(currentNBNodes), port.Port, nb.ID)
+			}
 		} else {
 			klog.Infof("No preexisting nodebalancer for port %v found.", port.Port)
 		}
 		// Add all of the Nodes to the config
-		newNBNodes := make([]linodego.NodeBalancerConfigRebuildNodeOptions, 0, len(nodes))
-		for _, node := range nodes {
-			newNodeOpts := l.buildNodeBalancerNodeConfigRebuildOptions(node, port.NodePort)
-			oldNodeID, ok := oldNBNodeIDs[newNodeOpts.Address]
+		backendPort, err := getBackendPort(service, port)
+		if err != nil {
+			sentry.CaptureError(ctx, err)
+			return err
+		}
+		newNBNodes, err := l.buildNodeBalancerNodes(service, backendIPs, drained, backendPort)
+		if err != nil {
+			sentry.CaptureError(ctx, err)
+			return err
+		}
+		for i := range newNBNodes {
+			oldNodeID, ok := oldNBNodeIDs[newNBNodes[i].Address]
 			if ok {
-				newNodeOpts.ID = oldNodeID
+				newNBNodes[i].ID = oldNodeID
 			} else {
-				klog.Infof("No preexisting node id for %v found.", newNodeOpts.Address)
+				klog.Infof("No preexisting node id for %v found.", newNBNodes[i].Address)
 			}
-			newNBNodes = append(newNBNodes, newNodeOpts)
 		}
 
-		// If there's no existing config, create it
-		var rebuildOpts linodego.NodeBalancerConfigRebuildOptions
-		if currentNBCfg == nil {
-			createOpts := newNBCfg.GetCreateOptions()
+		// Keep a healthy backend in rotation until one of the new backends
+		// passes its health checks, rather than dropping every healthy backend
+		// at once. The next reconcile removes it.
+		wanted := make(map[string]bool, len(newNBNodes))
+		for _, node := range newNBNodes {
+			wanted[node.Address] = true
+		}
+		if retained := retainedBackend(currentNBNodes, wanted); retained != nil {
+			l.recordEvent(service, v1.EventTypeNormal, eventReasonBackendRetained,
+				"keeping backend %s of port %d of NodeBalancer (%d) until a new backend is healthy",
+				retained.Address, port.Port, nb.ID)
+			newNBNodes = append(newNBNodes, linodego.NodeBalancerConfigRebuildNodeOptions{
+				NodeBalancerNodeCreateOptions: linodego.NodeBalancerNodeCreateOptions{
+					Address: retained.Address,
+					Label:   retained.Label,
+					Weight:  retained.Weight,
+					Mode:    retained.Mode,
+				},
+				ID: retained.ID,
+			})
+			backendsRetained = true
+		}
+
+		nodeLabels := make([]*linodego.NodeBalancerNodeCreateOptions, 0, len(newNBNodes))
+		for i := range newNBNodes {
+			nodeLabels = append(nodeLabels, &newNBNodes[i].NodeBalancerNodeCreateOptions)
+		}
+		uniqueBackendLabels(nodeLabels)
+
+		if err = checkLastGoodCert(newNBCfg, currentNBCfg); err != nil {
+			sentry.CaptureError(ctx, err)
+			return err
+		}
+
+		// If there's no existing config, create it. Its backends are set by
+		// the rebuild that follows.
+		created := currentNBCfg == nil
+		if created {
+			createOpts := newNodeBalancerConfigOptions(newNBCfg, nil).create
 
 			currentNBCfg, err = l.client.CreateNodeBalancerConfig(ctx, nb.ID, createOpts)
 			if err != nil {
 				sentry.CaptureError(ctx, err)
 				return fmt.Errorf("[port %d] error creating NodeBalancer config: %v", int(port.Port), err)
 			}
-			rebuildOpts = currentNBCfg.GetRebuildOptions()
+			createdCfgs++
+		}
+
+		rebuildOpts := newNodeBalancerConfigOptions(newNBCfg, newNBNodes).rebuild
 
-			// SSLCert and SSLKey return <REDACTED> from the API, so copy the
-			// value that we sent in create for the rebuild
-			rebuildOpts.SSLCert = newNBCfg.SSLCert
-			rebuildOpts.SSLKey = newNBCfg.SSLKey
+		if created || !nodeBalancerConfigUpToDate(*currentNBCfg, currentNBNodes, rebuildOpts) {
+			rebuiltNBCfg, err := l.client.RebuildNodeBalancerConfig(ctx, nb.ID, currentNBCfg.ID, rebuildOpts)
+			if err != nil {
+				sentry.CaptureError(ctx, err)
+				return fmt.Errorf("[port %d] error rebuilding NodeBalancer config: %v", int(port.Port), err)
+			}
+			logTLSConfig(service, rebuiltNBCfg)
 		} else {
-			rebuildOpts = newNBCfg.GetRebuildOptions()
+			klog.V(3).Infof("NodeBalancer (%d) config (%d) for port %d of service (%s) is up to date, not rebuilding it", nb.ID, currentNBCfg.ID, port.Port, getServiceNn(service))
 		}
 
-		rebuildOpts.Nodes = newNBNodes
-
-		if _, err = l.client.RebuildNodeBalancerConfig(ctx, nb.ID, currentNBCfg.ID, rebuildOpts); err != nil {
-			sentry.CaptureError(ctx, err)
-			return fmt.Errorf("[port %d] error rebuilding NodeBalancer config: %v", int(port.Port), err)
+		addresses := make([]string, 0, len(newNBNodes))
+		for _, node := range newNBNodes {
+			addresses = append(addresses, node.Address)
 		}
+		l.backends.observe(nb.ID, addresses, time.Now())
+		appliedCfgs = append(appliedCfgs, newNBCfg)
 	}
 
+	reportConfigCount(service, nb.ID, len(nbCfgs)-deletedCfgs+createdCfgs)
+	l.reportEffectiveConfig(ctx, service, nb, appliedCfgs)
+
+	// a retained backend must be removed once the new backends are healthy,
+	// so the Service is not considered up to date until then
+	if !backendsRetained {
+		l.reconciled.record(service, fingerprint)
+	}
 	return nil
 }
 
-// UpdateLoadBalancer updates the NodeBalancer to have configs that match the Service's ports
-func (l *loadbalancers) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (err error) {
-	ctx = sentry.SetHubOnContext(ctx)
-	sentry.SetTag(ctx, "cluster_name", clusterName)
-	sentry.SetTag(ctx, "service", service.Name)
+// drainRemovedBackends sets every backend which is no longer among backendIPs
+// to drain mode, and returns a RetryError until the drain period of all of
+// them is over, so that the whole batch is drained before any of it is removed
+// by rebuilding the configs. The worker is not blocked in the meantime: the
+// reconcile requeued once the drain period is over removes them.
+func (l *loadbalancers) drainRemovedBackends(ctx context.Context, service *v1.Service, backendIPs map[string]string, nb *linodego.NodeBalancer, nbCfgs []linodego.NodeBalancerConfig) error {
+	if Options.BackendDrainPeriod <= 0 {
+		return nil
+	}
 
-	// handle LoadBalancers backed by Cilium
-	if l.loadBalancerType == ciliumLBType {
-		klog.Infof("handling update for LoadBalancer Service %s/%s as %s", service.Namespace, service.Name, ciliumLBClass)
-		// make sure that IPs are shared properly on the Node if using load-balancers not backed by NodeBalancers
-		for _, node := range nodes {
-			if err := l.handleIPSharing(ctx, node); err != nil {
-				return err
+	now := time.Now()
+	draining := 0
+	var remaining time.Duration
+	for _, port := range service.Spec.Ports {
+		var nbCfg *linodego.NodeBalancerConfig
+		for i := range nbCfgs {
+			if nbCfgs[i].Port == int(port.Port) {
+				nbCfg = &nbCfgs[i]
+				break
+			}
+		}
+		if nbCfg == nil {
+			continue
+		}
+
+		wanted, err := l.wantedBackends(service, backendIPs, port)
+		if err != nil {
+			return err
+		}
+
+		currentNBNodes, err := l.client.ListNodeBalancerNodes(ctx, nb.ID, nbCfg.ID, nil)
+		if err != nil {
+			return err
+		}
+		retained := retainedBackend(currentNBNodes, wanted)
+		for _, nbNode := range currentNBNodes {
+			if wanted[nbNode.Address] {
+				continue
+			}
+			if retained != nil && nbNode.ID == retained.ID {
+				continue
+			}
+			if nbNode.Mode != linodego.ModeDrain {
+				klog.Infof("draining backend %s of NodeBalancer (%d) config (%d) before removal", nbNode.Address, nb.ID, nbCfg.ID)
+				if _, err = l.client.UpdateNodeBalancerNode(ctx, nb.ID, nbCfg.ID, nbNode.ID, linodego.NodeBalancerNodeUpdateOptions{Mode: linodego.ModeDrain}); err != nil {
+					return fmt.Errorf("[port %d] error draining NodeBalancer backend %s: %w", port.Port, nbNode.Address, err)
+				}
+				l.backends.drain(nb.ID, nbNode.Address, now.Add(Options.BackendDrainPeriod))
+			}
+			if left := l.backends.drainRemaining(nb.ID, nbNode.Address, now); left > 0 {
+				draining++
+				remaining = max(remaining, left)
 			}
 		}
-		return nil
 	}
 
-	// UpdateLoadBalancer is invoked with a nil LoadBalancerStatus; we must fetch the latest
-	// status for NodeBalancer discovery.
-	serviceWithStatus := service.DeepCopy()
-	serviceWithStatus.Status.LoadBalancer, err = l.getLatestServiceLoadBalancerStatus(ctx, service)
-	if err != nil {
-		return fmt.Errorf("failed to get latest LoadBalancer status for service (%s): %s", getServiceNn(service), err)
+	if draining == 0 {
+		return nil
 	}
 
-	nb, err := l.getNodeBalancerForService(ctx, serviceWithStatus)
-	if err != nil {
-		sentry.CaptureError(ctx, err)
-		return err
+	klog.Infof("waiting %s for %d backends of NodeBalancer (%d) to drain", remaining, draining, nb.ID)
+	return api.NewRetryError(fmt.Sprintf("waiting %s for %d backends of NodeBalancer (%d) to drain before removing them", remaining, draining, nb.ID), remaining)
+}
+
+// removeStaleBackends deletes every backend which is no longer among backendIPs
+// in batches of Options.BackendRemovalBatchSize, before the configs are
+// rebuilt. Without a batch size the rebuild removes them all in one request.
+func (l *loadbalancers) removeStaleBackends(ctx context.Context, service *v1.Service, backendIPs map[string]string, nb *linodego.NodeBalancer, nbCfgs []linodego.NodeBalancerConfig) error {
+	if Options.BackendRemovalBatchSize <= 0 {
+		return nil
 	}
 
-	if !l.shouldPreserveNodeBalancer(service) {
-		if err := l.cleanupOldNodeBalancer(ctx, service); err != nil {
-			sentry.CaptureError(ctx, err)
+	var stale []configBackend
+	for _, port := range service.Spec.Ports {
+		var nbCfg *linodego.NodeBalancerConfig
+		for i := range nbCfgs {
+			if nbCfgs[i].Port == int(port.Port) {
+				nbCfg = &nbCfgs[i]
+				break
+			}
+		}
+		if nbCfg == nil {
+			continue
+		}
+
+		wanted, err := l.wantedBackends(service, backendIPs, port)
+		if err != nil {
+			return err
+		}
+
+		currentNBNodes, err := l.client.ListNodeBalancerNodes(ctx, nb.ID, nbCfg.ID, nil)
+		if err != nil {
 			return err
 		}
+		retained := retainedBackend(currentNBNodes, wanted)
+		for _, nbNode := range currentNBNodes {
+			if wanted[nbNode.Address] || (retained != nil && nbNode.ID == retained.ID) {
+				continue
+			}
+			stale = append(stale, configBackend{port: port.Port, configID: nbCfg.ID, node: nbNode})
+		}
 	}
 
-	return l.updateNodeBalancer(ctx, clusterName, serviceWithStatus, nodes, nb)
+	if len(stale) > 0 {
+		klog.Infof("removing %d backends of NodeBalancer (%d) for service (%s)", len(stale), nb.ID, getServiceNn(service))
+	}
+	return l.deleteBackends(ctx, nb, stale)
 }
 
-// Delete any NodeBalancer configs for ports that no longer exist on the Service
-// Note: Don't build a map or other lookup structure here, it is not worth the overhead
-func (l *loadbalancers) deleteUnusedConfigs(ctx context.Context, nbConfigs []linodego.NodeBalancerConfig, servicePorts []v1.ServicePort) error {
-	for _, nbc := range nbConfigs {
-		found := false
-		for _, sp := range servicePorts {
-			if nbc.Port == int(sp.Port) {
-				found = true
+// configBackend is a backend of the NodeBalancer config for port.
+type configBackend struct {
+	port     int32
+	configID int
+	node     linodego.NodeBalancerNode
+}
+
+// deleteBackends deletes backends from nb. When Options.BackendRemovalBatchSize
+// is set, they are deleted in batches of that size separated by
+// Options.BackendRemovalBatchDelay, so that removing many backends does not
+// exhaust the API rate limits.
+func (l *loadbalancers) deleteBackends(ctx context.Context, nb *linodego.NodeBalancer, backends []configBackend) error {
+	batchSize := Options.BackendRemovalBatchSize
+	if batchSize <= 0 {
+		batchSize = len(backends)
+	}
+
+	for start := 0; start < len(backends); start += batchSize {
+		if start > 0 {
+			klog.V(3).Infof("waiting %s before deleting the next batch of backends of NodeBalancer (%d)", Options.BackendRemovalBatchDelay, nb.ID)
+			select {
+			case <-time.After(Options.BackendRemovalBatchDelay):
+			case <-ctx.Done():
+				return ctx.Err()
 			}
 		}
-		if !found {
-			if err := l.client.DeleteNodeBalancerConfig(ctx, nbc.NodeBalancerID, nbc.ID); err != nil {
-				return err
+
+		for _, backend := range backends[start:min(start+batchSize, len(backends))] {
+			klog.Infof("deleting backend %s of NodeBalancer (%d) config (%d)", backend.node.Address, nb.ID, backend.configID)
+			if err := l.client.DeleteNodeBalancerNode(ctx, nb.ID, backend.configID, backend.node.ID); err != nil {
+				return fmt.Errorf("[port %d] error deleting NodeBalancer backend %s: %w", backend.port, backend.node.Address, err)
 			}
 		}
 	}
 	return nil
 }
 
-// shouldPreserveNodeBalancer determines whether a NodeBalancer should be deleted based on the
-// service's preserve annotation.
-func (l *loadbalancers) shouldPreserveNodeBalancer(service *v1.Service) bool {
-	return getServiceBoolAnnotation(service, annotations.AnnLinodeLoadBalancerPreserve)
+// wantedBackends returns the addresses of the backends of port on the nodes of
+// backendIPs.
+func (l *loadbalancers) wantedBackends(service *v1.Service, backendIPs map[string]string, port v1.ServicePort) (map[string]bool, error) {
+	backendPort, err := getBackendPort(service, port)
+	if err != nil {
+		return nil, err
+	}
+	nodeOpts, err := l.buildNodeBalancerNodes(service, backendIPs, nil, backendPort)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(nodeOpts))
+	for _, opts := range nodeOpts {
+		wanted[opts.Address] = true
+	}
+	return wanted, nil
 }
 
-// EnsureLoadBalancerDeleted deletes the specified loadbalancer if it exists.
-// nil is returned if the load balancer for service does not exist or is
-// successfully deleted.
-//
-// EnsureLoadBalancerDeleted will not modify service.
-func (l *loadbalancers) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
-	ctx = sentry.SetHubOnContext(ctx)
+// deleteOrphanedBackends deletes the backends of the NodeBalancer configs which
+// are not among backendIPs, or duplicate another backend. Rebuilding the configs
+// replaces all of their backends, so this is only needed when they are not
+// rebuilt, to clean up backends left behind by a failed reconcile or added
+// out of band.
+func (l *loadbalancers) deleteOrphanedBackends(ctx context.Context, service *v1.Service, backendIPs map[string]string, nb *linodego.NodeBalancer, nbCfgs []linodego.NodeBalancerConfig) error {
+	var orphaned []configBackend
+	for _, port := range service.Spec.Ports {
+		var nbCfg *linodego.NodeBalancerConfig
+		for i := range nbCfgs {
+			if nbCfgs[i].Port == int(port.Port) {
+				nbCfg = &nbCfgs[i]
+				break
+			}
+		}
+		if nbCfg == nil {
+			continue
+		}
+
+		wanted, err := l.wantedBackends(service, backendIPs, port)
+		if err != nil {
+			return err
+		}
+
+		currentNBNodes, err := l.client.ListNodeBalancerNodes(ctx, nb.ID, nbCfg.ID, nil)
+		if err != nil {
+			return err
+		}
+		retained := retainedBackend(currentNBNodes, wanted)
+		seen := make(map[string]bool, len(currentNBNodes))
+		for _, nbNode := range currentNBNodes {
+			if wanted[nbNode.Address] && !seen[nbNode.Address] {
+				seen[nbNode.Address] = true
+				continue
+			}
+			if retained != nil && nbNode.ID == retained.ID {
+				klog.Infof("keeping orphaned backend %s of NodeBalancer (%d) config (%d) as its only healthy backend", nbNode.Address, nb.ID, nbCfg.ID)
+				continue
+			}
+			klog.Infof("found orphaned backend %s of NodeBalancer (%d) config (%d) for service (%s)", nbNode.Address, nb.ID, nbCfg.ID, getServiceNn(service))
+			orphaned = append(orphaned, configBackend{port: port.Port, configID: nbCfg.ID, node: nbNode})
+		}
+	}
+	return l.deleteBackends(ctx, nb, orphaned)
+}
+
+// UpdateLoadBalancer updates the NodeBalancer to have configs that match the Service's ports
+func (l *loadbalancers) UpdateLoadBalancer(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (err error) {
+	ctx = sentry.SetHubOnContext(ctx)
+	sentry.SetTag(ctx, "cluster_name", clusterName)
+	sentry.SetTag(ctx, "service", service.Name)
+
+	defer l.locks.lock(service)()
+
+	if !isNamespaceManaged(service.Namespace) {
+		klog.V(3).Infof("ignoring LoadBalancer Service %s outside of the managed namespaces", getServiceNn(service))
+		return cloudprovider.ImplementedElsewhere
+	}
+
+	// handle LoadBalancers backed by Cilium
+	if l.loadBalancerType == ciliumLBType {
+		klog.Infof("handling update for LoadBalancer Service %s/%s as %s", service.Namespace, service.Name, ciliumLBClass)
+		// make sure that IPs are shared properly on the Node if using load-balancers not backed by NodeBalancers
+		for _, node := range nodes {
+			if err := l.handleIPSharing(ctx, node); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err = l.retries.exhausted(service, nodes, Options.MaxReconcileRetries, Options.ReconcileRetryCooldown, time.Now()); err != nil {
+		klog.V(3).Info(err)
+		return err
+	}
+	defer func() { l.observeReconcile(service, nodes, err) }()
+	defer func() { err = l.requeueMissingTLSSecret(service, err) }()
+	if ctx, err = l.rateLimiters.withServiceRateLimit(ctx, service); err != nil {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidAnnotation, "%s", err)
+		return err
+	}
+
+	if err = l.validateServicePorts(service); err != nil {
+		return err
+	}
+	if err = l.validateIdleTimeout(service); err != nil {
+		return err
+	}
+	if err = l.validateServiceAnnotations(service); err != nil {
+		return err
+	}
+
+	// UpdateLoadBalancer is invoked with a nil LoadBalancerStatus; we must fetch the latest
+	// status for NodeBalancer discovery.
+	serviceWithStatus := service.DeepCopy()
+	serviceWithStatus.Status.LoadBalancer, err = l.getLatestServiceLoadBalancerStatus(ctx, service)
+	if err != nil {
+		return fmt.Errorf("failed to get latest LoadBalancer status for service (%s): %s", getServiceNn(service), err)
+	}
+
+	nb, err := l.getNodeBalancerForService(ctx, serviceWithStatus)
+	if err != nil {
+		sentry.CaptureError(ctx, err)
+		return err
+	}
+
+	preserve, err := l.shouldPreserveNodeBalancer(service)
+	if err != nil {
+		return err
+	}
+	if !preserve {
+		if err := l.cleanupOldNodeBalancer(ctx, service); err != nil {
+			sentry.CaptureError(ctx, err)
+			return err
+		}
+	}
+
+	return l.updateNodeBalancer(ctx, clusterName, serviceWithStatus, nodes, nb)
+}
+
+// observeReconcile counts a failed reconcile of service against its retry
+// budget, emitting a Warning event once the budget is exhausted. Requeues
+// requested while a NodeBalancer is being provisioned and transient errors are
+// not counted.
+func (l *loadbalancers) observeReconcile(service *v1.Service, nodes []*v1.Node, err error) {
+	var retryErr *api.RetryError
+	switch {
+	case err == nil:
+		l.retries.forget(service)
+	case Options.MaxReconcileRetries <= 0 || errors.As(err, &retryErr) || isTransientReconcileError(err):
+		return
+	default:
+		if attempts := l.retries.fail(service, nodes, err, time.Now()); attempts == Options.MaxReconcileRetries {
+			klog.Errorf("giving up on service (%s) after %d failed reconciles until it or its nodes change: %s", getServiceNn(service), attempts, err)
+			l.recordEvent(service, v1.EventTypeWarning, eventReasonRetriesExhausted,
+				"Giving up after %d failed reconciles, the Service is reconciled again once its spec, annotations or nodes change, or after %s: %s",
+				attempts, Options.ReconcileRetryCooldown, err)
+		}
+	}
+}
+
+// nodeBalancerConfigUpToDate reports whether rebuilding current with opts
+// would leave it and its backends unchanged. Fields left empty in opts are not
+// sent on rebuild, so they are not compared. TLS certificates are never
+// returned by the API, so configs which set one are never up to date.
+func nodeBalancerConfigUpToDate(current linodego.NodeBalancerConfig, currentNodes []linodego.NodeBalancerNode, opts linodego.NodeBalancerConfigRebuildOptions) bool {
+	if opts.SSLCert != "" || opts.SSLKey != "" {
+		return false
+	}
+
+	fields := func(opts linodego.NodeBalancerConfigRebuildOptions) map[string]any {
+		opts.Nodes, opts.SSLCert, opts.SSLKey = nil, "", ""
+		data, err := json.Marshal(opts)
+		if err != nil {
+			return nil
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil
+		}
+		return fields
+	}
+	wanted, actual := fields(opts), fields(newNodeBalancerConfigOptions(current, nil).rebuild)
+	if wanted == nil || actual == nil {
+		return false
+	}
+	for field, value := range wanted {
+		if field != "nodes" && !reflect.DeepEqual(value, actual[field]) {
+			return false
+		}
+	}
+
+	if len(currentNodes) != len(opts.Nodes) {
+		return false
+	}
+	backends := make(map[linodego.NodeBalancerNodeCreateOptions]bool, len(currentNodes))
+	for _, node := range currentNodes {
+		backends[linodego.NodeBalancerNodeCreateOptions{Address: node.Address, Label: node.Label, Weight: node.Weight, Mode: node.Mode}] = true
+	}
+	for _, node := range opts.Nodes {
+		if !backends[node.NodeBalancerNodeCreateOptions] {
+			return false
+		}
+	}
+	return true
+}
+
+// Delete any NodeBalancer configs for ports that no longer exist on the Service
+// Under the manage-only-owned config policy only the configs for ownedPorts
+// are deleted, unless ownedPorts is nil because the NodeBalancer predates the
+// config port tags.
+//
+// It returns the number of deleted configs.
+//
+// Note: Don't build a map or other lookup structure here, it is not worth the overhead
+func (l *loadbalancers) deleteUnusedConfigs(ctx context.Context, nbConfigs []linodego.NodeBalancerConfig, servicePorts []v1.ServicePort, ownedPorts map[int]bool) (int, error) {
+	manageAll := Options.NodeBalancerConfigPolicy == configPolicyManageAll || ownedPorts == nil
+	deleted := 0
+	for _, nbc := range nbConfigs {
+		found := false
+		for _, sp := range servicePorts {
+			if nbc.Port == int(sp.Port) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if !manageAll && !ownedPorts[nbc.Port] {
+			klog.V(3).Infof("leaving NodeBalancer (%d) config (%d) for port %d alone, it is not managed by the CCM", nbc.NodeBalancerID, nbc.ID, nbc.Port)
+			continue
+		}
+		klog.Infof("deleting NodeBalancer (%d) config (%d) for port %d, which was removed from the service", nbc.NodeBalancerID, nbc.ID, nbc.Port)
+		if err := l.client.DeleteNodeBalancerConfig(ctx, nbc.NodeBalancerID, nbc.ID); err != nil {
+			return deleted, fmt.Errorf("[port %d] error deleting NodeBalancer config: %w", nbc.Port, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// shouldPreserveNodeBalancer determines whether a NodeBalancer should be deleted based on the
+// service's preserve annotation. An invalid value is an error rather than
+// false, so that a mistyped annotation does not get the NodeBalancer deleted.
+func (l *loadbalancers) shouldPreserveNodeBalancer(service *v1.Service) (bool, error) {
+	return getServiceBoolAnnotation(service, annotations.AnnLinodeLoadBalancerPreserve)
+}
+
+// EnsureLoadBalancerDeleted deletes the specified loadbalancer if it exists.
+// nil is returned if the load balancer for service does not exist or is
+// successfully deleted.
+//
+// EnsureLoadBalancerDeleted will not modify service.
+func (l *loadbalancers) EnsureLoadBalancerDeleted(ctx context.Context, clusterName string, service *v1.Service) error {
+	ctx = sentry.SetHubOnContext(ctx)
 	sentry.SetTag(ctx, "cluster_name", clusterName)
 	sentry.SetTag(ctx, "service", service.Name)
 
+	defer l.locks.lock(service)()
+
+	// ImplementedElsewhere must not be returned here, so that the finalizer is
+	// still removed from Services this CCM does not manage
+	if !isNamespaceManaged(service.Namespace) {
+		return l.deleteOwnedNodeBalancer(ctx, clusterName, service)
+	}
+
 	// Handle LoadBalancers backed by Cilium
 	if l.loadBalancerType == ciliumLBType {
 		klog.Infof("handling LoadBalancer Service %s/%s as %s", service.Namespace, service.Name, ciliumLBClass)
@@ -514,8 +1356,20 @@ func (l *loadbalancers) EnsureLoadBalancerDeleted(ctx context.Context, clusterNa
 	// Handle LoadBalancers backed by NodeBalancers
 
 	serviceNn := getServiceNn(service)
+	l.reconciled.forget(service)
+	l.retries.forget(service)
+	l.tlsSecretRetries.forget(service)
+	// an invalid rate limit must not keep the NodeBalancer from being deleted
+	if limitedCtx, err := l.rateLimiters.withServiceRateLimit(ctx, service); err != nil {
+		klog.Warningf("not rate limiting the deletion of the NodeBalancer for service (%s): %s", serviceNn, err)
+	} else {
+		ctx = limitedCtx
+	}
+	defer l.rateLimiters.forget(service)
 
-	if len(service.Status.LoadBalancer.Ingress) == 0 {
+	// a NodeBalancer which was not assigned an IP yet is not in the status
+	_, pending := l.pendingIPs.get(service)
+	if len(service.Status.LoadBalancer.Ingress) == 0 && !pending {
 		klog.Infof("short-circuiting deletion of NodeBalancer for service(%s) as LoadBalancer ingress is not present", serviceNn)
 		return nil
 	}
@@ -527,6 +1381,7 @@ func (l *loadbalancers) EnsureLoadBalancerDeleted(ctx context.Context, clusterNa
 
 	case lbNotFoundError:
 		klog.Infof("short-circuiting deletion for NodeBalancer for service (%s) as one does not exist: %s", serviceNn, err)
+		l.pendingIPs.forget(service)
 		return nil
 
 	default:
@@ -535,23 +1390,187 @@ func (l *loadbalancers) EnsureLoadBalancerDeleted(ctx context.Context, clusterNa
 		return err
 	}
 
-	if l.shouldPreserveNodeBalancer(service) {
+	preserve, err := l.shouldPreserveNodeBalancer(service)
+	if err != nil {
+		klog.Errorf("not deleting NodeBalancer (%d) for service (%s): %s", nb.ID, serviceNn, err)
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidAnnotation, "Not deleting NodeBalancer (%d): %s", nb.ID, err)
+		return err
+	}
+	if preserve {
 		klog.Infof(
 			"short-circuiting deletion of NodeBalancer (%d) for service (%s) as annotated with %s",
 			nb.ID,
 			serviceNn,
 			annotations.AnnLinodeLoadBalancerPreserve,
 		)
+		l.pendingIPs.forget(service)
+		// a preserved NodeBalancer is no longer reconciled, until a Service
+		// selects it again with the nodebalancer-id annotation
+		deleteServiceMetrics(service)
+		return nil
+	}
+
+	// a NodeBalancer the CCM did not create for the Service is only released
+	adopted := isAdoptedNodeBalancer(service, nb)
+	if err = l.removeNodeBalancer(ctx, service, nb, adopted); err != nil {
+		klog.Errorf("failed to delete NodeBalancer (%d) for service (%s): %s", nb.ID, serviceNn, err)
+		sentry.CaptureError(ctx, err)
+		return err
+	}
+	l.backends.forget(nb.ID)
+	l.pendingIPs.forget(service)
+	deleteServiceMetrics(service)
+
+	if adopted {
+		klog.Infof("successfully released NodeBalancer (%d) for service (%s)", nb.ID, serviceNn)
+	} else {
+		klog.Infof("successfully deleted NodeBalancer (%d) for service (%s)", nb.ID, serviceNn)
+	}
+	return nil
+}
+
+// removeNodeBalancer deletes nb, created by the CCM for service, along with the
+// firewall created for its firewall ACL annotation, which would outlive it.
+// When adopted is set, nb was not created for service and is only released.
+func (l *loadbalancers) removeNodeBalancer(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer, adopted bool) error {
+	if adopted {
+		return l.releaseNodeBalancer(ctx, service, nb)
+	}
+	if err := l.deleteACLFirewall(ctx, service, nb); err != nil {
+		return fmt.Errorf("failed to delete the firewall of NodeBalancer (%d): %w", nb.ID, err)
+	}
+	return l.client.DeleteNodeBalancer(ctx, nb.ID)
+}
+
+// deleteServiceMetrics removes the metrics exposed for the NodeBalancer of
+// service, once it is no longer managed for it.
+func deleteServiceMetrics(service *v1.Service) {
+	serviceNn := getServiceNn(service)
+	for _, port := range service.Spec.Ports {
+		certExpirySeconds.Delete(map[string]string{"service": serviceNn, "port": strconv.Itoa(int(port.Port))})
+	}
+	nodeBalancerTransferUsed.Delete(map[string]string{"service": serviceNn})
+	nodeBalancerConfigCount.DeletePartialMatch(map[string]string{"service": serviceNn})
+	regionMismatch.Delete(map[string]string{"service": serviceNn})
+}
+
+// releaseNodeBalancer detaches service from nb, a NodeBalancer the CCM did not
+// create for it, instead of deleting it: the configs the CCM manages on nb,
+// and so their backends, are deleted along with the firewall created for the
+// firewall ACL annotation and the tags recording the CCM state. The configs
+// and tags nb was given by other means are kept.
+func (l *loadbalancers) releaseNodeBalancer(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer) error {
+	if err := l.deleteACLFirewall(ctx, service, nb); err != nil {
+		return err
+	}
+
+	configs, err := l.client.ListNodeBalancerConfigs(ctx, nb.ID, nil)
+	if err != nil {
+		return err
+	}
+	ownedPorts := getOwnedConfigPorts(nb.Tags)
+	for _, config := range configs {
+		if !ownedPorts[config.Port] {
+			continue
+		}
+		if err = l.client.DeleteNodeBalancerConfig(ctx, nb.ID, config.ID); err != nil {
+			return err
+		}
+	}
+
+	tags := make([]string, 0, len(nb.Tags))
+	for _, tag := range nb.Tags {
+		if !strings.HasPrefix(tag, configPortTagPrefix) && !strings.HasPrefix(tag, auditTagResourceVersionPrefix) && !strings.HasPrefix(tag, auditTagAppliedAtPrefix) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) != len(nb.Tags) {
+		update := nb.GetUpdateOptions()
+		update.Tags = &tags
+		if _, err = l.client.UpdateNodeBalancer(ctx, nb.ID, update); err != nil {
+			return err
+		}
+	}
+
+	l.recordEvent(service, v1.EventTypeNormal, eventReasonNodeBalancerReleased,
+		"NodeBalancer (%d) was not created for the service, only its configs were deleted", nb.ID)
+	return nil
+}
+
+// deleteACLFirewall deletes the firewall the CCM created for the firewall ACL
+// annotation of service, unless it is attached to anything other than nb.
+// Firewalls referenced by ID are managed by the user and left alone.
+func (l *loadbalancers) deleteACLFirewall(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer) error {
+	if _, ok := service.GetAnnotations()[annotations.AnnLinodeCloudFirewallID]; ok {
+		return nil
+	}
+	if _, ok := service.GetAnnotations()[annotations.AnnLinodeCloudFirewallACL]; !ok {
+		return nil
+	}
+
+	fwClient := firewall.LinodeClient{Client: l.client}
+	return fwClient.DetachNodeBalancerFirewall(ctx, nb)
+}
+
+// deleteOwnedNodeBalancer deletes the NodeBalancer tagged as owned by a Service
+// outside of the managed namespaces, which was created before its namespace
+// was excluded. Only NodeBalancers tagged with clusterName are deleted: the
+// others were created by another CCM, e.g. one managing the namespace side by
+// side with this one, and are left alone.
+func (l *loadbalancers) deleteOwnedNodeBalancer(ctx context.Context, clusterName string, service *v1.Service) error {
+	serviceNn := getServiceNn(service)
+	if l.loadBalancerType == ciliumLBType || len(service.Status.LoadBalancer.Ingress) == 0 {
+		klog.V(3).Infof("ignoring LoadBalancer Service %s outside of the managed namespaces", serviceNn)
+		return nil
+	}
+
+	nb, err := l.getNodeBalancerByOwnerTag(ctx, service)
+	switch err.(type) {
+	case nil:
+		break
+
+	case lbNotFoundError:
+		klog.V(3).Infof("ignoring LoadBalancer Service %s outside of the managed namespaces", serviceNn)
+		return nil
+
+	default:
+		klog.Errorf("failed to get NodeBalancer for service (%s): %s", serviceNn, err)
+		sentry.CaptureError(ctx, err)
+		return err
+	}
+	if clusterName == "" || !slices.Contains(nb.Tags, clusterName) {
+		klog.V(3).Infof("ignoring NodeBalancer (%d) of LoadBalancer Service %s outside of the managed namespaces, not created for cluster %q", nb.ID, serviceNn, clusterName)
 		return nil
 	}
 
-	if err = l.client.DeleteNodeBalancer(ctx, nb.ID); err != nil {
+	preserve, err := l.shouldPreserveNodeBalancer(service)
+	if err != nil {
+		klog.Errorf("not deleting NodeBalancer (%d) for service (%s): %s", nb.ID, serviceNn, err)
+		return err
+	}
+	if preserve {
+		klog.Infof("short-circuiting deletion of NodeBalancer (%d) for service (%s) as annotated with %s", nb.ID, serviceNn, annotations.AnnLinodeLoadBalancerPreserve)
+		return nil
+	}
+
+	adopted := isAdoptedNodeBalancer(service, nb)
+	if err = l.removeNodeBalancer(ctx, service, nb, adopted); err != nil {
 		klog.Errorf("failed to delete NodeBalancer (%d) for service (%s): %s", nb.ID, serviceNn, err)
 		sentry.CaptureError(ctx, err)
 		return err
 	}
+	l.reconciled.forget(service)
+	l.retries.forget(service)
+	l.tlsSecretRetries.forget(service)
+	l.backends.forget(nb.ID)
+	l.pendingIPs.forget(service)
+	deleteServiceMetrics(service)
 
-	klog.Infof("successfully deleted NodeBalancer (%d) for service (%s)", nb.ID, serviceNn)
+	if adopted {
+		klog.Infof("successfully released NodeBalancer (%d) for service (%s) outside of the managed namespaces", nb.ID, serviceNn)
+	} else {
+		klog.Infof("successfully deleted NodeBalancer (%d) for service (%s) outside of the managed namespaces", nb.ID, serviceNn)
+	}
 	return nil
 }
 
@@ -561,7 +1580,7 @@ func (l *loadbalancers) getNodeBalancerByHostname(ctx context.Context, service *
 		return nil, err
 	}
 	for _, lb := range lbs {
-		if *lb.Hostname == hostname {
+		if lb.Hostname != nil && *lb.Hostname == hostname {
 			klog.V(2).Infof("found NodeBalancer (%d) for service (%s) via hostname (%s)", lb.ID, getServiceNn(service), hostname)
 			return &lb, nil
 		}
@@ -593,32 +1612,111 @@ func (l *loadbalancers) getNodeBalancerByID(ctx context.Context, service *v1.Ser
 	return nb, nil
 }
 
+// GetLoadBalancerTags returns the tags of the NodeBalancer of service set from
+// the cluster name, the AnnLinodeLoadBalancerTags annotation and the labels
+// mapped by Options.NodeBalancerLabelTags, without duplicates, which the API
+// would drop and the next update would then add again.
 func (l *loadbalancers) GetLoadBalancerTags(_ context.Context, clusterName string, service *v1.Service) []string {
 	tags := []string{}
 	if clusterName != "" {
 		tags = append(tags, clusterName)
 	}
 
-	tagStr, ok := service.GetAnnotations()[annotations.AnnLinodeLoadBalancerTags]
-	if ok {
-		return append(tags, strings.Split(tagStr, ",")...)
+	// invalid tags are rejected by validateServiceAnnotations before the
+	// NodeBalancer is reconciled
+	annotationTags, _ := getAnnotationTags(service)
+	for _, tag := range append(annotationTags, getLabelTags(service)...) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// getAnnotationTags returns the comma separated tags of the
+// AnnLinodeLoadBalancerTags annotation of service, which must be between
+// minTagLength and maxTagLength characters long to be accepted by the API.
+func getAnnotationTags(service *v1.Service) ([]string, error) {
+	tags, _ := getAnnotationStringList(service, annotations.AnnLinodeLoadBalancerTags)
+	for _, tag := range tags {
+		if len(tag) < minTagLength || len(tag) > maxTagLength {
+			return nil, invalidAnnotationError{
+				name:   annotations.AnnLinodeLoadBalancerTags,
+				value:  tag,
+				reason: fmt.Sprintf("tags must be between %d and %d characters long", minTagLength, maxTagLength),
+			}
+		}
 	}
+	return tags, nil
+}
+
+// getLabelTags returns the tags derived from the Service labels mapped by
+// Options.NodeBalancerLabelTags, in the form <tag-key>:<label value>.
+func getLabelTags(service *v1.Service) []string {
+	labelNames := make([]string, 0, len(Options.NodeBalancerLabelTags))
+	for label := range Options.NodeBalancerLabelTags {
+		labelNames = append(labelNames, label)
+	}
+	slices.Sort(labelNames)
 
+	var tags []string
+	for _, label := range labelNames {
+		value, ok := service.Labels[label]
+		if !ok || value == "" {
+			continue
+		}
+		tags = append(tags, Options.NodeBalancerLabelTags[label]+":"+value)
+	}
 	return tags
 }
 
-func (l *loadbalancers) createNodeBalancer(ctx context.Context, clusterName string, service *v1.Service, configs []*linodego.NodeBalancerConfigCreateOptions) (lb *linodego.NodeBalancer, err error) {
-	connThrottle := getConnectionThrottle(service)
+// getAuditTags returns the tags recording the Service resourceVersion last
+// applied to the NodeBalancer and when, if the Service opted in to them. The
+// timestamp is only refreshed when the resourceVersion changes, so that
+// reconciling an unchanged Service does not update the NodeBalancer.
+func getAuditTags(service *v1.Service, currentTags []string, now time.Time) ([]string, error) {
+	enabled, err := getServiceBoolAnnotation(service, annotations.AnnLinodeAuditTags)
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	rvTag := auditTagResourceVersionPrefix + service.ResourceVersion
+	var appliedTag string
+	for _, tag := range currentTags {
+		if strings.HasPrefix(tag, auditTagAppliedAtPrefix) {
+			appliedTag = tag
+		}
+	}
+	if appliedTag == "" || !slices.Contains(currentTags, rvTag) {
+		appliedTag = auditTagAppliedAtPrefix + now.UTC().Format(auditTagTimeFormat)
+	}
+	return []string{rvTag, appliedTag}, nil
+}
+
+func (l *loadbalancers) createNodeBalancer(ctx context.Context, clusterName string, service *v1.Service, region string, configs []*linodego.NodeBalancerConfigCreateOptions) (lb *linodego.NodeBalancer, err error) {
+	connThrottle, err := getConnectionThrottle(service)
+	if err != nil {
+		return nil, err
+	}
+	auditTags, err := getAuditTags(service, nil, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
 	label := l.GetLoadBalancerName(ctx, clusterName, service)
 	tags := l.GetLoadBalancerTags(ctx, clusterName, service)
 	createOpts := linodego.NodeBalancerCreateOptions{
 		Label:              &label,
-		Region:             l.zone,
+		Region:             region,
 		ClientConnThrottle: &connThrottle,
 		Configs:            configs,
-		Tags:               tags,
+		Tags:               append(append(append([]string{}, tags...), auditTags...), getOwnerTag(service)),
+	}
+	configPorts := make([]int, 0, len(configs))
+	for _, config := range configs {
+		configPorts = append(configPorts, config.Port)
 	}
+	createOpts.Tags = append(createOpts.Tags, getConfigPortTags(configPorts)...)
 
 	fwid, ok := service.GetAnnotations()[annotations.AnnLinodeCloudFirewallID]
 	if ok {
@@ -645,82 +1743,392 @@ func (l *loadbalancers) createNodeBalancer(ctx context.Context, clusterName stri
 		// no need to deal with firewalls, continue creating nb's
 	}
 
-	return l.client.CreateNodeBalancer(ctx, createOpts)
-}
-
-//nolint:funlen
-func (l *loadbalancers) buildNodeBalancerConfig(ctx context.Context, service *v1.Service, port int) (linodego.NodeBalancerConfig, error) {
-	portConfig, err := getPortConfig(service, port)
-	if err != nil {
-		return linodego.NodeBalancerConfig{}, err
+	if Options.NodeBalancerProvisionTimeout <= 0 {
+		return l.client.CreateNodeBalancer(ctx, createOpts)
 	}
 
-	health, err := getHealthCheckType(service)
-	if err != nil {
-		return linodego.NodeBalancerConfig{}, err
+	createCtx, cancel := context.WithTimeout(ctx, Options.NodeBalancerProvisionTimeout)
+	defer cancel()
+	nb, err := l.client.CreateNodeBalancer(createCtx, createOpts)
+	if err != nil && createCtx.Err() == context.DeadlineExceeded {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonProvisioningTimeout,
+			"NodeBalancer was not provisioned within %s, will retry", Options.NodeBalancerProvisionTimeout)
+		return nil, fmt.Errorf("%w after %s for service %s: %v", errProvisioningTimeout, Options.NodeBalancerProvisionTimeout, getServiceNn(service), err)
 	}
+	return nb, err
+}
 
-	config := linodego.NodeBalancerConfig{
-		Port:          port,
+// getAdoptableNodeBalancer looks for an existing NodeBalancer to use for a
+// service which has none recorded in its status: the one holding the requested
+// spec.loadBalancerIP, or one created by a previous attempt whose provisioning
+// request timed out.
+func (l *loadbalancers) getAdoptableNodeBalancer(ctx context.Context, clusterName string, service *v1.Service) (*linodego.NodeBalancer, error) {
+	if ip := service.Spec.LoadBalancerIP; ip != "" {
+		nb, err := l.getNodeBalancerByIPv4(ctx, service, ip)
+		switch err.(type) {
+		case nil:
+			if clusterName != "" && !slices.Contains(nb.Tags, clusterName) {
+				return nil, fmt.Errorf("NodeBalancer (%d) with loadBalancerIP %s for service (%s) is not owned by cluster %s", nb.ID, ip, getServiceNn(service), clusterName)
+			}
+			// the NodeBalancer of another Service of the cluster must not be
+			// shared, each Service would overwrite the configs of the other;
+			// one preserved when its Service was deleted may be adopted
+			if owner, ok := getNodeBalancerOwner(nb); ok && owner != string(service.UID) {
+				exists, err := l.serviceExists(ctx, owner)
+				if err != nil {
+					return nil, err
+				}
+				if !exists {
+					return nb, nil
+				}
+				l.recordEvent(service, v1.EventTypeWarning, eventReasonNodeBalancerOwned,
+					"NodeBalancer (%d) with loadBalancerIP %s was created for the service with UID %s, refusing to adopt it", nb.ID, ip, owner)
+				return nil, fmt.Errorf("%w: NodeBalancer (%d) with loadBalancerIP %s for service (%s) belongs to the service with UID %s", errNodeBalancerOwned, nb.ID, ip, getServiceNn(service), owner)
+			}
+			return nb, nil
+		case lbNotFoundError:
+			return nil, fmt.Errorf("no NodeBalancer with loadBalancerIP %s exists for service (%s); Linode assigns NodeBalancer IPs, so loadBalancerIP can only be used to reuse an existing NodeBalancer", ip, getServiceNn(service))
+		default:
+			return nil, err
+		}
+	}
+
+	// a previous attempt may have created the NodeBalancer even though its
+	// provisioning request timed out
+	if Options.NodeBalancerProvisionTimeout > 0 {
+		return l.getNodeBalancerByOwnerTag(ctx, service)
+	}
+	return nil, lbNotFoundError{serviceNn: getServiceNn(service)}
+}
+
+// isNodeBalancerReady reports whether nb was assigned the IPv4 address and
+// hostname its ingress is made of. The API omits both while the NodeBalancer
+// is being provisioned.
+func isNodeBalancerReady(nb *linodego.NodeBalancer) bool {
+	return nb.IPv4 != nil && *nb.IPv4 != "" && nb.Hostname != nil && *nb.Hostname != ""
+}
+
+// nodeBalancerIPRetryAfter returns how long to wait before checking again
+// whether nb has been assigned an IP. The wait grows with the age of the
+// NodeBalancer, up to 16 times the configured interval.
+func nodeBalancerIPRetryAfter(nb *linodego.NodeBalancer, now time.Time) time.Duration {
+	interval := Options.NodeBalancerIPRequeueInterval
+	if interval <= 0 {
+		interval = defaultNodeBalancerIPRequeueInterval
+	}
+	retryAfter := interval
+	if nb.Created != nil {
+		retryAfter = max(retryAfter, now.Sub(*nb.Created))
+	}
+	return min(retryAfter, 16*interval)
+}
+
+// ccmTagPrefixes are the prefixes of the tags set by the CCM which are
+// replaced when their value changes.
+var ccmTagPrefixes = []string{auditTagResourceVersionPrefix, auditTagAppliedAtPrefix, ownerTagPrefix, configPortTagPrefix}
+
+// getForeignTags returns the tags of current which are not among managed and
+// were not set by the CCM.
+func getForeignTags(current, managed []string) []string {
+	var foreign []string
+	for _, tag := range current {
+		if slices.Contains(managed, tag) || slices.ContainsFunc(ccmTagPrefixes, func(prefix string) bool {
+			return strings.HasPrefix(tag, prefix)
+		}) {
+			continue
+		}
+		foreign = append(foreign, tag)
+	}
+	return foreign
+}
+
+// getConfigPortTags returns the tags marking the NodeBalancer configs for
+// ports as managed by the CCM.
+func getConfigPortTags(ports []int) []string {
+	tags := make([]string, 0, len(ports))
+	for _, port := range ports {
+		tags = append(tags, configPortTagPrefix+strconv.Itoa(port))
+	}
+	return tags
+}
+
+// getOwnedConfigPorts returns the ports of the NodeBalancer configs marked as
+// managed by the CCM in tags, or nil if none are marked.
+func getOwnedConfigPorts(tags []string) map[int]bool {
+	var ports map[int]bool
+	for _, tag := range tags {
+		raw, ok := strings.CutPrefix(tag, configPortTagPrefix)
+		if !ok {
+			continue
+		}
+		port, err := strconv.Atoi(raw)
+		if err != nil {
+			continue
+		}
+		if ports == nil {
+			ports = make(map[int]bool)
+		}
+		ports[port] = true
+	}
+	return ports
+}
+
+// isAdoptedNodeBalancer reports whether nb was not created for service but
+// selected for it by the nodebalancer-id annotation or spec.loadBalancerIP.
+// NodeBalancers created before the owner tag was introduced lack it as well,
+// they are only adopted when selected that way.
+func isAdoptedNodeBalancer(service *v1.Service, nb *linodego.NodeBalancer) bool {
+	if slices.Contains(nb.Tags, getOwnerTag(service)) {
+		return false
+	}
+	if id, err := strconv.Atoi(service.GetAnnotations()[annotations.AnnLinodeNodeBalancerID]); err == nil && id == nb.ID {
+		return true
+	}
+	ip := service.Spec.LoadBalancerIP
+	return ip != "" && nb.IPv4 != nil && *nb.IPv4 == ip
+}
+
+// getOwnerTag returns the tag identifying the NodeBalancer created for service.
+func getOwnerTag(service *v1.Service) string {
+	return ownerTagPrefix + string(service.UID)
+}
+
+// getNodeBalancerOwner returns the UID of the Service nb was created for, from
+// its owner tag.
+func getNodeBalancerOwner(nb *linodego.NodeBalancer) (string, bool) {
+	for _, tag := range nb.Tags {
+		if uid, ok := strings.CutPrefix(tag, ownerTagPrefix); ok {
+			return uid, true
+		}
+	}
+	return "", false
+}
+
+// serviceExists reports whether the Service with uid exists.
+func (l *loadbalancers) serviceExists(ctx context.Context, uid string) (bool, error) {
+	var services []*v1.Service
+	if l.serviceLister != nil {
+		var err error
+		if services, err = l.serviceLister.List(labels.Everything()); err != nil {
+			return false, err
+		}
+	} else {
+		if err := l.retrieveKubeClient(); err != nil {
+			return false, err
+		}
+		list, err := l.kubeClient.CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for i := range list.Items {
+			services = append(services, &list.Items[i])
+		}
+	}
+	return slices.ContainsFunc(services, func(service *v1.Service) bool { return string(service.UID) == uid }), nil
+}
+
+// getNodeBalancerByOwnerTag looks up a NodeBalancer created for service whose
+// provisioning request timed out before its status could be recorded.
+func (l *loadbalancers) getNodeBalancerByOwnerTag(ctx context.Context, service *v1.Service) (*linodego.NodeBalancer, error) {
+	filter := fmt.Sprintf(`{"tags": "%s"}`, getOwnerTag(service))
+	lbs, err := l.client.ListNodeBalancers(ctx, &linodego.ListOptions{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	if len(lbs) == 0 {
+		return nil, lbNotFoundError{serviceNn: getServiceNn(service)}
+	}
+	klog.V(2).Infof("found NodeBalancer (%d) for service (%s) via owner tag", lbs[0].ID, getServiceNn(service))
+	return &lbs[0], nil
+}
+
+//nolint:funlen
+func (l *loadbalancers) buildNodeBalancerConfig(ctx context.Context, service *v1.Service, port, backends int) (linodego.NodeBalancerConfig, error) {
+	portConfig, err := getPortConfig(service, port)
+	var annErr invalidAnnotationError
+	switch {
+	case errors.Is(err, errMultipleTLSSecrets):
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonUnsupportedSetting, "%s", err)
+	case errors.As(err, &annErr):
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidAnnotation, "%s", err)
+	}
+	if err != nil {
+		return linodego.NodeBalancerConfig{}, err
+	}
+
+	config := linodego.NodeBalancerConfig{
+		Port:          port,
 		Protocol:      portConfig.Protocol,
 		ProxyProtocol: portConfig.ProxyProtocol,
-		Check:         health,
+	}
+	if err = setHealthCheck(service, &config, backends); err != nil {
+		return config, err
+	}
+	if err = setBalancing(service, &config); err != nil {
+		return config, err
 	}
 
-	if health == linodego.CheckHTTP || health == linodego.CheckHTTPBody {
-		path := service.GetAnnotations()[annotations.AnnLinodeCheckPath]
-		if path == "" {
-			path = "/"
+	if portConfig.Protocol == linodego.ProtocolHTTPS {
+		if config.CipherSuite, err = getCipherSuite(service); err != nil {
+			return config, err
 		}
-		config.CheckPath = path
+		if err = l.addTLSCert(ctx, service, &config, portConfig); err != nil {
+			return config, err
+		}
+	}
+
+	return config, nil
+}
+
+// setHealthCheck sets the health check settings of config, a config with
+// backends backends, from the annotations of service.
+func setHealthCheck(service *v1.Service, config *linodego.NodeBalancerConfig, backends int) error {
+	health, err := getHealthCheckType(service)
+	if err != nil {
+		return err
+	}
+	// with the Local policy, nodes without a ready endpoint drop the traffic
+	// to the NodePort, so they must be taken out by health checks. UDP configs
+	// are checked on a separate port rather than the NodePort, so enabling
+	// them would take out every node.
+	if health == linodego.CheckNone && isLocalTrafficPolicy(service) && config.Protocol != protocolUDP {
+		klog.V(2).Infof("enabling connection health checks for service (%s) with the Local external traffic policy", getServiceNn(service))
+		health = linodego.CheckConnection
+	}
+	config.Check = health
+
+	if health == linodego.CheckHTTP || health == linodego.CheckHTTPBody {
+		config.CheckPath = getCheckPath(service, config.Protocol)
 	}
 
 	if health == linodego.CheckHTTPBody {
-		body := service.GetAnnotations()[annotations.AnnLinodeCheckBody]
+		body, _ := getAnnotationString(service, annotations.AnnLinodeCheckBody)
 		if body == "" {
-			return config, fmt.Errorf("for health check type http_body need body regex annotation %v", annotations.AnnLinodeCheckBody)
+			return fmt.Errorf("for health check type http_body need body regex annotation %v", annotations.AnnLinodeCheckBody)
 		}
 		config.CheckBody = body
 	}
-	checkInterval := 5
-	if ci, ok := service.GetAnnotations()[annotations.AnnLinodeHealthCheckInterval]; ok {
-		if checkInterval, err = strconv.Atoi(ci); err != nil {
-			return config, err
-		}
+	checkInterval, err := getHealthCheckInt(service, annotations.AnnLinodeHealthCheckInterval, 5, minCheckInterval, maxCheckInterval)
+	if err != nil {
+		return err
 	}
 	config.CheckInterval = checkInterval
 
-	checkTimeout := 3
-	if ct, ok := service.GetAnnotations()[annotations.AnnLinodeHealthCheckTimeout]; ok {
-		if checkTimeout, err = strconv.Atoi(ct); err != nil {
-			return config, err
-		}
+	checkTimeout, err := getHealthCheckInt(service, annotations.AnnLinodeHealthCheckTimeout, 3, minCheckTimeout, maxCheckTimeout)
+	if err != nil {
+		return err
 	}
 	config.CheckTimeout = checkTimeout
 
-	checkAttempts := 2
-	if ca, ok := service.GetAnnotations()[annotations.AnnLinodeHealthCheckAttempts]; ok {
-		if checkAttempts, err = strconv.Atoi(ca); err != nil {
-			return config, err
+	// the API rejects checks which would still be running when the next one starts
+	if checkInterval <= checkTimeout {
+		return invalidAnnotationError{
+			name:   annotations.AnnLinodeHealthCheckInterval,
+			value:  strconv.Itoa(checkInterval),
+			reason: fmt.Sprintf("must be greater than the check timeout (%d)", checkTimeout),
 		}
 	}
+
+	scaleInterval, err := getServiceBoolAnnotation(service, annotations.AnnLinodeHealthCheckIntervalScaling)
+	if err != nil {
+		return err
+	}
+	if scaleInterval {
+		config.CheckInterval = scaleCheckInterval(checkInterval, checkTimeout, backends)
+	}
+
+	checkAttempts, err := getHealthCheckInt(service, annotations.AnnLinodeHealthCheckAttempts, 2, minCheckAttempts, maxCheckAttempts)
+	if err != nil {
+		return err
+	}
 	config.CheckAttempts = checkAttempts
 
-	checkPassive := true
-	if cp, ok := service.GetAnnotations()[annotations.AnnLinodeHealthCheckPassive]; ok {
-		if checkPassive, err = strconv.ParseBool(cp); err != nil {
-			return config, err
-		}
+	checkPassive, ok, err := getAnnotationBool(service, annotations.AnnLinodeHealthCheckPassive)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		checkPassive = true
 	}
 	config.CheckPassive = checkPassive
 
-	if portConfig.Protocol == linodego.ProtocolHTTPS {
-		if err = l.addTLSCert(ctx, service, &config, portConfig); err != nil {
-			return config, err
+	return nil
+}
+
+// getHealthCheckInt returns the integer health check annotation name of
+// service, or def when it is not set. Values between minValue and maxValue
+// are accepted, the range the API accepts for the setting.
+func getHealthCheckInt(service *v1.Service, name string, def, minValue, maxValue int) (int, error) {
+	value, ok, err := getAnnotationInt(service, name)
+	if err != nil || !ok {
+		return def, err
+	}
+	if value < minValue || value > maxValue {
+		return 0, invalidAnnotationError{
+			name:   name,
+			value:  strconv.Itoa(value),
+			reason: fmt.Sprintf("must be between %d and %d", minValue, maxValue),
 		}
 	}
+	return value, nil
+}
 
-	return config, nil
+// healthCheckUpToDate reports whether the health check settings of current
+// match those of wanted. The check path and body are only compared for the
+// check types using them.
+func healthCheckUpToDate(current, wanted linodego.NodeBalancerConfig) bool {
+	if current.Check != wanted.Check ||
+		current.CheckInterval != wanted.CheckInterval ||
+		current.CheckTimeout != wanted.CheckTimeout ||
+		current.CheckAttempts != wanted.CheckAttempts ||
+		current.CheckPassive != wanted.CheckPassive {
+		return false
+	}
+	if (wanted.Check == linodego.CheckHTTP || wanted.Check == linodego.CheckHTTPBody) && current.CheckPath != wanted.CheckPath {
+		return false
+	}
+	return wanted.Check != linodego.CheckHTTPBody || current.CheckBody == wanted.CheckBody
+}
+
+// healthChecksDrifted reports whether the health check settings of any of the
+// configs for the ports of service differ from those of its annotations, e.g.
+// because they were changed out of band, or the config is missing.
+func healthChecksDrifted(service *v1.Service, nbCfgs []linodego.NodeBalancerConfig, backends int) (bool, error) {
+	for _, port := range service.Spec.Ports {
+		var current *linodego.NodeBalancerConfig
+		for i := range nbCfgs {
+			if nbCfgs[i].Port == int(port.Port) {
+				current = &nbCfgs[i]
+				break
+			}
+		}
+		if current == nil {
+			return true, nil
+		}
+
+		portConfig, err := getPortConfig(service, int(port.Port))
+		if err != nil {
+			return false, err
+		}
+		wanted := linodego.NodeBalancerConfig{Port: int(port.Port), Protocol: portConfig.Protocol}
+		if err = setHealthCheck(service, &wanted, backends); err != nil {
+			return false, err
+		}
+		if !healthCheckUpToDate(*current, wanted) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// scaleCheckInterval returns interval multiplied by the number of batches of
+// checkIntervalScalingBackends backends, so that the backends are checked at
+// most as often in total as a single batch would be. The result is kept within
+// the NodeBalancer bounds, and above timeout.
+func scaleCheckInterval(interval, timeout, backends int) int {
+	batches := (backends + checkIntervalScalingBackends - 1) / checkIntervalScalingBackends
+	scaled := interval * max(batches, 1)
+	return min(max(scaled, minCheckInterval, timeout+1), maxCheckInterval)
 }
 
 func (l *loadbalancers) addTLSCert(ctx context.Context, service *v1.Service, nbConfig *linodego.NodeBalancerConfig, config portConfig) error {
@@ -730,39 +2138,308 @@ func (l *loadbalancers) addTLSCert(ctx context.Context, service *v1.Service, nbC
 	}
 
 	nbConfig.SSLCert, nbConfig.SSLKey, err = getTLSCertInfo(ctx, l.kubeClient, service.Namespace, config)
+	if errors.Is(err, errCrossNamespaceSecret) {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonCrossNamespaceSecret,
+			"TLS secret %s for port %d is not in the namespace of the service", config.TLSSecretName, config.Port)
+		return err
+	}
+	if k8serrors.IsNotFound(err) && Options.TLSSecretMissingPolicy != tlsSecretMissingFail {
+		// leave the certificate unset so the one already on the NodeBalancer is kept
+		klog.Warningf("TLS secret %s for port %d of service (%s) not found, keeping last known good certificate", config.TLSSecretName, config.Port, getServiceNn(service))
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonTLSSecretMissing,
+			"TLS secret %s for port %d not found, keeping the last known good certificate", config.TLSSecretName, config.Port)
+		return nil
+	}
+	if k8serrors.IsNotFound(err) {
+		return fmt.Errorf("[port %d] %w: %s: %w", config.Port, errTLSSecretNotFound, config.TLSSecretName, err)
+	}
+	if k8serrors.IsForbidden(err) {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonTLSSecretUnreadable,
+			"TLS secret %s for port %d cannot be read by the CCM: %s", config.TLSSecretName, config.Port, err)
+		return fmt.Errorf("[port %d] %w: %s: %w", config.Port, errTLSSecretUnreadable, config.TLSSecretName, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the API accepts any certificate and key, which would leave the config
+	// unable to serve TLS
+	if nbConfig.SSLCert, err = buildCertChain(nbConfig.SSLCert, nbConfig.SSLKey); err != nil {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidTLSKeyPair,
+			"TLS secret %s for port %d does not hold a matching certificate and private key: %s", config.TLSSecretName, config.Port, err)
+		return fmt.Errorf("%w: TLS secret %s for port %d: %w", errInvalidTLSKeyPair, config.TLSSecretName, config.Port, err)
+	}
+	if err = verifyCertHostnames(nbConfig.SSLCert, config.TLSHostnames); err != nil {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonTLSHostnameMismatch,
+			"TLS secret %s for port %d: %s", config.TLSSecretName, config.Port, err)
+		return fmt.Errorf("TLS secret %s for port %d: %w", config.TLSSecretName, config.Port, err)
+	}
+
+	l.reportCertExpiry(service, config.Port, nbConfig.SSLCert)
+	return nil
+}
+
+// buildCertChain returns the certificates of the bundle certPEM with the leaf
+// matching keyPEM first, followed by the intermediates in their original order,
+// so clients are served the complete chain whichever order the secret holds.
+func buildCertChain(certPEM, keyPEM string) (string, error) {
+	var leaf []byte
+	var intermediates [][]byte
+	for rest := []byte(certPEM); ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		encoded := pem.EncodeToMemory(block)
+		if _, err := tls.X509KeyPair(encoded, []byte(keyPEM)); leaf == nil && err == nil {
+			leaf = encoded
+			continue
+		}
+		intermediates = append(intermediates, encoded)
+	}
+
+	if leaf == nil {
+		// report why the first certificate does not pair with the key
+		if _, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM)); err != nil {
+			return "", err
+		}
+		return "", errors.New("no certificate matches the private key")
+	}
+
+	chain := bytes.NewBuffer(leaf)
+	for _, cert := range intermediates {
+		chain.Write(cert)
+	}
+	return strings.TrimSpace(chain.String()), nil
+}
+
+// verifyCertHostnames returns an error if the leaf certificate of the chain
+// certPEM is not valid for all of hostnames.
+func verifyCertHostnames(certPEM string, hostnames []string) error {
+	if len(hostnames) == 0 {
+		return nil
+	}
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return errors.New("no PEM encoded certificate found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
 	if err != nil {
 		return err
 	}
+	for _, hostname := range hostnames {
+		if err := cert.VerifyHostname(hostname); err != nil {
+			return fmt.Errorf("%w %s: %w", errTLSHostnameMismatch, hostname, err)
+		}
+	}
+	return nil
+}
+
+// checkLastGoodCert returns an error if newCfg is missing its TLS certificate
+// and current has no certificate which could be kept in its place.
+func checkLastGoodCert(newCfg linodego.NodeBalancerConfig, current *linodego.NodeBalancerConfig) error {
+	if newCfg.Protocol != linodego.ProtocolHTTPS || newCfg.SSLCert != "" {
+		return nil
+	}
+	if current == nil || current.Protocol != linodego.ProtocolHTTPS {
+		return fmt.Errorf("[port %d] %w and there is no last known good certificate", newCfg.Port, errTLSSecretNotFound)
+	}
 	return nil
 }
 
+// reportCertExpiry exposes the time left before the certificate served on port
+// expires, and emits a Warning event when it is within the configured window.
+func (l *loadbalancers) reportCertExpiry(service *v1.Service, port int, certPEM string) {
+	notAfter, err := getCertExpiry(certPEM)
+	if err != nil {
+		klog.Warningf("unable to determine TLS certificate expiry for port %d of service (%s): %s", port, getServiceNn(service), err)
+		return
+	}
+
+	remaining := time.Until(notAfter)
+	certExpirySeconds.WithLabelValues(getServiceNn(service), strconv.Itoa(port)).Set(remaining.Seconds())
+
+	if remaining < Options.CertExpiryWarningWindow {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonCertificateExpiring,
+			"TLS certificate for port %d expires at %s", port, notAfter.Format(time.RFC3339))
+	}
+}
+
+// reportCertExpiries re-evaluates the expiry of the certificates served on the
+// https ports of service from their TLS secrets. Configs are not built when
+// nothing changed since the last update, and their expiry would otherwise only
+// be reported again once the Service or its secrets change.
+func (l *loadbalancers) reportCertExpiries(ctx context.Context, service *v1.Service) {
+	if err := l.retrieveKubeClient(); err != nil {
+		klog.Warningf("unable to determine TLS certificate expiry for service (%s): %s", getServiceNn(service), err)
+		return
+	}
+	for _, port := range service.Spec.Ports {
+		config, err := getPortConfig(service, int(port.Port))
+		if err != nil || config.Protocol != linodego.ProtocolHTTPS {
+			continue
+		}
+		cert, key, err := getTLSCertInfo(ctx, l.kubeClient, service.Namespace, config)
+		if err == nil {
+			cert, err = buildCertChain(cert, key)
+		}
+		if err != nil {
+			// the error is reported by the next update building the config
+			klog.V(3).Infof("unable to determine TLS certificate expiry for port %d of service (%s): %s", config.Port, getServiceNn(service), err)
+			continue
+		}
+		l.reportCertExpiry(service, config.Port, cert)
+	}
+}
+
+// reportConfigCount exposes the number of configs of the NodeBalancer of
+// service, which is capped per NodeBalancer.
+func reportConfigCount(service *v1.Service, nodeBalancerID, count int) {
+	serviceNn := getServiceNn(service)
+	// the NodeBalancer of a Service may be replaced, e.g. when recreated
+	nodeBalancerConfigCount.DeletePartialMatch(map[string]string{"service": serviceNn})
+	nodeBalancerConfigCount.WithLabelValues(serviceNn, strconv.Itoa(nodeBalancerID)).Set(float64(count))
+}
+
+// reportTransferUsage exposes the network transfer nb used this month, when the
+// API reports it, and emits a Warning event when it is above the
+// Options.TransferWarningThreshold of Options.NodeBalancerTransferQuota.
+func (l *loadbalancers) reportTransferUsage(service *v1.Service, nb *linodego.NodeBalancer) {
+	if nb.Transfer.Total == nil {
+		return
+	}
+
+	used := *nb.Transfer.Total
+	nodeBalancerTransferUsed.WithLabelValues(getServiceNn(service)).Set(used)
+
+	if Options.NodeBalancerTransferQuota > 0 && used >= Options.TransferWarningThreshold*float64(Options.NodeBalancerTransferQuota) {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonTransferQuota,
+			"NodeBalancer (%d) used %.0f MB of its %d MB monthly transfer quota", nb.ID, used, Options.NodeBalancerTransferQuota)
+	}
+}
+
+// logTLSConfig logs the TLS settings the API reports for an https config,
+// to help debug TLS issues. The certificate and key are never logged.
+func logTLSConfig(service *v1.Service, cfg *linodego.NodeBalancerConfig) {
+	if cfg == nil || cfg.Protocol != linodego.ProtocolHTTPS {
+		return
+	}
+	klog.V(4).Infof("NodeBalancer (%d) config (%d) on port %d for service (%s) uses cipher suite %s, certificate common name %q and fingerprint %s",
+		cfg.NodeBalancerID, cfg.ID, cfg.Port, getServiceNn(service), cfg.CipherSuite, cfg.SSLCommonName, cfg.SSLFingerprint)
+}
+
+// logCreatedTLSConfigs logs the TLS settings of the https configs of a newly
+// created NodeBalancer, which are only known by listing its configs.
+func (l *loadbalancers) logCreatedTLSConfigs(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer, configs []*linodego.NodeBalancerConfigCreateOptions) {
+	if !klog.V(4).Enabled() || !slices.ContainsFunc(configs, func(cfg *linodego.NodeBalancerConfigCreateOptions) bool {
+		return cfg.Protocol == linodego.ProtocolHTTPS
+	}) {
+		return
+	}
+
+	nbCfgs, err := l.client.ListNodeBalancerConfigs(ctx, nb.ID, nil)
+	if err != nil {
+		klog.V(4).Infof("unable to list configs of NodeBalancer (%d) for service (%s) to log their TLS settings: %s", nb.ID, getServiceNn(service), err)
+		return
+	}
+	for i := range nbCfgs {
+		logTLSConfig(service, &nbCfgs[i])
+	}
+}
+
+// getCertExpiry returns the expiry of the first certificate in certPEM.
+func getCertExpiry(certPEM string) (time.Time, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, errors.New("no PEM encoded certificate found")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
 // buildLoadBalancerRequest returns a linodego.NodeBalancer
 // requests for service across nodes.
 func (l *loadbalancers) buildLoadBalancerRequest(ctx context.Context, clusterName string, service *v1.Service, nodes []*v1.Node) (*linodego.NodeBalancer, error) {
+	nodes = eligibleBackendNodes(service, nodes)
 	if len(nodes) == 0 {
-		return nil, fmt.Errorf("%w: cluster %s, service %s", errNoNodesAvailable, clusterName, getServiceNn(service))
+		if Options.NoBackendNodesPolicy == noBackendNodesDefer {
+			l.recordEvent(service, v1.EventTypeWarning, eventReasonNoBackendNodes,
+				"no nodes are available as backends, deferring NodeBalancer creation until there is at least one")
+			return nil, fmt.Errorf("%w: cluster %s, service %s", errNoNodesAvailable, clusterName, getServiceNn(service))
+		}
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonNoBackendNodes,
+			"no nodes are available as backends, creating the NodeBalancer without backends")
 	}
+	backendIPs, err := l.resolveBackendIPs(ctx, service, nodes)
+	if err != nil {
+		return nil, err
+	}
+	drained := drainedBackendNodes(nodes)
 	ports := service.Spec.Ports
 	configs := make([]*linodego.NodeBalancerConfigCreateOptions, 0, len(ports))
+	appliedCfgs := make([]linodego.NodeBalancerConfig, 0, len(ports))
 
 	for _, port := range ports {
-		if port.Protocol == v1.ProtocolUDP {
-			return nil, fmt.Errorf("error creating NodeBalancer Config: ports with the UDP protocol are not supported")
+		if err := l.validatePortProtocol(service, port); err != nil {
+			return nil, fmt.Errorf("error creating NodeBalancer Config: %w", err)
 		}
 
-		config, err := l.buildNodeBalancerConfig(ctx, service, int(port.Port))
+		config, err := l.buildNodeBalancerConfig(ctx, service, int(port.Port), len(nodes))
 		if err != nil {
 			return nil, err
 		}
-		createOpt := config.GetCreateOptions()
+		if err = checkLastGoodCert(config, nil); err != nil {
+			return nil, err
+		}
 
-		for _, n := range nodes {
-			createOpt.Nodes = append(createOpt.Nodes, l.buildNodeBalancerNodeConfigRebuildOptions(n, port.NodePort).NodeBalancerNodeCreateOptions)
+		backendPort, err := getBackendPort(service, port)
+		if err != nil {
+			return nil, err
+		}
+		nodeOpts, err := l.buildNodeBalancerNodes(service, backendIPs, drained, backendPort)
+		if err != nil {
+			return nil, err
+		}
+		nodeLabels := make([]*linodego.NodeBalancerNodeCreateOptions, 0, len(nodeOpts))
+		for i := range nodeOpts {
+			nodeLabels = append(nodeLabels, &nodeOpts[i].NodeBalancerNodeCreateOptions)
 		}
+		uniqueBackendLabels(nodeLabels)
+		createOpt := newNodeBalancerConfigOptions(config, nodeOpts).create
 
 		configs = append(configs, &createOpt)
+		appliedCfgs = append(appliedCfgs, config)
+	}
+
+	placement := l.selectNodeBalancerRegion(service, nodes)
+	l.warnRegionMismatch(service, placement)
+
+	nb, err := l.createNodeBalancer(ctx, clusterName, service, placement.region, configs)
+	if err != nil {
+		return nil, err
+	}
+	l.logCreatedTLSConfigs(ctx, service, nb, configs)
+
+	var addresses []string
+	for _, config := range configs {
+		for _, node := range config.Nodes {
+			addresses = append(addresses, node.Address)
+		}
+	}
+	l.backends.observe(nb.ID, addresses, time.Now())
+	reportConfigCount(service, nb.ID, len(configs))
+	l.reportEffectiveConfig(ctx, service, nb, appliedCfgs)
+	if fingerprint, err := reconcileFingerprint(service, backendIPs, drained, nb.ID); err == nil {
+		l.reconciled.record(service, fingerprint)
 	}
-	return l.createNodeBalancer(ctx, clusterName, service, configs)
+	return nb, nil
 }
 
 func coerceString(s string, minLen, maxLen int, padding string) string {
@@ -774,23 +2451,190 @@ func coerceString(s string, minLen, maxLen int, padding string) string {
 	} else if len(s) < minLen {
 		return coerceString(fmt.Sprintf("%s%s", padding, s), minLen, maxLen, padding)
 	}
-	return s
+	return s
+}
+
+// eligibleBackendNodes returns the nodes which match the backend node selector
+// of service, and have the conditions in Options.RequiredNodeConditions and
+// none of the taints in Options.ExcludedNodeTaints, which may be used as
+// backends.
+func eligibleBackendNodes(service *v1.Service, nodes []*v1.Node) []*v1.Node {
+	// invalid selectors are rejected by validateServiceAnnotations before the
+	// NodeBalancer is reconciled
+	selector, _ := getBackendNodeSelector(service)
+	if selector.Empty() && len(Options.RequiredNodeConditions) == 0 && len(Options.ExcludedNodeTaints) == 0 {
+		return nodes
+	}
+
+	eligible := make([]*v1.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if !selector.Matches(labels.Set(node.Labels)) {
+			klog.V(3).Infof("not using node %s as a backend of service (%s): it does not match the backend node selector %q", node.Name, getServiceNn(service), selector)
+			continue
+		}
+		if reason := nodeIneligibleReason(node); reason != "" {
+			klog.V(3).Infof("not using node %s as a backend of service (%s): %s", node.Name, getServiceNn(service), reason)
+			continue
+		}
+		eligible = append(eligible, node)
+	}
+	return eligible
+}
+
+// getBackendNodeSelector returns the label selector the backend nodes of
+// service must match: its backend-node-selector annotation, else
+// Options.BackendNodeSelector.
+func getBackendNodeSelector(service *v1.Service) (labels.Selector, error) {
+	raw, ok := getAnnotationString(service, annotations.AnnLinodeBackendNodeSelector)
+	if !ok {
+		// validated in newCloud
+		selector, _ := labels.Parse(Options.BackendNodeSelector)
+		return selector, nil
+	}
+	selector, err := labels.Parse(raw)
+	if err != nil {
+		return labels.Everything(), invalidAnnotationError{name: annotations.AnnLinodeBackendNodeSelector, value: raw, reason: err.Error()}
+	}
+	return selector, nil
+}
+
+// drainedBackendNodes returns the names of the unschedulable nodes of nodes
+// whose backends are put in drain mode under Options.UnschedulableNodesPolicy.
+func drainedBackendNodes(nodes []*v1.Node) map[string]bool {
+	if Options.UnschedulableNodesPolicy != unschedulableNodesDrain {
+		return nil
+	}
+
+	drained := make(map[string]bool)
+	for _, node := range nodes {
+		if node.Spec.Unschedulable {
+			drained[node.Name] = true
+		}
+	}
+	return drained
+}
+
+// nodeIneligibleReason returns why node may not be used as a backend, or an
+// empty string if it may.
+func nodeIneligibleReason(node *v1.Node) string {
+	for conditionType, status := range Options.RequiredNodeConditions {
+		current := v1.ConditionUnknown
+		for _, condition := range node.Status.Conditions {
+			if string(condition.Type) == conditionType {
+				current = condition.Status
+				break
+			}
+		}
+		if string(current) != status {
+			return fmt.Sprintf("condition %s is %s, %s is required", conditionType, current, status)
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if slices.Contains(Options.ExcludedNodeTaints, taint.Key) {
+			return fmt.Sprintf("tainted with %s", taint.Key)
+		}
+	}
+	return ""
+}
+
+// resolveBackendIPs returns the backend address of each of nodes by node name.
+// With the instance backend IP source this takes an API call per node, so it is
+// done once per reconcile and the result is passed to everything building
+// backends.
+func (l *loadbalancers) resolveBackendIPs(ctx context.Context, service *v1.Service, nodes []*v1.Node) (map[string]string, error) {
+	backendIPs := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		address, err := l.resolveBackendIP(ctx, service, node)
+		if err != nil {
+			return nil, err
+		}
+		backendIPs[node.Name] = address
+	}
+	return backendIPs, nil
+}
+
+// resolveBackendIP returns the backend address of node. Backends must be
+// private IPv4 addresses, dual-stack Services are only given the IPv6 address
+// of the NodeBalancer as ingress.
+func (l *loadbalancers) resolveBackendIP(ctx context.Context, service *v1.Service, node *v1.Node) (string, error) {
+	if Options.BackendIPSource == backendIPSourceInstance {
+		return l.getInstanceBackendIP(ctx, service, node)
+	}
+	return getNodeBackendIP(service, node)
+}
+
+// buildNodeBalancerNodes returns the backends of a NodeBalancer config for the
+// nodes of backendIPs, in drain mode for the drained ones. Nodes are visited in name order, so when several nodes
+// resolve to the same backend address, the first by name keeps it and the
+// others are skipped (or fail the reconcile, per
+// Options.DuplicateBackendAddressPolicy).
+func (l *loadbalancers) buildNodeBalancerNodes(service *v1.Service, backendIPs map[string]string, drained map[string]bool, nodePort int32) ([]linodego.NodeBalancerConfigRebuildNodeOptions, error) {
+	nodeNames := make([]string, 0, len(backendIPs))
+	for nodeName := range backendIPs {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	slices.Sort(nodeNames)
+
+	owners := make(map[string]string, len(nodeNames))
+	backends := make([]linodego.NodeBalancerConfigRebuildNodeOptions, 0, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		opts := l.buildNodeBalancerNodeConfigRebuildOptions(nodeName, backendIPs[nodeName], nodePort)
+		if drained[nodeName] {
+			opts.Mode = linodego.ModeDrain
+		}
+		if owner, ok := owners[opts.Address]; ok {
+			if Options.DuplicateBackendAddressPolicy == duplicateBackendAddressFail {
+				return nil, fmt.Errorf("%w: %s of nodes %s and %s", errDuplicateBackendAddress, opts.Address, owner, nodeName)
+			}
+			klog.Warningf("backend address %s of node %s for service (%s) is already used by node %s, skipping it",
+				opts.Address, nodeName, getServiceNn(service), owner)
+			continue
+		}
+		owners[opts.Address] = nodeName
+		backends = append(backends, opts)
+	}
+	return backends, nil
 }
 
-func (l *loadbalancers) buildNodeBalancerNodeConfigRebuildOptions(node *v1.Node, nodePort int32) linodego.NodeBalancerConfigRebuildNodeOptions {
+func (l *loadbalancers) buildNodeBalancerNodeConfigRebuildOptions(label, address string, nodePort int32) linodego.NodeBalancerConfigRebuildNodeOptions {
 	return linodego.NodeBalancerConfigRebuildNodeOptions{
 		NodeBalancerNodeCreateOptions: linodego.NodeBalancerNodeCreateOptions{
-			Address: fmt.Sprintf("%v:%v", getNodePrivateIP(node), nodePort),
+			Address: net.JoinHostPort(address, strconv.Itoa(int(nodePort))),
 			// NodeBalancer backends must be 3-32 chars in length
 			// If < 3 chars, pad node name with "node-" prefix
-			Label:  coerceString(node.Name, 3, 32, "node-"),
+			Label:  coerceString(label, 3, 32, "node-"),
 			Mode:   "accept",
 			Weight: 100,
 		},
 	}
 }
 
+// uniqueBackendLabels suffixes the labels shared by several backends of a
+// config, e.g. node names which collide once truncated, with a hash of their
+// address so that every backend has a distinct label.
+func uniqueBackendLabels(nodes []*linodego.NodeBalancerNodeCreateOptions) {
+	count := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		count[node.Label]++
+	}
+	for _, node := range nodes {
+		if count[node.Label] < 2 {
+			continue
+		}
+		sum := sha256.Sum256([]byte(node.Address))
+		suffix := "-" + hex.EncodeToString(sum[:])[:8]
+		node.Label = coerceString(node.Label, 0, 32-len(suffix), "") + suffix
+	}
+}
+
 func (l *loadbalancers) retrieveKubeClient() error {
+	l.initMu.Lock()
+	defer l.initMu.Unlock()
+	return l.initKubeClient()
+}
+
+// initKubeClient builds kubeClient unless it is set, initMu must be held.
+func (l *loadbalancers) initKubeClient() error {
 	if l.kubeClient != nil {
 		return nil
 	}
@@ -821,59 +2665,510 @@ func (l *loadbalancers) retrieveKubeClient() error {
 	return nil
 }
 
+// updateServiceLoadBalancerStatus applies status to the Service with
+// server-side apply, so that only status.loadBalancer is owned by the CCM and
+// status fields of other managers are left alone. The apply is forced, as
+// status.loadBalancer is owned by the service controller which set it, and
+// the stale ingress it recorded is the one being replaced.
+func (l *loadbalancers) updateServiceLoadBalancerStatus(ctx context.Context, service *v1.Service, status *v1.LoadBalancerStatus) error {
+	if err := l.retrieveKubeClient(); err != nil {
+		return err
+	}
+
+	apply := corev1apply.Service(service.Name, service.Namespace).
+		WithStatus(corev1apply.ServiceStatus().WithLoadBalancer(loadBalancerStatusApplyConfiguration(status)))
+	_, err := l.kubeClient.CoreV1().Services(service.Namespace).ApplyStatus(ctx, apply, metav1.ApplyOptions{
+		FieldManager: serviceStatusFieldManager,
+		Force:        true,
+	})
+	return err
+}
+
+// annotateNodeBalancerID sets the ID of nb as the
+// AnnLinodeNodeBalancerStatusID annotation of service, unless it is already
+// set to it.
+func (l *loadbalancers) annotateNodeBalancerID(ctx context.Context, service *v1.Service, nb *linodego.NodeBalancer) error {
+	id := strconv.Itoa(nb.ID)
+	if service.Annotations[annotations.AnnLinodeNodeBalancerStatusID] == id {
+		return nil
+	}
+	if err := l.retrieveKubeClient(); err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{annotations.AnnLinodeNodeBalancerStatusID: id},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = l.kubeClient.CoreV1().Services(service.Namespace).Patch(ctx, service.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// loadBalancerStatusApplyConfiguration converts status for server-side apply.
+func loadBalancerStatusApplyConfiguration(status *v1.LoadBalancerStatus) *corev1apply.LoadBalancerStatusApplyConfiguration {
+	lbStatus := corev1apply.LoadBalancerStatus()
+	for _, ingress := range status.Ingress {
+		ing := corev1apply.LoadBalancerIngress()
+		if ingress.IP != "" {
+			ing.WithIP(ingress.IP)
+		}
+		if ingress.Hostname != "" {
+			ing.WithHostname(ingress.Hostname)
+		}
+		if ingress.IPMode != nil {
+			ing.WithIPMode(*ingress.IPMode)
+		}
+		for _, port := range ingress.Ports {
+			portStatus := corev1apply.PortStatus().WithPort(port.Port).WithProtocol(port.Protocol)
+			if port.Error != nil {
+				portStatus.WithError(*port.Error)
+			}
+			ing.WithPorts(portStatus)
+		}
+		lbStatus.WithIngress(ing)
+	}
+	return lbStatus
+}
+
+// recordEvent emits an Event for service. Failing to set up the event recorder
+// is logged but does not fail reconciliation.
+func (l *loadbalancers) recordEvent(service *v1.Service, eventType, reason, messageFmt string, args ...interface{}) {
+	l.initMu.Lock()
+	if l.eventRecorder == nil {
+		if err := l.initKubeClient(); err != nil {
+			l.initMu.Unlock()
+			klog.Warningf("unable to record %s event for service (%s): %s", reason, getServiceNn(service), err)
+			return
+		}
+		l.eventRecorder = newEventRecorder(l.kubeClient)
+	}
+	recorder := l.eventRecorder
+	l.initMu.Unlock()
+
+	recorder.Eventf(service, eventType, reason, messageFmt, args...)
+}
+
+// newEventRecorder returns a recorder sending the events of the CCM to the API
+// server of kubeClient.
+func newEventRecorder(kubeClient kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: eventSourceComponent})
+}
+
+// ingressAddress returns the first IP or hostname found in ingress.
+func ingressAddress(ingress []v1.LoadBalancerIngress) string {
+	for _, ing := range ingress {
+		if ing.IP != "" {
+			return ing.IP
+		}
+		if ing.Hostname != "" {
+			return ing.Hostname
+		}
+	}
+	return ""
+}
+
+// protocolUDP is the NodeBalancer config protocol balancing UDP datagrams,
+// which linodego does not define yet.
+const protocolUDP linodego.ConfigProtocol = "udp"
+
+var (
+	configProtocols      = []string{string(linodego.ProtocolTCP), string(linodego.ProtocolHTTP), string(linodego.ProtocolHTTPS), string(protocolUDP)}
+	configProxyProtocols = []string{string(linodego.ProxyProtocolNone), string(linodego.ProxyProtocolV1), string(linodego.ProxyProtocolV2)}
+	configChecks         = []string{string(linodego.CheckNone), string(linodego.CheckConnection), string(linodego.CheckHTTP), string(linodego.CheckHTTPBody)}
+)
+
 func getPortConfig(service *v1.Service, port int) (portConfig, error) {
 	portConfig := portConfig{}
 	portConfigAnnotation, err := getPortConfigAnnotation(service, port)
 	if err != nil {
 		return portConfig, err
 	}
-	protocol := portConfigAnnotation.Protocol
-	if protocol == "" {
-		protocol = "tcp"
-		if p, ok := service.GetAnnotations()[annotations.AnnLinodeDefaultProtocol]; ok {
-			protocol = p
+	portConfigKey := annotations.AnnLinodePortConfigPrefix + strconv.Itoa(port)
+
+	protocol := string(linodego.ProtocolTCP)
+	if portConfigAnnotation.Protocol != "" {
+		if protocol, err = parseAnnotationEnum(portConfigKey, portConfigAnnotation.Protocol, configProtocols...); err != nil {
+			return portConfig, err
 		}
+	} else if p, ok, err := getAnnotationEnum(service, annotations.AnnLinodeDefaultProtocol, configProtocols...); err != nil {
+		return portConfig, err
+	} else if ok {
+		protocol = p
 	}
-	protocol = strings.ToLower(protocol)
 
-	proxyProtocol := portConfigAnnotation.ProxyProtocol
-	if proxyProtocol == "" {
-		proxyProtocol = string(linodego.ProxyProtocolNone)
+	proxyProtocol := string(linodego.ProxyProtocolNone)
+	if portConfigAnnotation.ProxyProtocol != "" {
+		if proxyProtocol, err = parseAnnotationEnum(portConfigKey, portConfigAnnotation.ProxyProtocol, configProxyProtocols...); err != nil {
+			return portConfig, err
+		}
+		if linodego.ConfigProtocol(protocol) != linodego.ProtocolTCP && proxyProtocol != string(linodego.ProxyProtocolNone) {
+			return portConfig, invalidAnnotationError{name: portConfigKey, value: portConfigAnnotation.ProxyProtocol, reason: "proxy protocol is not supported for " + protocol}
+		}
+	} else {
 		for _, ann := range []string{annotations.AnnLinodeDefaultProxyProtocol, annLinodeProxyProtocolDeprecated} {
-			if pp, ok := service.GetAnnotations()[ann]; ok {
+			pp, ok, err := getAnnotationEnum(service, ann, configProxyProtocols...)
+			if err != nil {
+				return portConfig, err
+			}
+			// only tcp configs support proxy protocol, the default is only
+			// applied to the tcp ports of Services mixing protocols
+			if ok && linodego.ConfigProtocol(protocol) == linodego.ProtocolTCP {
 				proxyProtocol = pp
 				break
 			}
 		}
 	}
 
-	if protocol != "tcp" && protocol != "http" && protocol != "https" {
-		return portConfig, fmt.Errorf("invalid protocol: %q specified", protocol)
-	}
-
-	switch proxyProtocol {
-	case string(linodego.ProxyProtocolNone), string(linodego.ProxyProtocolV1), string(linodego.ProxyProtocolV2):
-		break
-	default:
-		return portConfig, fmt.Errorf("invalid NodeBalancer proxy protocol value '%s'", proxyProtocol)
+	tlsSecretName := portConfigAnnotation.TLSSecretName
+	var tlsHostnames []string
+	if len(portConfigAnnotation.TLSSecrets) > 0 {
+		if tlsSecretName, err = getSNISecretName(port, portConfigAnnotation); err != nil {
+			return portConfig, err
+		}
+		tlsHostnames = make([]string, 0, len(portConfigAnnotation.TLSSecrets))
+		for hostname := range portConfigAnnotation.TLSSecrets {
+			tlsHostnames = append(tlsHostnames, hostname)
+		}
+		slices.Sort(tlsHostnames)
 	}
 
 	portConfig.Port = port
 	portConfig.Protocol = linodego.ConfigProtocol(protocol)
 	portConfig.ProxyProtocol = linodego.ConfigProxyProtocol(proxyProtocol)
-	portConfig.TLSSecretName = portConfigAnnotation.TLSSecretName
+	portConfig.TLSSecretName = tlsSecretName
+	portConfig.TLSHostnames = tlsHostnames
 
 	return portConfig, nil
 }
 
+// getSNISecretName returns the TLS secret serving the SNI hostnames of the
+// port configuration ann. NodeBalancer configs hold a single certificate, so
+// the hostnames can only be served when they all reference the same secret,
+// the one of tls-secret-name if set too, whose certificate covers them all.
+func getSNISecretName(port int, ann portConfigAnnotation) (string, error) {
+	var secrets []string
+	if ann.TLSSecretName != "" {
+		secrets = append(secrets, ann.TLSSecretName)
+	}
+	for hostname, secret := range ann.TLSSecrets {
+		if hostname == "" || secret == "" {
+			return "", invalidAnnotationError{
+				name:   annotations.AnnLinodePortConfigPrefix + strconv.Itoa(port),
+				value:  fmt.Sprintf("%q: %q", hostname, secret),
+				reason: "tls-secrets must map SNI hostnames to secret names",
+			}
+		}
+		secrets = append(secrets, secret)
+	}
+	slices.Sort(secrets)
+	if secrets = slices.Compact(secrets); len(secrets) > 1 {
+		return "", fmt.Errorf("%w: port %d references TLS secrets %s, use a single secret whose certificate covers all of its SNI hostnames",
+			errMultipleTLSSecrets, port, strings.Join(secrets, ", "))
+	}
+	return secrets[0], nil
+}
+
+// getCipherSuite returns the cipher suite enforcing the minimum TLS version of
+// the Service. The recommended suite only accepts TLS 1.2 and above, while the
+// legacy one also accepts TLS 1.0 and 1.1. It is left unset when no minimum TLS
+// version is specified, which keeps the suite already in use.
+func getCipherSuite(service *v1.Service) (linodego.ConfigCipher, error) {
+	version, ok, err := getAnnotationEnum(service, annotations.AnnLinodeMinTLSVersion, "1.0", "1.1", "1.2")
+	switch {
+	case err != nil:
+		return "", err
+	case !ok:
+		return "", nil
+	case version == "1.2":
+		return linodego.CipherRecommended, nil
+	default:
+		return linodego.CipherLegacy, nil
+	}
+}
+
+var (
+	configAlgorithms = []string{string(linodego.AlgorithmRoundRobin), string(linodego.AlgorithmLeastConn), string(linodego.AlgorithmSource)}
+	configStickiness = []string{string(linodego.StickinessNone), string(linodego.StickinessTable), string(linodego.StickinessHTTPCookie)}
+)
+
+// setBalancing sets the algorithm and stickiness of config from the
+// annotations of service, leaving them unset, and so the values already in
+// use, when not annotated. Settings the protocol of config does not support
+// are not applied to it, so Services mixing protocols can still use them:
+// http_cookie stickiness only applies to http and https configs, and udp
+// configs support neither the source algorithm nor table stickiness.
+func setBalancing(service *v1.Service, config *linodego.NodeBalancerConfig) error {
+	algorithm, ok, err := getAnnotationEnum(service, annotations.AnnLinodeAlgorithm, configAlgorithms...)
+	if err != nil {
+		return err
+	}
+	if ok && (config.Protocol != protocolUDP || linodego.ConfigAlgorithm(algorithm) != linodego.AlgorithmSource) {
+		config.Algorithm = linodego.ConfigAlgorithm(algorithm)
+	}
+
+	stickiness, ok, err := getAnnotationEnum(service, annotations.AnnLinodeStickiness, configStickiness...)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !ok:
+	case linodego.ConfigStickiness(stickiness) == linodego.StickinessHTTPCookie && config.Protocol != linodego.ProtocolHTTP && config.Protocol != linodego.ProtocolHTTPS:
+	case linodego.ConfigStickiness(stickiness) == linodego.StickinessTable && config.Protocol == protocolUDP:
+	default:
+		config.Stickiness = linodego.ConfigStickiness(stickiness)
+	}
+	return nil
+}
+
+// nodeBalancerL4Protocols are the Service port protocols each NodeBalancer
+// config protocol can serve.
+var nodeBalancerL4Protocols = map[linodego.ConfigProtocol][]v1.Protocol{
+	linodego.ProtocolTCP:   {v1.ProtocolTCP},
+	linodego.ProtocolHTTP:  {v1.ProtocolTCP},
+	linodego.ProtocolHTTPS: {v1.ProtocolTCP},
+	protocolUDP:            {v1.ProtocolUDP},
+}
+
+// duplicatePortError is returned for a Service with several ports of the same
+// number, which would map to the same NodeBalancer config.
+type duplicatePortError struct {
+	port  int32
+	names []string
+}
+
+func (e duplicatePortError) Error() string {
+	return fmt.Sprintf("port %d is used by several ports of the service (%s), NodeBalancer configs must have distinct ports", e.port, strings.Join(e.names, ", "))
+}
+
+// validateServicePorts returns a duplicatePortError, and records a Warning
+// event, when several ports of service share a port number, e.g. a TCP and a
+// UDP port 53, rather than building conflicting configs for them. With the
+// reject Options.PrivilegedPortsPolicy, ports below 1024 are refused likewise.
+func (l *loadbalancers) validateServicePorts(service *v1.Service) error {
+	names := make(map[int32][]string, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		name := port.Name
+		if name == "" {
+			name = string(port.Protocol)
+		}
+		names[port.Port] = append(names[port.Port], name)
+	}
+	for _, port := range service.Spec.Ports {
+		if len(names[port.Port]) > 1 {
+			err := duplicatePortError{port: port.Port, names: names[port.Port]}
+			l.recordEvent(service, v1.EventTypeWarning, eventReasonDuplicatePort, "%s", err)
+			return err
+		}
+	}
+	if Options.PrivilegedPortsPolicy == privilegedPortsReject {
+		for _, port := range service.Spec.Ports {
+			if port.Port < privilegedPortLimit {
+				l.recordEvent(service, v1.EventTypeWarning, eventReasonPrivilegedPort,
+					"port %d is below %d, privileged ports are not allowed on NodeBalancers by the CCM configuration", port.Port, privilegedPortLimit)
+				return fmt.Errorf("%w: port %d of service (%s)", errPrivilegedPort, port.Port, getServiceNn(service))
+			}
+		}
+	}
+	return nil
+}
+
+// getIdleTimeout returns the idle connection timeout annotation of service, in
+// seconds, and whether it is set.
+func getIdleTimeout(service *v1.Service) (int, bool, error) {
+	timeout, ok, err := getAnnotationInt(service, annotations.AnnLinodeIdleTimeout)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	if timeout < minIdleTimeout || timeout > maxIdleTimeout {
+		return 0, true, invalidAnnotationError{
+			name:   annotations.AnnLinodeIdleTimeout,
+			value:  strconv.Itoa(timeout),
+			reason: fmt.Sprintf("must be between %d and %d", minIdleTimeout, maxIdleTimeout),
+		}
+	}
+	return timeout, true, nil
+}
+
+// validateIdleTimeout returns an error, and records a Warning event, when
+// service sets an idle connection timeout. The Linode API does not expose one,
+// so it cannot be applied and is rejected rather than silently ignored.
+func (l *loadbalancers) validateIdleTimeout(service *v1.Service) error {
+	timeout, ok, err := getIdleTimeout(service)
+	if !ok {
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%w: service (%s) sets %s to %d", errIdleTimeoutUnsupported, getServiceNn(service), annotations.AnnLinodeIdleTimeout, timeout)
+	}
+	l.recordEvent(service, v1.EventTypeWarning, eventReasonUnsupportedSetting, "%s", err)
+	return err
+}
+
+// serviceBoolAnnotations are the boolean annotations read outside of functions
+// returning errors, which are validated before a Service is reconciled.
+var serviceBoolAnnotations = []string{
+	annotations.AnnLinodeLoadBalancerPreserve,
+	annotations.AnnLinodeAuditTags,
+	annotations.AnnLinodeHostNetworking,
+	annotations.AnnLinodeHealthCheckIntervalScaling,
+	annotations.AnnLinodeHostnameOnlyIngress,
+}
+
+// validateServiceAnnotations returns an error, and records a Warning event,
+// when a boolean annotation or the throttle annotation of service has an
+// invalid value, rather than reconciling it as if the annotation was not set,
+// when the health check annotations are invalid, or when an https port does
+// not reference a TLS secret.
+func (l *loadbalancers) validateServiceAnnotations(service *v1.Service) error {
+	var err error
+	for _, name := range serviceBoolAnnotations {
+		if _, err = getServiceBoolAnnotation(service, name); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		_, err = getConnectionThrottle(service)
+	}
+	if err == nil {
+		_, err = getMinBackends(service)
+	}
+	if err == nil {
+		_, err = getBackendNodeSelector(service)
+	}
+	if err == nil {
+		_, err = getAnnotationTags(service)
+	}
+	if err == nil {
+		// the health check settings do not depend on the config they are set on
+		err = setHealthCheck(service, &linodego.NodeBalancerConfig{}, 0)
+	}
+	if err == nil {
+		err = setBalancing(service, &linodego.NodeBalancerConfig{})
+	}
+	if err == nil {
+		err = validateHTTPSPorts(service)
+	}
+	if err != nil {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidAnnotation, "%s", err)
+	}
+	return err
+}
+
+// validateHTTPSPorts returns an error when a port of service is balanced with
+// the https protocol, from its port config or the default protocol, without
+// referencing the TLS secret serving it.
+func validateHTTPSPorts(service *v1.Service) error {
+	for _, port := range service.Spec.Ports {
+		portConfig, err := getPortConfig(service, int(port.Port))
+		if err != nil {
+			// invalid port configs are reported when building the NodeBalancer config
+			continue
+		}
+		if portConfig.Protocol == linodego.ProtocolHTTPS && portConfig.TLSSecretName == "" {
+			portConfigKey := annotations.AnnLinodePortConfigPrefix + strconv.Itoa(int(port.Port))
+			return invalidAnnotationError{
+				name:   portConfigKey,
+				value:  service.Annotations[portConfigKey],
+				reason: "https ports must reference a TLS secret with tls-secret-name or tls-secrets",
+			}
+		}
+	}
+	return nil
+}
+
+// getMinBackends returns the minimum number of backend nodes of the
+// NodeBalancer of service: its min-backends annotation, else
+// Options.MinBackends.
+func getMinBackends(service *v1.Service) (int, error) {
+	minBackends, ok, err := getAnnotationInt(service, annotations.AnnLinodeMinBackends)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return Options.MinBackends, nil
+	}
+	if minBackends < 0 {
+		return 0, invalidAnnotationError{
+			name:   annotations.AnnLinodeMinBackends,
+			value:  service.Annotations[annotations.AnnLinodeMinBackends],
+			reason: "must not be negative",
+		}
+	}
+	return minBackends, nil
+}
+
+// warnBelowMinBackends emits a Warning event when fewer of nodes are eligible
+// as backends of nb than the minimum set for service, e.g. so that a single
+// backend, which takes the Service down with it, is noticed.
+func (l *loadbalancers) warnBelowMinBackends(service *v1.Service, nb *linodego.NodeBalancer, nodes []*v1.Node) {
+	// invalid values are rejected by validateServiceAnnotations before the
+	// NodeBalancer is reconciled
+	minBackends, _ := getMinBackends(service)
+	if minBackends <= 0 {
+		return
+	}
+	if backends := len(eligibleBackendNodes(service, nodes)); backends < minBackends {
+		l.recordEvent(service, v1.EventTypeWarning, eventReasonBelowMinBackends,
+			"NodeBalancer (%d) has %d backends, fewer than the minimum of %d", nb.ID, backends, minBackends)
+	}
+}
+
+// validatePortProtocol returns an error, and records a Warning event, when the
+// protocol of port is not compatible with the NodeBalancer protocol configured
+// for it, such as a UDP port annotated for http.
+func (l *loadbalancers) validatePortProtocol(service *v1.Service, port v1.ServicePort) error {
+	portConfig, err := getPortConfig(service, int(port.Port))
+	if err != nil {
+		// invalid port configs are reported when building the NodeBalancer config
+		return nil
+	}
+
+	protocol := v1.Protocol(strings.ToUpper(string(port.Protocol)))
+	if protocol != v1.ProtocolUDP && protocol != v1.ProtocolSCTP {
+		protocol = v1.ProtocolTCP
+	}
+	if slices.Contains(nodeBalancerL4Protocols[portConfig.Protocol], protocol) {
+		return nil
+	}
+
+	err = fmt.Errorf("port %d: %s ports are not compatible with the NodeBalancer protocol %s", port.Port, protocol, portConfig.Protocol)
+	l.recordEvent(service, v1.EventTypeWarning, eventReasonInvalidPortProtocol, "%s", err)
+	return err
+}
+
+// getCheckPath returns the path of http health checks for a config of
+// protocol: the check-path annotation, else the cluster-wide default for the
+// protocol, else /.
+func getCheckPath(service *v1.Service, protocol linodego.ConfigProtocol) string {
+	if path, _ := getAnnotationString(service, annotations.AnnLinodeCheckPath); path != "" {
+		return path
+	}
+	if path, ok := Options.DefaultCheckPaths[string(protocol)]; ok && path != "" {
+		return path
+	}
+	return "/"
+}
+
+// isLocalTrafficPolicy reports whether external traffic to service is only
+// routed to endpoints on the node receiving it.
+func isLocalTrafficPolicy(service *v1.Service) bool {
+	return service.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyLocal
+}
+
 func getHealthCheckType(service *v1.Service) (linodego.ConfigCheck, error) {
-	hType, ok := service.GetAnnotations()[annotations.AnnLinodeHealthCheckType]
+	hType, ok, err := getAnnotationEnum(service, annotations.AnnLinodeHealthCheckType, configChecks...)
+	if err != nil {
+		return "", err
+	}
 	if !ok {
 		return linodego.CheckConnection, nil
 	}
-	if hType != "none" && hType != "connection" && hType != "http" && hType != "http_body" {
-		return "", fmt.Errorf("invalid health check type: %q specified in annotation: %q", hType, annotations.AnnLinodeHealthCheckType)
-	}
 	return linodego.ConfigCheck(hType), nil
 }
 
@@ -911,12 +3206,166 @@ func getNodePrivateIP(node *v1.Node) string {
 	return ""
 }
 
+// getNodeBackendIP returns the address the NodeBalancer should use to reach
+// node. When the Service specifies a backend subnet, the node address within
+// that subnet is used; this allows picking the right private IP on nodes with
+// multiple NICs. Otherwise, when a backend IP preference is configured for the
+// Service or the CCM, the first available address type in that order is used.
+// It falls back to getNodePrivateIP.
+func getNodeBackendIP(service *v1.Service, node *v1.Node) (string, error) {
+	return selectBackendIP(service, node.Name, getNodeAddressCandidates(node), getNodePrivateIP(node))
+}
+
+// getInstanceBackendIP is like getNodeBackendIP, but picks the address from the
+// networking of the Linode backing node rather than from the Node object, for
+// setups where the address to use is on an interface not reported by the node.
+// It falls back to the first VPC, private or public address, in that order.
+func (l *loadbalancers) getInstanceBackendIP(ctx context.Context, service *v1.Service, node *v1.Node) (string, error) {
+	linodeID, err := parseProviderID(node.Spec.ProviderID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up networking of node %s: %w", node.Name, err)
+	}
+	ips, err := l.client.GetInstanceIPAddresses(ctx, linodeID)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up networking of node %s: %w", node.Name, err)
+	}
+
+	var candidates []string
+	if ips.IPv4 != nil {
+		for _, ip := range ips.IPv4.VPC {
+			if ip.Address != nil {
+				candidates = append(candidates, *ip.Address)
+			}
+		}
+		for _, ip := range append(slices.Clone(ips.IPv4.Private), ips.IPv4.Public...) {
+			candidates = append(candidates, ip.Address)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("linode %d of node %s has no IPv4 addresses", linodeID, node.Name)
+	}
+	return selectBackendIP(service, node.Name, candidates, candidates[0])
+}
+
+// selectBackendIP picks the backend address of a node among candidates, as per
+// the backend subnet or backend IP preference of the Service, or fallback.
+func selectBackendIP(service *v1.Service, nodeName string, candidates []string, fallback string) (string, error) {
+	subnets, ok, err := getAnnotationCIDRs(service, annotations.AnnLinodeBackendSubnet)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return getIPInSubnets(nodeName, candidates, subnets)
+	}
+
+	preference := Options.BackendIPPreference
+	if raw, ok := getAnnotationString(service, annotations.AnnLinodeBackendIPPreference); ok {
+		preference = raw
+	}
+	if preference == "" {
+		return fallback, nil
+	}
+
+	order, err := parseBackendIPPreference(preference)
+	if err != nil {
+		return "", err
+	}
+	for _, ipType := range order {
+		for _, address := range candidates {
+			if classifyBackendIP(address) == ipType {
+				return address, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("node %s has no address of the preferred types %v", nodeName, order)
+}
+
+// getNodeAddressCandidates returns the node addresses that may be used as a
+// backend, starting with the private IP annotation if set.
+func getNodeAddressCandidates(node *v1.Node) []string {
+	candidates := make([]string, 0, len(node.Status.Addresses)+1)
+	if address, exists := node.Annotations[annotations.AnnLinodeNodePrivateIP]; exists {
+		candidates = append(candidates, address)
+	}
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP || addr.Type == v1.NodeExternalIP {
+			candidates = append(candidates, addr.Address)
+		}
+	}
+	return candidates
+}
+
+// getIPInSubnets returns the first of candidates within any of subnets.
+func getIPInSubnets(nodeName string, candidates []string, subnets []*net.IPNet) (string, error) {
+	for _, address := range candidates {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			continue
+		}
+		for _, subnet := range subnets {
+			if subnet.Contains(ip) {
+				return address, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("node %s has no address within backend subnets %v", nodeName, subnets)
+}
+
+// parseBackendIPPreference parses a comma separated, ordered list of backend IP types.
+func parseBackendIPPreference(raw string) ([]string, error) {
+	var order []string
+	for _, ipType := range strings.Split(raw, ",") {
+		ipType = strings.ToLower(strings.TrimSpace(ipType))
+		switch ipType {
+		case backendIPTypeVPC, backendIPTypePrivate, backendIPTypePublic:
+			order = append(order, ipType)
+		default:
+			return nil, fmt.Errorf("invalid backend IP type %q, options are %s, %s and %s",
+				ipType, backendIPTypeVPC, backendIPTypePrivate, backendIPTypePublic)
+		}
+	}
+	return order, nil
+}
+
+// classifyBackendIP returns whether address is a Linode private IP, a VPC IP
+// (any other private range) or a public IP.
+func classifyBackendIP(address string) string {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return ""
+	case linodePrivateSubnet.Contains(ip):
+		return backendIPTypePrivate
+	case ip.IsPrivate():
+		return backendIPTypeVPC
+	default:
+		return backendIPTypePublic
+	}
+}
+
+// getTLSSecretRef returns the secret referenced by name, either the name of a
+// secret in namespace or a <namespace>/<name> reference.
+func getTLSSecretRef(namespace, name string) types.NamespacedName {
+	if secretNamespace, secretName, ok := strings.Cut(name, "/"); ok {
+		return types.NamespacedName{Namespace: secretNamespace, Name: secretName}
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}
+}
+
+// getTLSCertInfo returns the certificate and private key of the TLS secret of
+// config, for a Service in namespace. Secrets of other namespaces are only read
+// with Options.AllowCrossNamespaceTLSSecrets.
 func getTLSCertInfo(ctx context.Context, kubeClient kubernetes.Interface, namespace string, config portConfig) (string, string, error) {
 	if config.TLSSecretName == "" {
 		return "", "", fmt.Errorf("TLS secret name for port %v is not specified", config.Port)
 	}
 
-	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, config.TLSSecretName, metav1.GetOptions{})
+	ref := getTLSSecretRef(namespace, config.TLSSecretName)
+	if ref.Namespace != namespace && !Options.AllowCrossNamespaceTLSSecrets {
+		return "", "", fmt.Errorf("%w: TLS secret %s for port %d", errCrossNamespaceSecret, ref, config.Port)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
 	if err != nil {
 		return "", "", err
 	}
@@ -931,40 +3380,73 @@ func getTLSCertInfo(ctx context.Context, kubeClient kubernetes.Interface, namesp
 	return cert, key, nil
 }
 
-func getConnectionThrottle(service *v1.Service) int {
+// getConnectionThrottle returns the client connection throttle of the
+// NodeBalancer of service, 0 disabling it when the annotation is not set.
+// Values outside of the range accepted by the API are rejected rather than
+// clamped, so the throttle applied is the one requested.
+func getConnectionThrottle(service *v1.Service) (int, error) {
 	connThrottle := 0 // disable throttle if nothing is specified
 
-	if connThrottleString := service.GetAnnotations()[annotations.AnnLinodeThrottle]; connThrottleString != "" {
-		parsed, err := strconv.Atoi(connThrottleString)
-		if err == nil {
-			if parsed < 0 {
-				parsed = 0
-			}
-
-			if parsed > 20 {
-				parsed = 20
+	parsed, ok, err := getAnnotationInt(service, annotations.AnnLinodeThrottle)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		if parsed < minConnThrottle || parsed > maxConnThrottle {
+			return 0, invalidAnnotationError{
+				name:   annotations.AnnLinodeThrottle,
+				value:  strconv.Itoa(parsed),
+				reason: fmt.Sprintf("must be between %d and %d", minConnThrottle, maxConnThrottle),
 			}
-			connThrottle = parsed
 		}
+		connThrottle = parsed
 	}
 
-	return connThrottle
+	return connThrottle, nil
 }
 
 func makeLoadBalancerStatus(service *v1.Service, nb *linodego.NodeBalancer) *v1.LoadBalancerStatus {
+	// a NodeBalancer still being provisioned has neither address, and an
+	// empty ingress must not be reported for it
+	if !isNodeBalancerReady(nb) {
+		return &v1.LoadBalancerStatus{}
+	}
+
 	ingress := v1.LoadBalancerIngress{
 		Hostname: *nb.Hostname,
 	}
-	if !getServiceBoolAnnotation(service, annotations.AnnLinodeHostnameOnlyIngress) {
+	// invalid values are rejected by validateServiceAnnotations before the
+	// NodeBalancer is reconciled
+	hostnameOnly, _ := getServiceBoolAnnotation(service, annotations.AnnLinodeHostnameOnlyIngress)
+	if !hostnameOnly {
 		if val := envBoolOptions("LINODE_HOSTNAME_ONLY_INGRESS"); val {
 			klog.Infof("LINODE_HOSTNAME_ONLY_INGRESS:  (%v)", val)
 		} else {
 			ingress.IP = *nb.IPv4
 		}
 	}
-	return &v1.LoadBalancerStatus{
+	status := &v1.LoadBalancerStatus{
 		Ingress: []v1.LoadBalancerIngress{ingress},
 	}
+	// the IPv4 ingress always comes first, as it is used to look up the NodeBalancer
+	if ingress.IP != "" && isDualStack(service) && nb.IPv6 != nil && *nb.IPv6 != "" {
+		status.Ingress = append(status.Ingress, v1.LoadBalancerIngress{IP: *nb.IPv6})
+	}
+	return status
+}
+
+// isDualStack reports whether service requests both IPv4 and IPv6.
+func isDualStack(service *v1.Service) bool {
+	var ipv4, ipv6 bool
+	for _, family := range service.Spec.IPFamilies {
+		switch family {
+		case v1.IPv4Protocol:
+			ipv4 = true
+		case v1.IPv6Protocol:
+			ipv6 = true
+		}
+	}
+	return ipv4 && ipv6
 }
 
 // Checks for a truth value in an environment variable
@@ -976,16 +3458,46 @@ func envBoolOptions(o string) bool {
 	return boolValue
 }
 
+// getBackendPort returns the port NodeBalancer backends are addressed on for a
+// Service port: the NodePort, or for host-networking Services the target port
+// the pods listen on directly.
+func getBackendPort(service *v1.Service, port v1.ServicePort) (int32, error) {
+	hostNetworking, err := getServiceBoolAnnotation(service, annotations.AnnLinodeHostNetworking)
+	if err != nil {
+		return 0, err
+	}
+	if !hostNetworking {
+		return port.NodePort, nil
+	}
+
+	switch {
+	case port.TargetPort.Type == intstr.String && port.TargetPort.StrVal != "":
+		return 0, fmt.Errorf("port %d of service (%s) uses named targetPort %q, which is not supported with host networking", port.Port, getServiceNn(service), port.TargetPort.StrVal)
+	case port.TargetPort.IntVal != 0:
+		return port.TargetPort.IntVal, nil
+	default:
+		// targetPort defaults to the Service port
+		return port.Port, nil
+	}
+}
+
+// isNamespaceManaged reports whether LoadBalancer Services in namespace are
+// managed by this CCM according to the namespace allow and deny lists.
+func isNamespaceManaged(namespace string) bool {
+	if slices.Contains(Options.ExcludedServiceNamespaces, namespace) {
+		return false
+	}
+	return len(Options.ServiceNamespaces) == 0 || slices.Contains(Options.ServiceNamespaces, namespace)
+}
+
 // getServiceNn returns the services namespaced name.
 func getServiceNn(service *v1.Service) string {
 	return fmt.Sprintf("%s/%s", service.Namespace, service.Name)
 }
 
-func getServiceBoolAnnotation(service *v1.Service, name string) bool {
-	value, ok := service.GetAnnotations()[name]
-	if !ok {
-		return false
-	}
-	boolValue, err := strconv.ParseBool(value)
-	return err == nil && boolValue
+// getServiceBoolAnnotation reports whether the annotation name of service is
+// set to true. Invalid values are returned as an invalidAnnotationError.
+func getServiceBoolAnnotation(service *v1.Service, name string) (bool, error) {
+	value, _, err := getAnnotationBool(service, name)
+	return value, err
 }
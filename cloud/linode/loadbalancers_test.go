@@ -1,32 +1,60 @@
 package linode
 
 import (
+	"bytes"
 	"context"
 	cryptoRand "crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	stderrors "errors"
+	"flag"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/linode/linodego"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1apply "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	cloudprovider "k8s.io/cloud-provider"
+	"k8s.io/cloud-provider/api"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
 	"github.com/linode/linode-cloud-controller-manager/cloud/linode/firewall"
 )
 
@@ -150,10 +178,26 @@ func TestCCMLoadBalancers(t *testing.T) {
 			name: "Create Load Balancer With Invalid Firewall ACL - NO Allow Or Deny",
 			f:    testCreateNodeBalanceWithNoAllowOrDenyList,
 		},
+		{
+			name: "Create Load Balancer With UDP Port",
+			f:    testCreateNodeBalancerWithUDPPort,
+		},
 		{
 			name: "Update Load Balancer - Add Node",
 			f:    testUpdateLoadBalancerAddNode,
 		},
+		{
+			name: "Update Load Balancer - Paginated Nodes",
+			f:    testUpdateLoadBalancerPaginatedNodes,
+		},
+		{
+			name: "Update Load Balancer - Concurrent Secret Controller",
+			f:    testUpdateLoadBalancerConcurrentSecretController,
+		},
+		{
+			name: "Update Load Balancer - Required Node Conditions",
+			f:    testUpdateLoadBalancerRequiredNodeConditions,
+		},
 		{
 			name: "Update Load Balancer - Add Annotation",
 			f:    testUpdateLoadBalancerAddAnnotation,
@@ -166,10 +210,38 @@ func TestCCMLoadBalancers(t *testing.T) {
 			name: "Update Load Balancer - Add TLS Port",
 			f:    testUpdateLoadBalancerAddTLSPort,
 		},
+		{
+			name: "Update Load Balancer - Reordered Configs",
+			f:    testUpdateLoadBalancerReorderedConfigs,
+		},
+		{
+			name: "Update Load Balancer - Repair Health Check",
+			f:    testUpdateLoadBalancerRepairHealthCheck,
+		},
+		{
+			name: "Update Load Balancer - Remove Port",
+			f:    testUpdateLoadBalancerRemovePort,
+		},
+		{
+			name: "Update Load Balancer - Unowned Configs",
+			f:    testUpdateLoadBalancerUnownedConfigs,
+		},
+		{
+			name: "Update Load Balancer - Log TLS Config",
+			f:    testUpdateLoadBalancerLogTLSConfig,
+		},
 		{
 			name: "Update Load Balancer - Add Tags",
 			f:    testUpdateLoadBalancerAddTags,
 		},
+		{
+			name: "Update Load Balancer - Label Tags",
+			f:    testUpdateLoadBalancerLabelTags,
+		},
+		{
+			name: "Update Load Balancer - Adopted Tags",
+			f:    testUpdateLoadBalancerAdoptedTags,
+		},
 		{
 			name: "Update Load Balancer - Specify NodeBalancerID",
 			f:    testUpdateLoadBalancerAddNodeBalancerID,
@@ -218,10 +290,18 @@ func TestCCMLoadBalancers(t *testing.T) {
 			name: "Ensure Load Balancer Deleted",
 			f:    testEnsureLoadBalancerDeleted,
 		},
+		{
+			name: "Ensure Load Balancer Deleted - Firewall ACL",
+			f:    testEnsureLoadBalancerDeletedFirewallACL,
+		},
 		{
 			name: "Ensure Load Balancer Deleted - Preserve Annotation",
 			f:    testEnsureLoadBalancerPreserveAnnotation,
 		},
+		{
+			name: "Ensure Load Balancer - Reattach Preserved",
+			f:    testEnsureLoadBalancerReattachPreserved,
+		},
 		{
 			name: "Ensure Existing Load Balancer",
 			f:    testEnsureExistingLoadBalancer,
@@ -234,6 +314,18 @@ func TestCCMLoadBalancers(t *testing.T) {
 			name: "Ensure New Load Balancer with NodeBalancerID",
 			f:    testEnsureNewLoadBalancerWithNodeBalancerID,
 		},
+		{
+			name: "Ensure Load Balancer Deleted releases the NodeBalancer of NodeBalancerID",
+			f:    testEnsureLoadBalancerDeletedReleasesNodeBalancerID,
+		},
+		{
+			name: "Update Load Balancer - Switch Between Pre-existing NodeBalancers",
+			f:    testUpdateLoadBalancerSwitchNodeBalancerID,
+		},
+		{
+			name: "Ensure Load Balancer with a NodeBalancerID which does not exist",
+			f:    testEnsureLoadBalancerNodeBalancerIDNotFound,
+		},
 		{
 			name: "getNodeBalancerForService - NodeBalancerID does not exist",
 			f:    testGetNodeBalancerForServiceIDDoesNotExist,
@@ -254,6 +346,130 @@ func TestCCMLoadBalancers(t *testing.T) {
 			name: "Update Load Balancer - No Nodes",
 			f:    testUpdateLoadBalancerNoNodes,
 		},
+		{
+			name: "Ensure Load Balancer - No Backend Nodes",
+			f:    testEnsureLoadBalancerNoBackendNodes,
+		},
+		{
+			name: "Ensure Load Balancer - Region Mismatch",
+			f:    testEnsureLoadBalancerRegionMismatch,
+		},
+		{
+			name: "Ensure Load Balancer - Retry Budget",
+			f:    testEnsureLoadBalancerRetryBudget,
+		},
+		{
+			name: "Ensure Load Balancer - Multi Region",
+			f:    testEnsureLoadBalancerMultiRegion,
+		},
+		{
+			name: "Update Load Balancer - Region Mismatch Metric",
+			f:    testUpdateLoadBalancerRegionMismatchMetric,
+		},
+		{
+			name: "Ensure Load Balancer - Duplicate Ports",
+			f:    testEnsureLoadBalancerDuplicatePorts,
+		},
+		{
+			name: "Ensure Load Balancer - Unique Backend Labels",
+			f:    testEnsureLoadBalancerUniqueBackendLabels,
+		},
+		{
+			name: "Ensure Load Balancer - Pending NodeBalancer IP",
+			f:    testEnsureLoadBalancerPendingIP,
+		},
+		{
+			name: "Update Load Balancer - External Traffic Policy Transitions",
+			f:    testUpdateLoadBalancerExternalTrafficPolicy,
+		},
+		{
+			name: "Ensure Load Balancer - Recreate Emits Warning",
+			f:    testEnsureLoadBalancerRecreated,
+		},
+		{
+			name: "Ensure Load Balancer - Dual Stack",
+			f:    testEnsureLoadBalancerDualStack,
+		},
+		{
+			name: "Update Load Balancer - Audit Tags",
+			f:    testUpdateLoadBalancerAuditTags,
+		},
+		{
+			name: "Ensure Load Balancer - Certificate Expiry",
+			f:    testEnsureLoadBalancerCertExpiry,
+		},
+		{
+			name: "Ensure Load Balancer - TLS Key Pair",
+			f:    testEnsureLoadBalancerTLSKeyPair,
+		},
+		{
+			name: "Ensure Load Balancer - Service Removed",
+			f:    testEnsureLoadBalancerServiceRemoved,
+		},
+		{
+			name: "Update Load Balancer - TLS Secret Missing",
+			f:    testUpdateLoadBalancerTLSSecretMissing,
+		},
+		{
+			name: "Ensure Load Balancer - Unmanaged Namespace",
+			f:    testEnsureLoadBalancerUnmanagedNamespace,
+		},
+		{
+			name: "Ensure Load Balancer Deleted - Unmanaged Namespace Owned NodeBalancer",
+			f:    testEnsureLoadBalancerDeletedUnmanagedNamespace,
+		},
+		{
+			name: "Ensure Load Balancer - Stale Ingress Replaced",
+			f:    testEnsureLoadBalancerStaleIngress,
+		},
+		{
+			name: "Ensure Load Balancer - Annotate NodeBalancer ID",
+			f:    testEnsureLoadBalancerAnnotateNodeBalancerID,
+		},
+		{
+			name: "Ensure Load Balancer - Adopt After Provisioning Timeout",
+			f:    testEnsureLoadBalancerAdoptAfterTimeout,
+		},
+		{
+			name: "Update Load Balancer - Drain Removed Backends",
+			f:    testUpdateLoadBalancerDrainRemovedBackends,
+		},
+		{
+			name: "Update Load Balancer - Drain Unschedulable Nodes",
+			f:    testUpdateLoadBalancerDrainUnschedulableNodes,
+		},
+		{
+			name: "Update Load Balancer - Config Count Metric",
+			f:    testUpdateLoadBalancerConfigCountMetric,
+		},
+		{
+			name: "Ensure Load Balancer - Load Balancer IP",
+			f:    testEnsureLoadBalancerLoadBalancerIP,
+		},
+		{
+			name: "Ensure Load Balancer - Host Networking",
+			f:    testEnsureLoadBalancerHostNetworking,
+		},
+		{
+			name: "Update Load Balancer - Unchanged Service",
+			f:    testUpdateLoadBalancerUnchangedService,
+		},
+		{
+			name: "Update Load Balancer - Delete Orphaned Backends",
+			f:    testUpdateLoadBalancerDeleteOrphanedBackends,
+		},
+		{
+			name: "Update Load Balancer - Retain Last Healthy Backend",
+			f:    testUpdateLoadBalancerRetainHealthyBackend,
+		},
+		{
+			name: "Ensure Load Balancer - Min TLS Version",
+			f:    testEnsureLoadBalancerMinTLSVersion,
+		},
+		{
+			name: "Migrate Cluster Tag",
+			f:    testMigrateClusterTag,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -341,7 +557,7 @@ func testCreateNodeBalancer(t *testing.T, client *linodego.Client, _ *fakeAPI, a
 		t.Logf("actual: %v", nb.ClientConnThrottle)
 	}
 
-	expectedTags := []string{"linodelb", "fake", "test", "yolo"}
+	expectedTags := []string{"linodelb", "fake", "test", "yolo", getOwnerTag(svc), configPortTagPrefix + "80", configPortTagPrefix + "8080"}
 	if !reflect.DeepEqual(nb.Tags, expectedTags) {
 		t.Error("unexpected Tags")
 		t.Logf("expected: %v", expectedTags)
@@ -452,25 +668,35 @@ func testCreateNodeBalancerWithInvalidFirewall(t *testing.T, client *linodego.Cl
 	}
 }
 
-func testUpdateLoadBalancerAddNode(t *testing.T, client *linodego.Client, f *fakeAPI) {
+func testCreateNodeBalancerWithUDPPort(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: randString(),
-			UID:  "foobar1234",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeDefaultProxyProtocol:    string(linodego.ProxyProtocolV2),
+				annotations.AnnLinodePortConfigPrefix + "53": `{ "protocol": "udp", "tls-secret-name": "tls-secret" }`,
+			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
 				{
-					Name:     randString(),
-					Protocol: "TCP",
-					Port:     int32(80),
+					Name:     "dns-udp",
+					Protocol: v1.ProtocolUDP,
+					Port:     int32(53),
 					NodePort: int32(30000),
 				},
+				{
+					Name:     "http",
+					Protocol: v1.ProtocolTCP,
+					Port:     int32(80),
+					NodePort: int32(30001),
+				},
 			},
 		},
 	}
 
-	nodes1 := []*v1.Node{
+	nodes := []*v1.Node{
 		{
 			Status: v1.NodeStatus{
 				Addresses: []v1.NodeAddress{
@@ -483,37 +709,73 @@ func testUpdateLoadBalancerAddNode(t *testing.T, client *linodego.Client, f *fak
 		},
 	}
 
-	nodes2 := []*v1.Node{
-		{
-			Status: v1.NodeStatus{
-				Addresses: []v1.NodeAddress{
-					{
-						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.1",
-					},
-				},
-			},
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer configs: %s", err)
+	}
+
+	expected := map[int][2]string{
+		53: {string(protocolUDP), string(linodego.ProxyProtocolNone)},
+		80: {string(linodego.ProtocolTCP), string(linodego.ProxyProtocolV2)},
+	}
+	if len(configs) != len(expected) {
+		t.Fatalf("expected %d configs, got %d", len(expected), len(configs))
+	}
+	for _, config := range configs {
+		actual := [2]string{string(config.Protocol), string(config.ProxyProtocol)}
+		if actual != expected[config.Port] {
+			t.Errorf("unexpected protocol and proxy protocol of port %d: expected %v, got %v", config.Port, expected[config.Port], actual)
+		}
+	}
+}
+
+func testUpdateLoadBalancerPaginatedNodes(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
 		},
-		{
-			Status: v1.NodeStatus{
-				Addresses: []v1.NodeAddress{
-					{
-						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.2",
-					},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
 				},
 			},
 		},
-		{
+	}
+
+	// more backends than fit in a page of the API
+	nodeCount := fakePageSize + fakePageSize/2
+	nodes := make([]*v1.Node, 0, nodeCount)
+	for i := range nodeCount {
+		nodes = append(nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
 			Status: v1.NodeStatus{
 				Addresses: []v1.NodeAddress{
 					{
 						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.3",
+						Address: fmt.Sprintf("192.168.%d.%d", 200+i/250, 1+i%250),
 					},
 				},
 			},
-		},
+		})
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
@@ -524,74 +786,289 @@ func testUpdateLoadBalancerAddNode(t *testing.T, client *linodego.Client, f *fak
 		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
 	}()
 
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes1)
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
 	svc.Status.LoadBalancer = *lbStatus
-
 	stubService(fakeClientset, svc)
 
-	f.ResetRequests()
-
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes1)
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error while updated LB to have one node: %s", err)
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer configs: %s", err)
+	}
+	i := slices.IndexFunc(configs, func(config linodego.NodeBalancerConfig) bool { return config.NodeBalancerID == nb.ID })
+	if i < 0 {
+		t.Fatal("NodeBalancer config was not created")
+	}
+	nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, configs[i].ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer nodes: %s", err)
+	}
+	assert.Len(t, nbNodes, nodeCount, "expected the nodes of all pages to be listed")
+
+	f.ResetRequests()
+	// change the config only, as configs which are up to date are not rebuilt
+	svc.SetAnnotations(map[string]string{annotations.AnnLinodeHealthCheckAttempts: "3"})
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
 	}
 
 	rx := regexp.MustCompile("/nodebalancers/[0-9]+/configs/[0-9]+/rebuild")
-	checkIDs := func() (int, int) {
-		var req *fakeRequest
-		for request := range f.requests {
-			request := request
-			if rx.MatchString(request.Path) {
-				req = &request
-				break
+	var rebuild *linodego.NodeBalancerConfigRebuildOptions
+	for request := range f.requests {
+		if rx.MatchString(request.Path) {
+			rebuild = new(linodego.NodeBalancerConfigRebuildOptions)
+			if err = json.Unmarshal([]byte(request.Body), rebuild); err != nil {
+				t.Fatalf("failed to decode the rebuild request: %s", err)
 			}
 		}
+	}
+	if rebuild == nil {
+		t.Fatal("NodeBalancer config rebuild request was not sent")
+	}
 
-		if req == nil {
-			t.Fatalf("Nodebalancer config rebuild request was not called.")
+	// the nodes of every page are rebuilt with their existing IDs
+	withIDs := 0
+	for _, node := range rebuild.Nodes {
+		if node.ID > 0 {
+			withIDs++
 		}
+	}
+	assert.Len(t, rebuild.Nodes, nodeCount)
+	assert.Equal(t, nodeCount, withIDs, "expected all nodes to keep their ID")
+}
 
-		var nbcro linodego.NodeBalancerConfigRebuildOptions
+// testUpdateLoadBalancerConcurrentSecretController reconciles a Service from
+// the worker of the secret controller while the service controller updates
+// it, which must be run with -race to catch unserialized reconciles.
+func testUpdateLoadBalancerConcurrentSecretController(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      randString(),
+			Namespace: "default",
+			UID:       "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{nodeInRegion("node-1", "")}
 
-		if err := json.Unmarshal([]byte(req.Body), &nbcro); err != nil {
-			t.Fatalf("Unable to unmarshall request body %#v, error: %#v", req.Body, err)
-		}
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
 
-		withIds := 0
-		for i := range nbcro.Nodes {
-			if nbcro.Nodes[i].ID > 0 {
-				withIds++
-			}
-		}
+	factory := informers.NewSharedInformerFactory(fakeClientset, 0)
+	secretInformer := newSecretMetadataInformer(metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()))
+	controller := newSecretController(lb, secretInformer, factory.Core().V1().Services(), factory.Core().V1().Nodes())
+	lb.nodeLister = factory.Core().V1().Nodes().Lister()
 
-		return len(nbcro.Nodes), withIds
-	}
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
 
-	nodecount, nodeswithIdcount := checkIDs()
-	if nodecount != 1 {
-		t.Fatalf("Unexpected node count (%d) in request on updating the nodebalancer with one node.", nodecount)
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
-	if nodeswithIdcount != 1 {
-		t.Fatalf("Expected Node ID to be set when updating the nodebalancer with the same node it had previously.")
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+	assert.NoError(t, factory.Core().V1().Services().Informer().GetIndexer().Add(svc.DeepCopy()))
+	assert.NoError(t, factory.Core().V1().Nodes().Informer().GetIndexer().Add(nodes[0]))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 5 {
+			controller.queue.Add(types.NamespacedName{Namespace: svc.Namespace, Name: svc.Name})
+			controller.processNext()
+		}
+	}()
+	for i := range 5 {
+		// a changed config is rebuilt by both reconciles
+		update := svc.DeepCopy()
+		update.SetAnnotations(map[string]string{annotations.AnnLinodeHealthCheckAttempts: strconv.Itoa(i + 1)})
+		if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", update, nodes); err != nil {
+			t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+		}
 	}
+	wg.Wait()
 
-	f.ResetRequests()
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes2)
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error while updated LB to have three nodes: %s", err)
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
 	}
-	nodecount, nodeswithIdcount = checkIDs()
-	if nodecount != 3 {
-		t.Fatalf("Unexpected node count (%d) in request on updating the nodebalancer with three nodes.", nodecount)
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer configs: %s", err)
 	}
-	if nodeswithIdcount != 1 {
-		t.Fatalf("Expected ID to be set just on one node which was existing prior updating the node with three nodes, it is set on %d nodes", nodeswithIdcount)
+	assert.Len(t, configs, 1, "expected a single config for the port")
+	for _, config := range configs {
+		nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, config.ID, nil)
+		if err != nil {
+			t.Fatalf("failed to list NodeBalancer nodes: %s", err)
+		}
+		assert.Len(t, nbNodes, 1, "expected a single node for the config")
+	}
+}
+
+func testUpdateLoadBalancerAddNode(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar1234",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes1 := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	nodes2 := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.2",
+					},
+				},
+			},
+		},
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.3",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes1)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+
+	stubService(fakeClientset, svc)
+
+	f.ResetRequests()
+
+	// change the config only, as configs which are up to date are not rebuilt
+	svc.SetAnnotations(map[string]string{annotations.AnnLinodeHealthCheckAttempts: "3"})
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes1)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error while updated LB to have one node: %s", err)
+	}
+
+	rx := regexp.MustCompile("/nodebalancers/[0-9]+/configs/[0-9]+/rebuild")
+	checkIDs := func() (int, int) {
+		var req *fakeRequest
+		for request := range f.requests {
+			request := request
+			if rx.MatchString(request.Path) {
+				req = &request
+				break
+			}
+		}
+
+		if req == nil {
+			t.Fatalf("Nodebalancer config rebuild request was not called.")
+		}
+
+		var nbcro linodego.NodeBalancerConfigRebuildOptions
+
+		if err := json.Unmarshal([]byte(req.Body), &nbcro); err != nil {
+			t.Fatalf("Unable to unmarshall request body %#v, error: %#v", req.Body, err)
+		}
+
+		withIds := 0
+		for i := range nbcro.Nodes {
+			if nbcro.Nodes[i].ID > 0 {
+				withIds++
+			}
+		}
+
+		return len(nbcro.Nodes), withIds
+	}
+
+	nodecount, nodeswithIdcount := checkIDs()
+	if nodecount != 1 {
+		t.Fatalf("Unexpected node count (%d) in request on updating the nodebalancer with one node.", nodecount)
+	}
+	if nodeswithIdcount != 1 {
+		t.Fatalf("Expected Node ID to be set when updating the nodebalancer with the same node it had previously.")
+	}
+
+	f.ResetRequests()
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes2)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error while updated LB to have three nodes: %s", err)
+	}
+	nodecount, nodeswithIdcount = checkIDs()
+	if nodecount != 3 {
+		t.Fatalf("Unexpected node count (%d) in request on updating the nodebalancer with three nodes.", nodecount)
+	}
+	if nodeswithIdcount != 1 {
+		t.Fatalf("Expected ID to be set just on one node which was existing prior updating the node with three nodes, it is set on %d nodes", nodeswithIdcount)
 	}
 
 	f.ResetRequests()
+	svc.SetAnnotations(map[string]string{annotations.AnnLinodeHealthCheckAttempts: "4"})
 	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes2)
 	if err != nil {
 		t.Errorf("UpdateLoadBalancer returned an error while updated LB to have three nodes second time: %s", err)
@@ -642,6 +1119,101 @@ func testUpdateLoadBalancerAddNode(t *testing.T, client *linodego.Client, f *fak
 	}
 }
 
+func testUpdateLoadBalancerRequiredNodeConditions(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	networkReady := v1.NodeConditionType("example.com/NetworkReady")
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionTrue},
+					{Type: networkReady, Status: v1.ConditionTrue},
+				},
+				Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "127.0.0.1"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionTrue},
+					{Type: networkReady, Status: v1.ConditionFalse},
+				},
+				Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "127.0.0.2"}},
+			},
+		},
+	}
+
+	Options.RequiredNodeConditions = map[string]string{string(v1.NodeReady): "True", string(networkReady): "True"}
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		Options.RequiredNodeConditions = nil
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	backendAddresses := func(nbID int) []string {
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nbID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer configs: %v", err)
+		}
+		var addresses []string
+		for _, cfg := range cfgs {
+			nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nbID, cfg.ID, nil)
+			if err != nil {
+				t.Fatalf("error getting NodeBalancer nodes: %v", err)
+			}
+			for _, node := range nbNodes {
+				addresses = append(addresses, node.Address)
+			}
+		}
+		slices.Sort(addresses)
+		return addresses
+	}
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	if addresses := backendAddresses(nb.ID); !reflect.DeepEqual(addresses, []string{"127.0.0.1:30000"}) {
+		t.Errorf("expected only the node with the required conditions as backend, got %v", addresses)
+	}
+
+	nodes[1].Status.Conditions[1].Status = v1.ConditionTrue
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if addresses := backendAddresses(nb.ID); !reflect.DeepEqual(addresses, []string{"127.0.0.1:30000", "127.0.0.2:30000"}) {
+		t.Errorf("expected the node to be added as backend once it meets the required conditions, got %v", addresses)
+	}
+}
+
 func testUpdateLoadBalancerAddAnnotation(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -857,21 +1429,46 @@ func testUpdateLoadBalancerAddTags(t *testing.T, client *linodego.Client, _ *fak
 		t.Fatalf("failed to get NodeBalancer by status: %v", err)
 	}
 
-	expectedTags := append([]string{clusterName}, strings.Split(testTags, ",")...)
+	expectedTags := append(append([]string{clusterName}, strings.Split(testTags, ",")...), getOwnerTag(svc), configPortTagPrefix+"80")
 	observedTags := nb.Tags
 
 	if !reflect.DeepEqual(expectedTags, observedTags) {
 		t.Errorf("NodeBalancer tags mismatch: expected %v, got %v", expectedTags, observedTags)
 	}
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeLoadBalancerTags: "test,tags",
+	})
+	if err = lb.UpdateLoadBalancer(context.TODO(), clusterName, svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error while removing a tag: %s", err)
+	}
+	nb, err = lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	expectedTags = []string{clusterName, "test", "tags", getOwnerTag(svc), configPortTagPrefix + "80"}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch after removing a tag: expected %v, got %v", expectedTags, nb.Tags)
+	}
 }
 
-func testUpdateLoadBalancerAddTLSPort(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+func testUpdateLoadBalancerLabelTags(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	Options.NodeBalancerLabelTags = map[string]string{
+		"example.com/team":        "team",
+		"example.com/cost-center": "cost-center",
+	}
+	defer func() { Options.NodeBalancerLabelTags = nil }()
+
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: randString(),
 			UID:  "foobar123",
+			Labels: map[string]string{
+				"example.com/team": "payments",
+				"app":              "web",
+			},
 			Annotations: map[string]string{
-				annotations.AnnLinodeThrottle: "15",
+				annotations.AnnLinodeLoadBalancerTags: "fake",
 			},
 		},
 		Spec: v1.ServiceSpec{
@@ -899,185 +1496,52 @@ func testUpdateLoadBalancerAddTLSPort(t *testing.T, client *linodego.Client, _ *
 		},
 	}
 
-	extraPort := v1.ServicePort{
-		Name:     randString(),
-		Protocol: "TCP",
-		Port:     int32(443),
-		NodePort: int32(30001),
-	}
-
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	clusterName := "linodelb"
 
 	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), clusterName, svc)
 	}()
 
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
-	addTLSSecret(t, lb.kubeClient)
-
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), clusterName, svc, nodes)
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
 	svc.Status.LoadBalancer = *lbStatus
-
 	stubService(fakeClientset, svc)
-	svc.Spec.Ports = append(svc.Spec.Ports, extraPort)
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
-	})
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Fatalf("UpdateLoadBalancer returned an error while updated annotations: %s", err)
-	}
 
 	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	expectedTags := []string{clusterName, "fake", "team:payments", getOwnerTag(svc), configPortTagPrefix + "80"}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch after create: expected %v, got %v", expectedTags, nb.Tags)
 	}
 
-	cfgs, errConfigs := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
-	if errConfigs != nil {
-		t.Fatalf("error getting NodeBalancer configs: %v", errConfigs)
+	svc.Labels["example.com/team"] = "billing"
+	svc.Labels["example.com/cost-center"] = "cc-42"
+	if err = lb.UpdateLoadBalancer(context.TODO(), clusterName, svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error while updating labels: %s", err)
 	}
 
-	expectedPorts := map[int]struct{}{
-		80:  {},
-		443: {},
+	nb, err = lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	expectedTags = []string{clusterName, "fake", "cost-center:cc-42", "team:billing", getOwnerTag(svc), configPortTagPrefix + "80"}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch after update: expected %v, got %v", expectedTags, nb.Tags)
 	}
+}
 
-	observedPorts := make(map[int]struct{})
-
-	for _, cfg := range cfgs {
-		nodes, errNodes := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfg.ID, nil)
-		if errNodes != nil {
-			t.Errorf("error getting NodeBalancer nodes: %v", errNodes)
-		}
-
-		if len(nodes) == 0 {
-			t.Errorf("no nodes found for port %d", cfg.Port)
-		}
-
-		observedPorts[cfg.Port] = struct{}{}
-	}
-
-	if !reflect.DeepEqual(expectedPorts, observedPorts) {
-		t.Errorf("NodeBalancer ports mismatch: expected %v, got %v", expectedPorts, observedPorts)
-	}
-}
-
-func testUpdateLoadBalancerAddProxyProtocol(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
-	nodes := []*v1.Node{
-		{
-			Status: v1.NodeStatus{
-				Addresses: []v1.NodeAddress{
-					{
-						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.1",
-					},
-				},
-			},
-		},
-	}
-
-	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
-
-	for _, tc := range []struct {
-		name                string
-		proxyProtocolConfig linodego.ConfigProxyProtocol
-		invalidErr          bool
-	}{
-		{
-			name:                "with invalid Proxy Protocol",
-			proxyProtocolConfig: "bogus",
-			invalidErr:          true,
-		},
-		{
-			name:                "with none",
-			proxyProtocolConfig: linodego.ProxyProtocolNone,
-		},
-		{
-			name:                "with v1",
-			proxyProtocolConfig: linodego.ProxyProtocolV1,
-		},
-		{
-			name:                "with v2",
-			proxyProtocolConfig: linodego.ProxyProtocolV2,
-		},
-	} {
-		t.Run(tc.name, func(t *testing.T) {
-			svc := &v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        randString(),
-					UID:         "foobar123",
-					Annotations: map[string]string{},
-				},
-				Spec: v1.ServiceSpec{
-					Ports: []v1.ServicePort{
-						{
-							Name:     randString(),
-							Protocol: "tcp",
-							Port:     int32(80),
-							NodePort: int32(8080),
-						},
-					},
-				},
-			}
-
-			defer func() {
-				_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-			}()
-			nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
-				Region: lb.zone,
-			})
-			if err != nil {
-				t.Fatalf("failed to create NodeBalancer: %s", err)
-			}
-
-			svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nodeBalancer)
-			svc.ObjectMeta.SetAnnotations(map[string]string{
-				annotations.AnnLinodeDefaultProxyProtocol: string(tc.proxyProtocolConfig),
-			})
-
-			stubService(fakeClientset, svc)
-			if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
-				expectedErrMessage := fmt.Sprintf("invalid NodeBalancer proxy protocol value '%s'", tc.proxyProtocolConfig)
-				if tc.invalidErr && err.Error() == expectedErrMessage {
-					return
-				}
-				t.Fatalf("UpdateLoadBalancer returned an unexpected error while updated annotations: %s", err)
-				return
-			}
-			if tc.invalidErr {
-				t.Fatal("expected UpdateLoadBalancer to return an error")
-			}
-
-			nodeBalancerConfigs, err := client.ListNodeBalancerConfigs(context.TODO(), nodeBalancer.ID, nil)
-			if err != nil {
-				t.Fatalf("failed to get NodeBalancer: %s", err)
-			}
-
-			for _, config := range nodeBalancerConfigs {
-				proxyProtocol := config.ProxyProtocol
-				if proxyProtocol != tc.proxyProtocolConfig {
-					t.Errorf("expected ProxyProtocol to be %s; got %s", tc.proxyProtocolConfig, proxyProtocol)
-				}
-			}
-		})
-	}
-}
-
-func testUpdateLoadBalancerAddNewFirewall(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+func testUpdateLoadBalancerAdoptedTags(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: randString(),
 			UID:  "foobar123",
-			Annotations: map[string]string{
-				annotations.AnnLinodeThrottle: "15",
-			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
@@ -1107,72 +1571,168 @@ func testUpdateLoadBalancerAddNewFirewall(t *testing.T, client *linodego.Client,
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
 	fakeClientset := fake.NewSimpleClientset()
 	lb.kubeClient = fakeClientset
+	clusterName := "linodelb"
 
 	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+		Options.AdoptedTagsPolicy = ""
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), clusterName, svc)
 	}()
 
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	label := "my-nodebalancer"
+	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Label:  &label,
+		Region: lb.zone,
+		Tags:   []string{"team:platform", "managed-by-terraform"},
+	})
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+		t.Fatalf("failed to create NodeBalancer: %s", err)
+	}
+	svc.Annotations = map[string]string{
+		annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nodeBalancer.ID),
+	}
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), clusterName, svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
 	svc.Status.LoadBalancer = *lbStatus
 	stubService(fakeClientset, svc)
-	fwClient := firewall.LinodeClient{Client: client}
-	fw, err := fwClient.CreateFirewall(context.TODO(), linodego.FirewallCreateOptions{
-		Label: "test",
-		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
-			Action:      "ACCEPT",
-			Label:       "inbound-rule123",
-			Description: "inbound rule123",
-			Ports:       "4321",
-			Protocol:    linodego.TCP,
-			Addresses: linodego.NetworkAddresses{
-				IPv4: &[]string{"0.0.0.0/0"},
-			},
-		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
-	})
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Errorf("CreatingFirewall returned an error: %s", err)
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	expectedTags := []string{clusterName, configPortTagPrefix + "80", "team:platform", "managed-by-terraform"}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch after adoption: expected %v, got %v", expectedTags, nb.Tags)
 	}
-	defer func() {
-		_ = fwClient.DeleteFirewall(context.TODO(), fw)
-	}()
-
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
-	})
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	// reconciling again must not duplicate or drop the preserved tags
+	lb.reconciled.forget(svc)
+	if err = lb.UpdateLoadBalancer(context.TODO(), clusterName, svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	nb, err = lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error while updated annotations: %s", err)
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch after update: expected %v, got %v", expectedTags, nb.Tags)
 	}
 
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	Options.AdoptedTagsPolicy = adoptedTagsReplace
+	lb.reconciled.forget(svc)
+	if err = lb.UpdateLoadBalancer(context.TODO(), clusterName, svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	nb, err = lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	expectedTags = []string{clusterName, configPortTagPrefix + "80"}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch with the replace policy: expected %v, got %v", expectedTags, nb.Tags)
 	}
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	// a NodeBalancer without the owner tag which is not selected by the
+	// nodebalancer-id annotation, e.g. one created before the owner tag was
+	// introduced, is not adopted and keeps no stale tags
+	Options.AdoptedTagsPolicy = ""
+	delete(svc.Annotations, annotations.AnnLinodeNodeBalancerID)
+	staleTags := append(append([]string{}, expectedTags...), "stale-tag")
+	if _, err = client.UpdateNodeBalancer(context.TODO(), nb.ID, linodego.NodeBalancerUpdateOptions{Tags: &staleTags}); err != nil {
+		t.Fatalf("failed to update NodeBalancer: %s", err)
+	}
+	lb.reconciled.forget(svc)
+	if err = lb.UpdateLoadBalancer(context.TODO(), clusterName, svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	nb, err = lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
 	}
+	if !reflect.DeepEqual(expectedTags, nb.Tags) {
+		t.Errorf("NodeBalancer tags mismatch when not adopted: expected %v, got %v", expectedTags, nb.Tags)
+	}
+}
 
-	if len(firewalls) == 0 {
-		t.Fatalf("No attached firewalls found")
+func Test_getLabelTags(t *testing.T) {
+	Options.NodeBalancerLabelTags = map[string]string{
+		"example.com/team":        "team",
+		"example.com/cost-center": "cc",
 	}
+	defer func() { Options.NodeBalancerLabelTags = nil }()
 
-	if firewalls[0].ID != fw.ID {
-		t.Fatalf("Attached firewallID not matching with created firewall")
+	testcases := []struct {
+		name     string
+		labels   map[string]string
+		expected []string
+	}{
+		{
+			name:     "no labels",
+			expected: nil,
+		},
+		{
+			name:     "unmapped labels are ignored",
+			labels:   map[string]string{"app": "web"},
+			expected: nil,
+		},
+		{
+			name:     "empty label values are ignored",
+			labels:   map[string]string{"example.com/team": ""},
+			expected: nil,
+		},
+		{
+			name: "mapped labels are sorted by label",
+			labels: map[string]string{
+				"example.com/team":        "payments",
+				"example.com/cost-center": "42",
+			},
+			expected: []string{"cc:42", "team:payments"},
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Labels: test.labels}}
+			if tags := getLabelTags(svc); !reflect.DeepEqual(test.expected, tags) {
+				t.Errorf("expected %v, got %v", test.expected, tags)
+			}
+		})
 	}
 }
 
-// This will also test the firewall with >255 IPs
-func testUpdateLoadBalancerAddNewFirewallACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+func TestGetLoadBalancerTags(t *testing.T) {
+	Options.NodeBalancerLabelTags = map[string]string{"example.com/team": "team"}
+	defer func() { Options.NodeBalancerLabelTags = nil }()
+
+	lb := &loadbalancers{}
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: randString(),
-			UID:  "foobar123",
+			Labels: map[string]string{"example.com/team": "payments"},
+			Annotations: map[string]string{
+				annotations.AnnLinodeLoadBalancerTags: "prod, linodelb,prod,team:payments",
+			},
+		},
+	}
+	assert.Equal(t, []string{"linodelb", "prod", "team:payments"}, lb.GetLoadBalancerTags(context.TODO(), "linodelb", svc))
+
+	for _, value := range []string{"ab", strings.Repeat("a", maxTagLength+1)} {
+		svc.Annotations[annotations.AnnLinodeLoadBalancerTags] = "prod," + value
+		_, err := getAnnotationTags(svc)
+		assert.IsType(t, invalidAnnotationError{}, err, value)
+	}
+}
+
+func testUpdateLoadBalancerAuditTags(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            randString(),
+			UID:             "foobar123",
+			ResourceVersion: "100",
+			Annotations: map[string]string{
+				annotations.AnnLinodeAuditTags: "true",
+			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
@@ -1202,110 +1762,247 @@ func testUpdateLoadBalancerAddNewFirewallACL(t *testing.T, client *linodego.Clie
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
 	fakeClientset := fake.NewSimpleClientset()
 	lb.kubeClient = fakeClientset
+	clusterName := "linodelb"
 
 	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), clusterName, svc)
 	}()
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), clusterName, svc, nodes)
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
 	svc.Status.LoadBalancer = *lbStatus
 	stubService(fakeClientset, svc)
 
 	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	if len(nb.Tags) != 5 || nb.Tags[0] != clusterName || nb.Tags[1] != auditTagResourceVersionPrefix+"100" ||
+		!strings.HasPrefix(nb.Tags[2], auditTagAppliedAtPrefix) || nb.Tags[3] != getOwnerTag(svc) || nb.Tags[4] != configPortTagPrefix+"80" {
+		t.Fatalf("unexpected NodeBalancer tags after create: %v", nb.Tags)
 	}
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	svc.ResourceVersion = "101"
+	err = lb.UpdateLoadBalancer(context.TODO(), clusterName, svc, nodes)
 	if err != nil {
-		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
 	}
 
-	if len(firewalls) != 0 {
-		t.Fatalf("Firewalls attached when none specified")
+	nb, err = lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
 	}
+	if !slices.Contains(nb.Tags, auditTagResourceVersionPrefix+"101") || slices.Contains(nb.Tags, auditTagResourceVersionPrefix+"100") {
+		t.Errorf("expected NodeBalancer audit tags to be updated to resourceVersion 101, got %v", nb.Tags)
+	}
+}
 
-	var ipv4s []string
-	var ipv6s []string
-	i := 0
-	for i < 400 {
-		ipv4s = append(ipv4s, fmt.Sprintf("%d.%d.%d.%d", 192, rand.Int31n(255), rand.Int31n(255), rand.Int31n(255)))
-		i += 1
+func Test_eligibleBackendNodes(t *testing.T) {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+	ready := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "ready"},
+		Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
 	}
-	i = 0
-	for i < 300 {
-		ip := make([]byte, 16)
-		if _, err := cryptoRand.Read(ip); err != nil {
-			t.Fatalf("unable to read random bytes")
-		}
-		ipv6s = append(ipv6s, fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s",
-			hex.EncodeToString(ip[0:2]),
-			hex.EncodeToString(ip[2:4]),
-			hex.EncodeToString(ip[4:6]),
-			hex.EncodeToString(ip[6:8]),
-			hex.EncodeToString(ip[8:10]),
-			hex.EncodeToString(ip[10:12]),
-			hex.EncodeToString(ip[12:14]),
-			hex.EncodeToString(ip[14:16])))
-		i += 1
+	notReady := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "not-ready"},
+		Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}}},
 	}
-	acl := map[string]map[string][]string{
-		"allowList": {
-			"ipv4": ipv4s,
-			"ipv6": ipv6s,
+	noConditions := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "no-conditions"}}
+	deleted := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "deleted"},
+		Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: "ToBeDeletedByClusterAutoscaler", Effect: v1.TaintEffectNoSchedule}}},
+		Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}},
+	}
+	nodes := []*v1.Node{ready, notReady, noConditions, deleted}
+
+	defer func() {
+		Options.RequiredNodeConditions = nil
+		Options.ExcludedNodeTaints = nil
+	}()
+
+	testcases := []struct {
+		name       string
+		conditions map[string]string
+		taints     []string
+		expected   []*v1.Node
+	}{
+		{"no requirements", nil, nil, nodes},
+		{"ready required", map[string]string{"Ready": "True"}, nil, []*v1.Node{ready, deleted}},
+		{"taint excluded", nil, []string{"ToBeDeletedByClusterAutoscaler"}, []*v1.Node{ready, notReady, noConditions}},
+		{"unknown condition required", map[string]string{"Ready": "Unknown"}, nil, []*v1.Node{noConditions}},
+		{"ready required and taint excluded", map[string]string{"Ready": "True"}, []string{"ToBeDeletedByClusterAutoscaler"}, []*v1.Node{ready}},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			Options.RequiredNodeConditions = test.conditions
+			Options.ExcludedNodeTaints = test.taints
+			if eligible := eligibleBackendNodes(svc, nodes); !reflect.DeepEqual(eligible, test.expected) {
+				t.Errorf("expected eligible nodes %v, got %v", test.expected, eligible)
+			}
+		})
+	}
+}
+
+func Test_eligibleBackendNodesSelector(t *testing.T) {
+	poolA := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "pool-a", Labels: map[string]string{"lke.linode.com/pool-id": "1"}}}
+	poolB := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "pool-b", Labels: map[string]string{"lke.linode.com/pool-id": "2"}}}
+	unlabelled := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "unlabelled"}}
+	nodes := []*v1.Node{poolA, poolB, unlabelled}
+
+	defer func() { Options.BackendNodeSelector = "" }()
+
+	testcases := []struct {
+		name            string
+		clusterSelector string
+		annotations     map[string]string
+		expected        []*v1.Node
+	}{
+		{name: "no selector", expected: nodes},
+		{name: "cluster default pool", clusterSelector: "lke.linode.com/pool-id=1", expected: []*v1.Node{poolA}},
+		{
+			name:            "service selector overrides the cluster default",
+			clusterSelector: "lke.linode.com/pool-id=1",
+			annotations:     map[string]string{annotations.AnnLinodeBackendNodeSelector: "lke.linode.com/pool-id=2"},
+			expected:        []*v1.Node{poolB},
+		},
+		{
+			name:            "empty service selector uses all nodes",
+			clusterSelector: "lke.linode.com/pool-id=1",
+			annotations:     map[string]string{annotations.AnnLinodeBackendNodeSelector: ""},
+			expected:        nodes,
+		},
+		{
+			name:        "set based service selector",
+			annotations: map[string]string{annotations.AnnLinodeBackendNodeSelector: "lke.linode.com/pool-id in (1, 2)"},
+			expected:    []*v1.Node{poolA, poolB},
 		},
 	}
-	aclString, err := json.Marshal(acl)
-	if err != nil {
-		t.Fatalf("unable to marshal json acl")
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			Options.BackendNodeSelector = test.clusterSelector
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Annotations: test.annotations}}
+			if eligible := eligibleBackendNodes(svc, nodes); !reflect.DeepEqual(eligible, test.expected) {
+				t.Errorf("expected eligible nodes %v, got %v", test.expected, eligible)
+			}
+		})
 	}
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallACL: string(aclString),
+	t.Run("invalid service selector", func(t *testing.T) {
+		svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{annotations.AnnLinodeBackendNodeSelector: "pool-id in (1"}}}
+		if _, err := getBackendNodeSelector(svc); !stderrors.As(err, &invalidAnnotationError{}) {
+			t.Errorf("expected an invalid annotation error, got %v", err)
+		}
 	})
+}
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+func Test_getOwnedConfigPorts(t *testing.T) {
+	testcases := []struct {
+		name     string
+		tags     []string
+		expected map[int]bool
+	}{
+		{"no port tags", []string{"linodelb", ownerTagPrefix + "foobar123"}, nil},
+		{"port tags", []string{"linodelb", configPortTagPrefix + "80", configPortTagPrefix + "443"}, map[int]bool{80: true, 443: true}},
+		{"invalid port tag", []string{configPortTagPrefix + "http", configPortTagPrefix + "80"}, map[int]bool{80: true}},
 	}
 
-	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			if ports := getOwnedConfigPorts(test.tags); !reflect.DeepEqual(ports, test.expected) {
+				t.Errorf("expected owned ports %v, got %v", test.expected, ports)
+			}
+		})
 	}
+}
 
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
-	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
-	}
+func Test_getAuditTags(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
 
-	if len(firewallsNew) == 0 {
-		t.Fatalf("No firewalls found")
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			ResourceVersion: "42",
+			Annotations: map[string]string{
+				annotations.AnnLinodeAuditTags: "true",
+			},
+		},
 	}
 
-	if firewallsNew[0].Rules.InboundPolicy != "DROP" {
-		t.Errorf("expected DROP inbound policy, got %s", firewallsNew[0].Rules.InboundPolicy)
+	testcases := []struct {
+		name        string
+		service     *v1.Service
+		currentTags []string
+		expected    []string
+	}{
+		{
+			"audit tags disabled",
+			&v1.Service{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "42"}},
+			nil,
+			nil,
+		},
+		{
+			"no audit tags yet",
+			svc,
+			[]string{"linodelb"},
+			[]string{"ccm-rv:42", "ccm-applied:20240501T120000Z"},
+		},
+		{
+			"unchanged resourceVersion keeps timestamp",
+			svc,
+			[]string{"linodelb", "ccm-rv:42", "ccm-applied:" + earlier.Format(auditTagTimeFormat)},
+			[]string{"ccm-rv:42", "ccm-applied:20240501T110000Z"},
+		},
+		{
+			"changed resourceVersion refreshes timestamp",
+			svc,
+			[]string{"linodelb", "ccm-rv:41", "ccm-applied:" + earlier.Format(auditTagTimeFormat)},
+			[]string{"ccm-rv:42", "ccm-applied:20240501T120000Z"},
+		},
 	}
 
-	if len(firewallsNew[0].Rules.Inbound) != 4 {
-		t.Errorf("expected 4 rules, got %d", len(firewallsNew[0].Rules.Inbound))
+	t.Run("invalid value", func(t *testing.T) {
+		invalid := svc.DeepCopy()
+		invalid.Annotations[annotations.AnnLinodeAuditTags] = "ture"
+		if _, err := getAuditTags(invalid, nil, now); !stderrors.As(err, &invalidAnnotationError{}) {
+			t.Errorf("expected an invalid annotation error, got %v", err)
+		}
+	})
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			tags, err := getAuditTags(test.service, test.currentTags, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(tags, test.expected) {
+				t.Error("unexpected audit tags")
+				t.Logf("expected: %v", test.expected)
+				t.Logf("actual: %v", tags)
+			}
+		})
 	}
 }
 
-func testUpdateLoadBalancerDeleteFirewallRemoveACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+func testEnsureLoadBalancerCertExpiry(t *testing.T, client *linodego.Client, f *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: randString(),
-			UID:  "foobar123",
+			Name:      randString(),
+			Namespace: "default",
+			UID:       "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "expiring-tls-secret"}`,
+			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
 				{
 					Name:     randString(),
 					Protocol: "TCP",
-					Port:     int32(80),
+					Port:     int32(443),
 					NodePort: int32(30000),
 				},
 			},
@@ -1326,85 +2023,89 @@ func testUpdateLoadBalancerDeleteFirewallRemoveACL(t *testing.T, client *linodeg
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
-
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallACL: `{
-			"allowList": {
-				"ipv4": ["2.2.2.2"]
-			}
-		}`,
-	})
+	lb.kubeClient = fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
 
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	notAfter := time.Now().Add(48 * time.Hour)
+	cert, key := newTestCertificate(t, notAfter)
+	_, err := lb.kubeClient.CoreV1().Secrets(svc.Namespace).Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "expiring-tls-secret",
+		},
+		Data: map[string][]byte{
+			v1.TLSCertKey:       []byte(cert),
+			v1.TLSPrivateKeyKey: []byte(key),
+		},
+		Type: "kubernetes.io/tls",
+	}, metav1.CreateOptions{})
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+		t.Fatalf("failed to add TLS secret: %s", err)
 	}
-	svc.Status.LoadBalancer = *lbStatus
-	stubService(fakeClientset, svc)
 
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
-	}
+	Options.CertExpiryWarningWindow = 7 * 24 * time.Hour
+	defer func() { Options.CertExpiryWarningWindow = 0 }()
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	if len(firewalls) == 0 {
-		t.Fatalf("No firewalls attached")
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonCertificateExpiring) {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Error("expected a Warning event for the expiring certificate")
 	}
 
-	if firewalls[0].Rules.InboundPolicy != "DROP" {
-		t.Errorf("expected DROP inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+	value, found := getGaugeValue(t, "ccm_nodebalancer_cert_expiry_seconds", map[string]string{
+		"service": getServiceNn(svc),
+		"port":    "443",
+	})
+	if !found {
+		t.Fatal("expected ccm_nodebalancer_cert_expiry_seconds to be reported")
 	}
-
-	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected IP, got %v", fwIPs)
+	if value <= 0 || value > (48*time.Hour).Seconds() {
+		t.Errorf("expected cert expiry within 48h, got %v seconds", value)
 	}
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{})
-
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	// the expiry is re-evaluated when the update is skipped as nothing changed
+	certExpirySeconds.WithLabelValues(getServiceNn(svc), "443").Set(0)
+	stubService(lb.kubeClient.(*fake.Clientset), svc)
+	f.ResetRequests()
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
 	}
-
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
-	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
+	rx := regexp.MustCompile("/nodebalancers/[0-9]+/configs/[0-9]+/rebuild")
+	for request := range f.requests {
+		if rx.MatchString(request.Path) {
+			t.Error("expected the update to be skipped")
+		}
 	}
 
-	if len(firewallsNew) != 0 {
-		t.Fatalf("firewall's %d still attached", firewallsNew[0].ID)
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonCertificateExpiring) {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Error("expected a Warning event for the expiring certificate of the skipped update")
 	}
-}
 
-func testUpdateLoadBalancerUpdateFirewallRemoveACLaddID(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
-	svc := &v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: randString(),
-			UID:  "foobar123",
-		},
-		Spec: v1.ServiceSpec{
-			Ports: []v1.ServicePort{
-				{
-					Name:     randString(),
-					Protocol: "TCP",
-					Port:     int32(80),
-					NodePort: int32(30000),
-				},
-			},
-		},
+	value, _ = getGaugeValue(t, "ccm_nodebalancer_cert_expiry_seconds", map[string]string{
+		"service": getServiceNn(svc),
+		"port":    "443",
+	})
+	if value <= 0 || value > (48*time.Hour).Seconds() {
+		t.Errorf("expected cert expiry within 48h after the skipped update, got %v seconds", value)
 	}
+}
 
+func testEnsureLoadBalancerTLSKeyPair(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	nodes := []*v1.Node{
 		{
 			Status: v1.NodeStatus{
@@ -1418,387 +2119,819 @@ func testUpdateLoadBalancerUpdateFirewallRemoveACLaddID(t *testing.T, client *li
 		},
 	}
 
-	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
+	notAfter := time.Now().Add(365 * 24 * time.Hour)
+	cert, key := newTestCertificate(t, notAfter)
+	_, otherKey := newTestCertificate(t, notAfter)
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallACL: `{
-			"allowList": {
-				"ipv4": ["2.2.2.2"]
+	for _, test := range []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"matching pair", key, false},
+		{"mismatched pair", otherKey, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      randString(),
+					Namespace: "default",
+					UID:       "foobar123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
+					},
+				},
+				Spec: v1.ServiceSpec{
+					Ports: []v1.ServicePort{
+						{
+							Name:     randString(),
+							Protocol: "TCP",
+							Port:     int32(443),
+							NodePort: int32(30000),
+						},
+					},
+				},
 			}
-		}`,
-	})
-
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
-	}
-	svc.Status.LoadBalancer = *lbStatus
-	stubService(fakeClientset, svc)
-
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
-	}
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
-	if err != nil {
-		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
-	}
+			lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+			lb.kubeClient = fake.NewSimpleClientset()
+			recorder := record.NewFakeRecorder(10)
+			lb.eventRecorder = recorder
 
-	if len(firewalls) == 0 {
-		t.Fatalf("No firewalls attached")
-	}
+			_, err := lb.kubeClient.CoreV1().Secrets(svc.Namespace).Create(context.TODO(), &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "tls-secret",
+				},
+				Data: map[string][]byte{
+					v1.TLSCertKey:       []byte(cert),
+					v1.TLSPrivateKeyKey: []byte(test.key),
+				},
+				Type: "kubernetes.io/tls",
+			}, metav1.CreateOptions{})
+			if err != nil {
+				t.Fatalf("failed to add TLS secret: %s", err)
+			}
+			defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	if firewalls[0].Rules.InboundPolicy != "DROP" {
-		t.Errorf("expected DROP inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
-	}
+			_, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+			if !test.wantErr {
+				if err != nil {
+					t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+				}
+				return
+			}
 
-	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected IP, got %v", fwIPs)
+			if !stderrors.Is(err, errInvalidTLSKeyPair) {
+				t.Fatalf("expected errInvalidTLSKeyPair, got %v", err)
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonInvalidTLSKeyPair) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				t.Error("expected a Warning event for the mismatched key pair")
+			}
+		})
 	}
+}
 
-	fwClient := firewall.LinodeClient{Client: client}
-	fw, err := fwClient.CreateFirewall(context.TODO(), linodego.FirewallCreateOptions{
-		Label: "test",
-		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
-			Action:      "ACCEPT",
-			Label:       "inbound-rule123",
-			Description: "inbound rule123",
-			Ports:       "4321",
-			Protocol:    linodego.TCP,
-			Addresses: linodego.NetworkAddresses{
-				IPv4: &[]string{"0.0.0.0/0"},
+func testEnsureLoadBalancerServiceRemoved(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
 			},
-		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
-	})
-	if err != nil {
-		t.Errorf("Error creating firewall %s", err)
+		},
 	}
-	defer func() {
-		_ = fwClient.DeleteFirewall(context.TODO(), fw)
-	}()
-
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
-	})
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	newService := func() *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randString(),
+				Namespace: "default",
+				UID:       "foobar123",
+			},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+				Ports: []v1.ServicePort{
+					{
+						Name:     randString(),
+						Protocol: "TCP",
+						Port:     int32(80),
+						NodePort: int32(30000),
+					},
+				},
+			},
+		}
 	}
 
-	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	countNodeBalancers := func() int {
+		nbs, err := client.ListNodeBalancers(context.TODO(), nil)
+		if err != nil {
+			t.Fatalf("failed to list NodeBalancers: %s", err)
+		}
+		return len(nbs)
 	}
 
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	t.Run("removed before the NodeBalancer is created", func(t *testing.T) {
+		svc := newService()
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		lb.kubeClient = fake.NewSimpleClientset()
+		lb.serviceLister = corelisters.NewServiceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+
+		before := countNodeBalancers()
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, errServiceRemoved) {
+			t.Fatalf("expected errServiceRemoved, got %v", err)
+		}
+		if after := countNodeBalancers(); after != before {
+			t.Errorf("expected no NodeBalancer to be created, went from %d to %d", before, after)
+		}
+	})
+
+	t.Run("removed while the NodeBalancer is created", func(t *testing.T) {
+		svc := newService()
+		fakeClientset := fake.NewSimpleClientset(svc)
+		lookups := 0
+		fakeClientset.PrependReactor("get", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			lookups++
+			if lookups > 1 {
+				return true, nil, errors.NewNotFound(v1.Resource("services"), svc.Name)
+			}
+			return false, nil, nil
+		})
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		lb.kubeClient = fakeClientset
+		lb.serviceLister = corelisters.NewServiceLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+
+		before := countNodeBalancers()
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, errServiceRemoved) {
+			t.Fatalf("expected errServiceRemoved, got %v", err)
+		}
+		if lookups < 2 {
+			t.Errorf("expected the service to be looked up before and after creating the NodeBalancer, got %d lookups", lookups)
+		}
+		if after := countNodeBalancers(); after != before {
+			t.Errorf("expected the NodeBalancer created for the removed service to be deleted, went from %d to %d", before, after)
+		}
+	})
+
+	t.Run("not of type LoadBalancer anymore", func(t *testing.T) {
+		svc := newService()
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		if err := indexer.Add(svc); err != nil {
+			t.Fatal(err)
+		}
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		lb.kubeClient = fake.NewSimpleClientset()
+		lb.serviceLister = corelisters.NewServiceLister(indexer)
+
+		if lb.serviceRemoved(context.TODO(), svc) {
+			t.Error("expected an existing LoadBalancer service not to be reported as removed")
+		}
+
+		current := svc.DeepCopy()
+		current.Spec.Type = v1.ServiceTypeClusterIP
+		if err := indexer.Update(current); err != nil {
+			t.Fatal(err)
+		}
+		if !lb.serviceRemoved(context.TODO(), svc) {
+			t.Error("expected a service which is no longer of type LoadBalancer to be reported as removed")
+		}
+	})
+}
+
+func Test_getCertExpiry(t *testing.T) {
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+	cert, _ := newTestCertificate(t, notAfter)
+
+	expiry, err := getCertExpiry(cert)
 	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
+		t.Fatal(err)
 	}
-
-	if len(firewallsNew) == 0 {
-		t.Fatalf("No attached firewalls found")
+	if !expiry.Equal(notAfter) {
+		t.Errorf("expected expiry %s, got %s", notAfter, expiry)
 	}
 
-	if firewallsNew[0].Rules.InboundPolicy != "ACCEPT" {
-		t.Errorf("expected ACCEPT inbound policy, got %s", firewallsNew[0].Rules.InboundPolicy)
+	if _, err := getCertExpiry("not a certificate"); err == nil {
+		t.Error("expected an error for invalid certificate data")
 	}
+}
 
-	fwIPs = firewallsNew[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected 2.2.2.2, got %v", fwIPs)
-	}
+func Test_buildCertChain(t *testing.T) {
+	leaf, intermediate, key := newTestCertificateChain(t)
+	_, otherKey := newTestCertificate(t, time.Now().Add(time.Hour))
 
-	if firewallsNew[0].ID != fw.ID {
-		t.Errorf("Firewall ID does not match what we created, something wrong.")
+	for _, test := range []struct {
+		name    string
+		cert    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"single certificate", leaf, key, strings.TrimSpace(leaf), false},
+		{"leaf first", leaf + intermediate, key, strings.TrimSpace(leaf + intermediate), false},
+		{"intermediate first", intermediate + leaf, key, strings.TrimSpace(leaf + intermediate), false},
+		{"mismatched key", leaf + intermediate, otherKey, "", true},
+		{"no certificate", "not a certificate", key, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			chain, err := buildCertChain(test.cert, test.key)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if chain != test.want {
+				t.Errorf("expected chain %q, got %q", test.want, chain)
+			}
+		})
 	}
 }
 
-func testUpdateLoadBalancerUpdateFirewallRemoveIDaddACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+func Test_buildNodeBalancerConfigInvalidPortConfig(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	lb := &loadbalancers{eventRecorder: recorder}
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: randString(),
-			UID:  "foobar123",
-		},
-		Spec: v1.ServiceSpec{
-			Ports: []v1.ServicePort{
-				{
-					Name:     randString(),
-					Protocol: "TCP",
-					Port:     int32(80),
-					NodePort: int32(30000),
-				},
+			Annotations: map[string]string{
+				annotations.AnnLinodePortConfigPrefix + "80": `{ "protocol": "http", "proxy-protocol": "v1" }`,
 			},
 		},
 	}
 
-	nodes := []*v1.Node{
-		{
-			Status: v1.NodeStatus{
-				Addresses: []v1.NodeAddress{
-					{
-						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.1",
-					},
-				},
-			},
-		},
-	}
+	_, err := lb.buildNodeBalancerConfig(context.TODO(), svc, 80, 1)
+	assert.IsType(t, invalidAnnotationError{}, err)
+	event := <-recorder.Events
+	assert.Contains(t, event, eventReasonInvalidAnnotation)
+	assert.Contains(t, event, "proxy protocol is not supported for http")
+}
 
-	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
+func Test_verifyCertHostnames(t *testing.T) {
+	cert, _ := newTestCertificate(t, time.Now().Add(time.Hour))
 
-	fwClient := firewall.LinodeClient{Client: client}
-	fw, err := fwClient.CreateFirewall(context.TODO(), linodego.FirewallCreateOptions{
-		Label: "test",
-		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
-			Action:      "ACCEPT",
-			Label:       "inbound-rule123",
-			Description: "inbound rule123",
-			Ports:       "4321",
-			Protocol:    linodego.TCP,
-			Addresses: linodego.NetworkAddresses{
-				IPv4: &[]string{"0.0.0.0/0"},
+	assert.NoError(t, verifyCertHostnames(cert, nil))
+	assert.NoError(t, verifyCertHostnames(cert, []string{"linode.test", "www.linode.test"}))
+	assert.ErrorIs(t, verifyCertHostnames(cert, []string{"linode.test", "example.com"}), errTLSHostnameMismatch)
+	assert.Error(t, verifyCertHostnames("not a certificate", []string{"linode.test"}))
+}
+
+func Test_buildNodeBalancerConfigSNI(t *testing.T) {
+	cert, key := newTestCertificate(t, time.Now().Add(time.Hour))
+	kubeClient := fake.NewSimpleClientset()
+	for _, name := range []string{"tls-secret", "other-tls-secret"} {
+		_, err := kubeClient.CoreV1().Secrets("default").Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data: map[string][]byte{
+				v1.TLSCertKey:       []byte(cert),
+				v1.TLSPrivateKeyKey: []byte(key),
 			},
-		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
-	})
-	if err != nil {
-		t.Errorf("Error creating firewall %s", err)
+			Type: "kubernetes.io/tls",
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("failed to add TLS secret: %s", err)
+		}
 	}
-	defer func() {
-		_ = fwClient.DeleteFirewall(context.TODO(), fw)
-	}()
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
-	})
+	for _, test := range []struct {
+		name        string
+		tlsSecrets  string
+		eventReason string
+		err         error
+	}{
+		{"hostnames sharing a secret", `{ "linode.test": "tls-secret", "www.linode.test": "tls-secret" }`, "", nil},
+		{"hostnames with different secrets", `{ "linode.test": "tls-secret", "www.linode.test": "other-tls-secret" }`, eventReasonUnsupportedSetting, errMultipleTLSSecrets},
+		{"hostname not covered by the certificate", `{ "linode.test": "tls-secret", "example.com": "tls-secret" }`, eventReasonTLSHostnameMismatch, errTLSHostnameMismatch},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			lb := &loadbalancers{kubeClient: kubeClient, eventRecorder: recorder}
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      randString(),
+					Namespace: "default",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": fmt.Sprintf(`{ "protocol": "https", "tls-secrets": %s }`, test.tlsSecrets),
+					},
+				},
+			}
 
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+			config, err := lb.buildNodeBalancerConfig(context.TODO(), svc, 443, 1)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				assert.Len(t, recorder.Events, 1)
+				assert.Contains(t, <-recorder.Events, test.eventReason)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(cert), config.SSLCert)
+			assert.Empty(t, recorder.Events)
+		})
 	}
-	svc.Status.LoadBalancer = *lbStatus
-	stubService(fakeClientset, svc)
+}
 
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+func Test_addTLSCertBundle(t *testing.T) {
+	leaf, intermediate, key := newTestCertificateChain(t)
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      randString(),
+			Namespace: "default",
+		},
 	}
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	lb := &loadbalancers{kubeClient: fake.NewSimpleClientset(), eventRecorder: record.NewFakeRecorder(10)}
+	_, err := lb.kubeClient.CoreV1().Secrets(svc.Namespace).Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tls-secret",
+		},
+		Data: map[string][]byte{
+			v1.TLSCertKey:       []byte(leaf + intermediate),
+			v1.TLSPrivateKeyKey: []byte(key),
+		},
+		Type: "kubernetes.io/tls",
+	}, metav1.CreateOptions{})
 	if err != nil {
-		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+		t.Fatalf("failed to add TLS secret: %s", err)
 	}
 
-	if len(firewalls) == 0 {
-		t.Fatalf("No firewalls attached")
+	nbConfig := linodego.NodeBalancerConfig{Port: 443, Protocol: linodego.ProtocolHTTPS}
+	if err = lb.addTLSCert(context.TODO(), svc, &nbConfig, portConfig{Port: 443, TLSSecretName: "tls-secret"}); err != nil {
+		t.Fatal(err)
 	}
-
-	if firewalls[0].Rules.InboundPolicy != "ACCEPT" {
-		t.Errorf("expected ACCEPT inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+	if nbConfig.SSLCert != strings.TrimSpace(leaf+intermediate) {
+		t.Errorf("expected the full chain to be sent, got %q", nbConfig.SSLCert)
 	}
+	if nbConfig.SSLKey != strings.TrimSpace(key) {
+		t.Errorf("expected the leaf key to be sent, got %q", nbConfig.SSLKey)
+	}
+}
 
-	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected IP, got %v", fwIPs)
+func Test_addTLSCertForbidden(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      randString(),
+			Namespace: "default",
+		},
 	}
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallACL: `{
-			"allowList": {
-				"ipv4": ["2.2.2.2"]
-			}
-		}`,
+
+	fakeClientset := fake.NewSimpleClientset()
+	fakeClientset.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewForbidden(v1.Resource("secrets"), "tls-secret", stderrors.New("not allowed"))
 	})
+	recorder := record.NewFakeRecorder(10)
+	lb := &loadbalancers{kubeClient: fakeClientset, eventRecorder: recorder}
+
+	nbConfig := linodego.NodeBalancerConfig{Port: 443, Protocol: linodego.ProtocolHTTPS}
+	err := lb.addTLSCert(context.TODO(), svc, &nbConfig, portConfig{Port: 443, TLSSecretName: "tls-secret"})
+	assert.ErrorIs(t, err, errTLSSecretUnreadable)
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, eventReasonTLSSecretUnreadable)
+}
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+func Test_validateHTTPSPorts(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   bool
+	}{
+		{name: "tcp port"},
+		{
+			name:        "https port config with a TLS secret",
+			annotations: map[string]string{annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret" }`},
+		},
+		{
+			name:        "https port config with SNI secrets",
+			annotations: map[string]string{annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secrets": { "linode.test": "tls-secret" } }`},
+		},
+		{
+			name:        "https port config without a TLS secret",
+			annotations: map[string]string{annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https" }`},
+			expectErr:   true,
+		},
+		{
+			name:        "https default protocol without a TLS secret",
+			annotations: map[string]string{annotations.AnnLinodeDefaultProtocol: "https"},
+			expectErr:   true,
+		},
+		{
+			name: "https default protocol overridden for the port",
+			annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol:          "https",
+				annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "http" }`,
+			},
+		},
 	}
 
-	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: randString(), Annotations: test.annotations},
+				Spec:       v1.ServiceSpec{Ports: []v1.ServicePort{{Port: 443, Protocol: v1.ProtocolTCP}}},
+			}
+			err := validateHTTPSPorts(svc)
+			if test.expectErr {
+				assert.IsType(t, invalidAnnotationError{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
 	}
+}
 
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+// newTestCertificateChain returns a PEM encoded leaf certificate, the
+// intermediate CA certificate which signed it and the leaf's private key.
+func newTestCertificateChain(t *testing.T) (string, string, string) {
+	t.Helper()
+
+	caKey, err := rsa.GenerateKey(cryptoRand.Reader, 2048)
 	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
+		t.Fatal(err)
 	}
-
-	if len(firewallsNew) == 0 {
-		t.Fatalf("No attached firewalls found")
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "intermediate.linode.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
 	}
-
-	if firewallsNew[0].Rules.InboundPolicy != "DROP" {
-		t.Errorf("expected DROP inbound policy, got %s", firewallsNew[0].Rules.InboundPolicy)
+	caDER, err := x509.CreateCertificate(cryptoRand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	fwIPs = firewallsNew[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected 2.2.2.2, got %v", fwIPs)
+	key, err := rsa.GenerateKey(cryptoRand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
 	}
-
-	if firewallsNew[0].ID != fw.ID {
-		t.Errorf("Firewall ID does not match, something wrong.")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "linode.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(cryptoRand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	leaf := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	intermediate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(leaf), string(intermediate), string(keyPEM)
 }
 
-func testUpdateLoadBalancerUpdateFirewallACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
-	svc := &v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: randString(),
-			UID:  "foobar123",
-			Annotations: map[string]string{
-				annotations.AnnLinodeCloudFirewallACL: `{
-					"allowList": {
-						"ipv4": ["2.2.2.2"]
-					}
-				}`,
-			},
-		},
-		Spec: v1.ServiceSpec{
-			Ports: []v1.ServicePort{
-				{
-					Name:     randString(),
-					Protocol: "TCP",
-					Port:     int32(80),
-					NodePort: int32(30000),
+func Test_reportTransferUsage(t *testing.T) {
+	registerMetrics()
+	Options.NodeBalancerTransferQuota = 1000
+	Options.TransferWarningThreshold = 0.8
+	defer func() {
+		Options.NodeBalancerTransferQuota = 0
+		Options.TransferWarningThreshold = 0
+	}()
+
+	for _, test := range []struct {
+		name      string
+		total     *float64
+		wantEvent bool
+	}{
+		{"usage not reported", nil, false},
+		{"usage below the threshold", ptr.To(500.0), false},
+		{"usage above the threshold", ptr.To(900.0), true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      randString(),
+					Namespace: "default",
 				},
-			},
-		},
+			}
+			recorder := record.NewFakeRecorder(10)
+			lb := &loadbalancers{eventRecorder: recorder}
+
+			lb.reportTransferUsage(svc, &linodego.NodeBalancer{ID: 1, Transfer: linodego.NodeBalancerTransfer{Total: test.total}})
+
+			value, found := getGaugeValue(t, "ccm_nodebalancer_transfer_used_megabytes", map[string]string{"service": getServiceNn(svc)})
+			if test.total == nil {
+				if found {
+					t.Error("expected no transfer usage to be reported")
+				}
+			} else if !found || value != *test.total {
+				t.Errorf("expected transfer usage %v, got %v (found: %t)", *test.total, value, found)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !test.wantEvent {
+					t.Errorf("unexpected event: %s", event)
+				} else if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonTransferQuota) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				if test.wantEvent {
+					t.Error("expected a Warning event for the transfer usage")
+				}
+			}
+		})
 	}
+}
+
+func Test_warnBelowMinBackends(t *testing.T) {
+	Options.MinBackends = 2
+	defer func() { Options.MinBackends = 0 }()
 
 	nodes := []*v1.Node{
-		{
-			Status: v1.NodeStatus{
-				Addresses: []v1.NodeAddress{
-					{
-						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.1",
-					},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+	}
+
+	for _, test := range []struct {
+		name        string
+		annotations map[string]string
+		nodes       []*v1.Node
+		wantEvent   bool
+	}{
+		{"below the default minimum", nil, nodes[:1], true},
+		{"at the default minimum", nil, nodes, false},
+		{"below the annotated minimum", map[string]string{annotations.AnnLinodeMinBackends: "3"}, nodes, true},
+		{"minimum disabled by annotation", map[string]string{annotations.AnnLinodeMinBackends: "0"}, nodes[:1], false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        randString(),
+					Namespace:   "default",
+					Annotations: test.annotations,
 				},
-			},
-		},
+			}
+			recorder := record.NewFakeRecorder(10)
+			lb := &loadbalancers{eventRecorder: recorder}
+
+			lb.warnBelowMinBackends(svc, &linodego.NodeBalancer{ID: 1}, test.nodes)
+
+			select {
+			case event := <-recorder.Events:
+				if !test.wantEvent || !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonBelowMinBackends) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				if test.wantEvent {
+					t.Error("expected a Warning event for the missing backends")
+				}
+			}
+		})
 	}
 
-	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
+	t.Run("negative annotation is invalid", func(t *testing.T) {
+		svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{annotations.AnnLinodeMinBackends: "-1"},
+		}}
+		if _, err := getMinBackends(svc); err == nil {
+			t.Error("expected an error for a negative minimum")
+		}
+	})
+}
 
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+// newTestCertificate returns a PEM encoded self-signed certificate for
+// linode.test and its subdomains expiring at notAfter, and its private key.
+func newTestCertificate(t *testing.T, notAfter time.Time) (string, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(cryptoRand.Reader, 2048)
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+		t.Fatal(err)
 	}
-	svc.Status.LoadBalancer = *lbStatus
-	stubService(fakeClientset, svc)
-
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "linode.test"},
+		DNSNames:     []string{"linode.test", "*.linode.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(cryptoRand.Reader, template, template, &key.PublicKey, key)
 	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+		t.Fatal(err)
 	}
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return string(cert), string(keyPEM)
+}
+
+// getGaugeValue returns the value of the gauge with the given name and labels
+// from the legacy registry.
+func getGaugeValue(t *testing.T, name string, labels map[string]string) (float64, bool) {
+	t.Helper()
+
+	families, err := legacyregistry.DefaultGatherer.Gather()
 	if err != nil {
-		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+		t.Fatal(err)
 	}
-
-	if len(firewalls) == 0 {
-		t.Fatalf("No firewalls attached")
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] == label.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return metric.GetGauge().GetValue(), true
+			}
+		}
 	}
+	return 0, false
+}
 
-	if firewalls[0].Rules.InboundPolicy != "DROP" {
-		t.Errorf("expected DROP inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+func testUpdateLoadBalancerTLSSecretMissing(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
 	}
 
-	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected 2.2.2.2, got %v", fwIPs)
+	newService := func() *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randString(),
+				UID:  "foobar123",
+				Annotations: map[string]string{
+					annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
+				},
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Name:     randString(),
+						Protocol: "TCP",
+						Port:     int32(443),
+						NodePort: int32(30000),
+					},
+				},
+			},
+		}
 	}
 
-	fmt.Printf("got %v", fwIPs)
+	defer func() { Options.TLSSecretMissingPolicy = "" }()
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallACL: `{
-			"allowList": {
-				"ipv4": ["2.2.2.2"],
-				"ipv6": ["dead:beef::/128"]
+	for _, tc := range []struct {
+		policy    string
+		expectErr bool
+	}{
+		{policy: "", expectErr: false},
+		{policy: tlsSecretMissingKeepLastGood, expectErr: false},
+		{policy: tlsSecretMissingFail, expectErr: true},
+	} {
+		t.Run("policy "+tc.policy, func(t *testing.T) {
+			Options.TLSSecretMissingPolicy = tc.policy
+			svc := newService()
+
+			lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+			fakeClientset := fake.NewSimpleClientset()
+			lb.kubeClient = fakeClientset
+			recorder := record.NewFakeRecorder(10)
+			lb.eventRecorder = recorder
+			addTLSSecret(t, lb.kubeClient)
+
+			lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+			if err != nil {
+				t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 			}
-		}`,
-	})
+			svc.Status.LoadBalancer = *lbStatus
+			defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+			stubService(fakeClientset, svc)
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
-	}
+			if err = fakeClientset.CoreV1().Secrets("").Delete(context.TODO(), "tls-secret", metav1.DeleteOptions{}); err != nil {
+				t.Fatalf("failed to delete TLS secret: %s", err)
+			}
+			// drain events from the initial reconcile
+			for len(recorder.Events) > 0 {
+				<-recorder.Events
+			}
+			// as done by the secret controller on deletion of the secret
+			lb.reconciled.forget(svc)
 
-	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
-	}
+			err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected UpdateLoadBalancer to fail for the missing TLS secret")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+			}
 
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
-	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
-	}
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonTLSSecretMissing) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				t.Error("expected a Warning event for the missing TLS secret")
+			}
 
-	if len(firewallsNew) == 0 {
-		t.Fatalf("No attached firewalls found")
+			nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+			if err != nil {
+				t.Fatalf("error getting NodeBalancer configs: %v", err)
+			}
+			if len(cfgs) != 1 || cfgs[0].Protocol != linodego.ProtocolHTTPS {
+				t.Errorf("expected the https config to be kept, got %v", cfgs)
+			}
+		})
 	}
 
-	fwIPs = firewallsNew[0].Rules.Inbound[0].Addresses.IPv4
-	if fwIPs == nil {
-		t.Errorf("expected non nil IPv4, got %v", fwIPs)
-	}
+	t.Run("no last known good certificate", func(t *testing.T) {
+		Options.TLSSecretMissingPolicy = tlsSecretMissingKeepLastGood
+		svc := newService()
 
-	if len(*fwIPs) != 1 {
-		t.Errorf("expected one IPv4, got %v", fwIPs)
-	}
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		lb.kubeClient = fake.NewSimpleClientset()
+		lb.eventRecorder = record.NewFakeRecorder(10)
 
-	if firewallsNew[0].Rules.Inbound[0].Addresses.IPv6 == nil {
-		t.Errorf("expected non nil IPv6, got %v", firewallsNew[0].Rules.Inbound[0].Addresses.IPv6)
-	}
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); err == nil {
+			t.Error("expected EnsureLoadBalancer to fail without a certificate")
+		}
+	})
 
-	if len(*firewallsNew[0].Rules.Inbound[0].Addresses.IPv6) != 1 {
-		t.Errorf("expected one IPv6, got %v", firewallsNew[0].Rules.Inbound[0].Addresses.IPv6)
-	}
-}
+	t.Run("secret created after the service", func(t *testing.T) {
+		Options.TLSSecretMissingPolicy = tlsSecretMissingKeepLastGood
+		Options.TLSSecretRetryInterval = time.Second
+		defer func() { Options.TLSSecretRetryInterval = 0 }()
+		svc := newService()
+
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		fakeClientset := fake.NewSimpleClientset()
+		lb.kubeClient = fakeClientset
+		lb.eventRecorder = record.NewFakeRecorder(10)
+
+		for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+			var retryErr *api.RetryError
+			if !stderrors.As(err, &retryErr) {
+				t.Fatalf("expected EnsureLoadBalancer to requeue until the TLS secret exists, got %v", err)
+			}
+			if retryErr.RetryAfter() != expected {
+				t.Errorf("expected a requeue after %s, got %s", expected, retryErr.RetryAfter())
+			}
+		}
 
-func testUpdateLoadBalancerUpdateFirewall(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
-	firewallCreateOpts := linodego.FirewallCreateOptions{
-		Label: "test",
-		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
-			Action:      "ACCEPT",
-			Label:       "inbound-rule123",
-			Description: "inbound rule123",
-			Ports:       "4321",
-			Protocol:    linodego.TCP,
-			Addresses: linodego.NetworkAddresses{
-				IPv4: &[]string{"0.0.0.0/0"},
-			},
-		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
-	}
+		addTLSSecret(t, lb.kubeClient)
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		svc.Status.LoadBalancer = *lbStatus
+		defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+		if _, retrying := lb.tlsSecretRetries.attempts[svc.UID]; retrying {
+			t.Error("expected the TLS secret retries to be forgotten once the reconcile succeeds")
+		}
+
+		nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer configs: %v", err)
+		}
+		if len(cfgs) != 1 || cfgs[0].Protocol != linodego.ProtocolHTTPS {
+			t.Errorf("expected an https config, got %v", cfgs)
+		}
+	})
+}
 
+func testEnsureLoadBalancerUnmanagedNamespace(t *testing.T, client *linodego.Client, f *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: randString(),
-			UID:  "foobar123",
-			Annotations: map[string]string{
-				annotations.AnnLinodeThrottle: "15",
-			},
+			Name:      randString(),
+			Namespace: "legacy",
+			UID:       "foobar123",
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
@@ -1825,103 +2958,295 @@ func testUpdateLoadBalancerUpdateFirewall(t *testing.T, client *linodego.Client,
 		},
 	}
 
+	Options.ServiceNamespaces = []string{"default"}
+	defer func() { Options.ServiceNamespaces = nil }()
+
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
+	lb.kubeClient = fake.NewSimpleClientset()
+	f.ResetRequests()
 
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
+	if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, cloudprovider.ImplementedElsewhere) {
+		t.Errorf("expected EnsureLoadBalancer to return ImplementedElsewhere, got %v", err)
+	}
+	if err := lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, cloudprovider.ImplementedElsewhere) {
+		t.Errorf("expected UpdateLoadBalancer to return ImplementedElsewhere, got %v", err)
+	}
+	if _, exists, err := lb.GetLoadBalancer(context.TODO(), "linodelb", svc); err != nil || exists {
+		t.Errorf("expected GetLoadBalancer to report no load balancer, got exists=%t err=%v", exists, err)
+	}
+	if err := lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+		t.Errorf("EnsureLoadBalancerDeleted returned an error: %s", err)
+	}
 
-	fwClient := firewall.LinodeClient{Client: client}
-	fw, err := fwClient.CreateFirewall(context.TODO(), firewallCreateOpts)
-	if err != nil {
-		t.Errorf("Error creating firewall %s", err)
+	if len(f.requests) != 0 {
+		t.Errorf("expected no Linode API requests for an unmanaged namespace, got %d", len(f.requests))
 	}
-	defer func() {
-		_ = fwClient.DeleteFirewall(context.TODO(), fw)
-	}()
+}
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
+func testEnsureLoadBalancerDeletedUnmanagedNamespace(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	newService := func(uid types.UID, ip string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      randString(),
+				Namespace: "legacy",
+				UID:       uid,
+			},
+			Status: v1.ServiceStatus{
+				LoadBalancer: v1.LoadBalancerStatus{
+					Ingress: []v1.LoadBalancerIngress{{IP: ip}},
+				},
+			},
+		}
+	}
+
+	Options.ServiceNamespaces = []string{"default"}
+	defer func() { Options.ServiceNamespaces = nil }()
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+
+	t.Run("owned NodeBalancer is deleted", func(t *testing.T) {
+		svc := newService("owned123", "")
+		nb, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+			Region: "us-west",
+			Tags:   []string{"linodelb", getOwnerTag(svc)},
+		})
+		if err != nil {
+			t.Fatalf("failed to create NodeBalancer: %s", err)
+		}
+		svc.Status.LoadBalancer.Ingress[0].IP = *nb.IPv4
+
+		if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+			t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+		}
+		if _, err = client.GetNodeBalancer(context.TODO(), nb.ID); err == nil {
+			t.Errorf("expected NodeBalancer (%d) to be deleted", nb.ID)
+		}
 	})
-	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+
+	t.Run("NodeBalancer of another CCM is left alone", func(t *testing.T) {
+		nb, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+			Region: "us-west",
+			Tags:   []string{"other-cluster"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create NodeBalancer: %s", err)
+		}
+		defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nb.ID) }()
+		svc := newService("foreign123", *nb.IPv4)
+
+		if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+			t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+		}
+		if _, err = client.GetNodeBalancer(context.TODO(), nb.ID); err != nil {
+			t.Errorf("expected NodeBalancer (%d) to be kept: %s", nb.ID, err)
+		}
+	})
+
+	t.Run("owned NodeBalancer of another cluster is left alone", func(t *testing.T) {
+		svc := newService("othercluster123", "")
+		nb, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+			Region: "us-west",
+			Tags:   []string{"other-cluster", getOwnerTag(svc)},
+		})
+		if err != nil {
+			t.Fatalf("failed to create NodeBalancer: %s", err)
+		}
+		defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nb.ID) }()
+		svc.Status.LoadBalancer.Ingress[0].IP = *nb.IPv4
+
+		if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+			t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+		}
+		if _, err = client.GetNodeBalancer(context.TODO(), nb.ID); err != nil {
+			t.Errorf("expected NodeBalancer (%d) of another cluster to be kept: %s", nb.ID, err)
+		}
+	})
+
+	t.Run("firewall of the owned NodeBalancer is deleted", func(t *testing.T) {
+		svc := newService("firewall123", "")
+		svc.Annotations = map[string]string{
+			annotations.AnnLinodeCloudFirewallACL: `{"allowList": {"ipv4": ["2.2.2.2"]}}`,
+		}
+		svc.Spec.Ports = []v1.ServicePort{{Name: "test", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)}}
+		svc.Status = v1.ServiceStatus{}
+		firewalls := len(f.fw)
+
+		// the NodeBalancer was created before the namespace was excluded
+		Options.ServiceNamespaces = nil
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{nodeInRegion("node-1", "us-west")})
+		Options.ServiceNamespaces = []string{"default"}
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		svc.Status.LoadBalancer = *lbStatus
+		if len(f.fw) != firewalls+1 {
+			t.Fatalf("expected a firewall to be created for the ACL, got %d", len(f.fw)-firewalls)
+		}
+
+		if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+			t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+		}
+		if len(f.fw) != firewalls {
+			t.Errorf("expected the firewall created for the ACL to be deleted, %d remain", len(f.fw)-firewalls)
+		}
+	})
+}
+
+func testEnsureLoadBalancerStaleIngress(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
 	}
-	svc.Status.LoadBalancer = *lbStatus
-	stubService(fakeClientset, svc)
 
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	nb, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: "us-west",
+		Tags:   []string{"linodelb"},
+	})
 	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+		t.Fatalf("failed to create NodeBalancer: %s", err)
 	}
+	defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nb.ID) }()
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
-	if err != nil {
-		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      randString(),
+			Namespace: "default",
+			UID:       "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{
+				// the address of a NodeBalancer which no longer exists comes first
+				Ingress: []v1.LoadBalancerIngress{{IP: "203.0.113.10"}, {IP: *nb.IPv4}},
+			},
+		},
 	}
 
-	if len(firewalls) == 0 {
-		t.Fatalf("No firewalls attached")
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset(svc)
+
+	found, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("expected the NodeBalancer to be found past the stale entry, got: %s", err)
+	}
+	if found.ID != nb.ID {
+		t.Fatalf("expected NodeBalancer (%d), got (%d)", nb.ID, found.ID)
 	}
 
-	if fw.ID != firewalls[0].ID {
-		t.Fatalf("Attached firewallID not matching with created firewall")
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	want := makeLoadBalancerStatus(svc, nb).Ingress
+	if !reflect.DeepEqual(lbStatus.Ingress, want) {
+		t.Errorf("expected ingress %v, got %v", want, lbStatus.Ingress)
 	}
 
-	firewallCreateOpts.Label = "test2"
-	firewallNew, err := fwClient.CreateFirewall(context.TODO(), firewallCreateOpts)
+	updated, err := lb.kubeClient.CoreV1().Services(svc.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
 	if err != nil {
-		t.Fatalf("Error in creating firewall %s", err)
+		t.Fatal(err)
 	}
-	defer func() {
-		_ = fwClient.DeleteFirewall(context.TODO(), firewallNew)
-	}()
+	if !reflect.DeepEqual(updated.Status.LoadBalancer.Ingress, want) {
+		t.Errorf("expected the stale ingress to be replaced with %v, got %v", want, updated.Status.LoadBalancer.Ingress)
+	}
+}
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(firewallNew.ID),
+func testEnsureLoadBalancerAnnotateNodeBalancerID(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      randString(),
+			Namespace: "default",
+			UID:       "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	Options.AnnotateNodeBalancerID = true
+	defer func() { Options.AnnotateNodeBalancerID = false }()
+
+	fakeClientset := fake.NewSimpleClientset(svc)
+	patches := 0
+	fakeClientset.PrependReactor("patch", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "" {
+			patches++
+		}
+		return false, nil, nil
 	})
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fakeClientset
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+		t.Fatal(err)
 	}
-
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	updated, err := fakeClientset.CoreV1().Services(svc.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
 	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
+		t.Fatal(err)
 	}
-
-	if len(firewallsNew) == 0 {
-		t.Fatalf("No attached firewalls found")
+	if id := updated.Annotations[annotations.AnnLinodeNodeBalancerStatusID]; id != strconv.Itoa(nb.ID) {
+		t.Errorf("expected the service to be annotated with NodeBalancer ID %d, got %q", nb.ID, id)
 	}
-
-	if firewallsNew[0].ID != firewallNew.ID {
-		t.Fatalf("Attached firewallID not matching with created firewall")
+	if patches != 1 {
+		t.Errorf("expected a single patch of the service, got %d", patches)
 	}
-}
 
-func testUpdateLoadBalancerDeleteFirewallRemoveID(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
-	firewallCreateOpts := linodego.FirewallCreateOptions{
-		Label: "test",
-		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
-			Action:      "ACCEPT",
-			Label:       "inbound-rule123",
-			Description: "inbound rule123",
-			Ports:       "4321",
-			Protocol:    linodego.TCP,
-			Addresses: linodego.NetworkAddresses{
-				IPv4: &[]string{"0.0.0.0/0"},
-			},
-		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
+	// the annotation does not change the reconciled state and is not rewritten
+	updated.Status.LoadBalancer = *lbStatus
+	if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", updated, nodes); err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
+	if patches != 1 {
+		t.Errorf("expected the unchanged annotation not to be rewritten, got %d patches", patches)
+	}
+}
 
+func testEnsureLoadBalancerAdoptAfterTimeout(t *testing.T, client *linodego.Client, f *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: randString(),
@@ -1952,1069 +3277,6081 @@ func testUpdateLoadBalancerDeleteFirewallRemoveID(t *testing.T, client *linodego
 		},
 	}
 
-	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	fakeClientset := fake.NewSimpleClientset()
-	lb.kubeClient = fakeClientset
+	Options.NodeBalancerProvisionTimeout = time.Minute
+	defer func() { Options.NodeBalancerProvisionTimeout = 0 }()
 
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
 
-	fwClient := firewall.LinodeClient{Client: client}
-	fw, err := fwClient.CreateFirewall(context.TODO(), firewallCreateOpts)
+	// the NodeBalancer created by the attempt which timed out
+	nb, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: "us-west",
+		Tags:   []string{"linodelb", getOwnerTag(svc)},
+	})
 	if err != nil {
-		t.Errorf("Error in creating firewall %s", err)
+		t.Fatalf("failed to create NodeBalancer: %s", err)
 	}
-	defer func() {
-		_ = fwClient.DeleteFirewall(context.TODO(), fw)
-	}()
-
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
-	})
+	defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nb.ID) }()
 
+	f.ResetRequests()
 	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
-	svc.Status.LoadBalancer = *lbStatus
-	stubService(fakeClientset, svc)
 
-	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
-	if err != nil {
-		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	for req := range f.requests {
+		if req.Method == http.MethodPost && req.Path == "/nodebalancers" {
+			t.Error("expected the existing NodeBalancer to be adopted rather than a new one created")
+		}
+	}
+	if ingressAddress(lbStatus.Ingress) != *nb.IPv4 {
+		t.Errorf("expected ingress %s of the adopted NodeBalancer, got %v", *nb.IPv4, lbStatus.Ingress)
 	}
 
-	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	adopted, err := client.GetNodeBalancer(context.TODO(), nb.ID)
 	if err != nil {
-		t.Errorf("Error in listing firewalls %s", err)
+		t.Fatalf("failed to get NodeBalancer: %s", err)
 	}
-
-	if len(firewalls) == 0 {
-		t.Fatalf("No firewalls attached")
+	if !slices.Contains(adopted.Tags, getOwnerTag(svc)) {
+		t.Errorf("expected the owner tag to be kept on the adopted NodeBalancer, got %v", adopted.Tags)
 	}
+}
 
-	if fw.ID != firewalls[0].ID {
-		t.Fatalf("Attached firewallID not matching with created firewall")
+func Test_createNodeBalancerProvisioningTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mc := mocks.NewMockClient(ctrl)
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       "foobar123",
+		},
 	}
 
-	svc.ObjectMeta.SetAnnotations(map[string]string{})
+	Options.NodeBalancerProvisionTimeout = 10 * time.Millisecond
+	defer func() { Options.NodeBalancerProvisionTimeout = 0 }()
 
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
-	}
+	mc.EXPECT().CreateNodeBalancer(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+		func(ctx context.Context, opts linodego.NodeBalancerCreateOptions) (*linodego.NodeBalancer, error) {
+			if !slices.Contains(opts.Tags, getOwnerTag(svc)) {
+				t.Errorf("expected NodeBalancer to be created with owner tag, got %v", opts.Tags)
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
 
-	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
-	if err != nil {
-		t.Fatalf("failed to List Firewalls %s", err)
+	lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+
+	_, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, lb.zone, nil)
+	if !stderrors.Is(err, errProvisioningTimeout) {
+		t.Fatalf("expected a provisioning timeout error, got %v", err)
 	}
 
-	if len(firewallsNew) != 0 {
-		t.Fatalf("firewall's %d still attached", firewallsNew[0].ID)
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonProvisioningTimeout) {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Error("expected a Warning event for the provisioning timeout")
 	}
 }
 
-func testUpdateLoadBalancerAddNodeBalancerID(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+func testUpdateLoadBalancerDrainRemovedBackends(t *testing.T, client *linodego.Client, f *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        randString(),
-			UID:         "foobar123",
-			Annotations: map[string]string{},
+			Name: randString(),
+			UID:  "foobar123",
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
 				{
 					Name:     randString(),
-					Protocol: "http",
+					Protocol: "TCP",
 					Port:     int32(80),
-					NodePort: int32(8080),
+					NodePort: int32(30000),
 				},
 			},
 		},
 	}
 
-	nodes := []*v1.Node{
-		{
+	newNode := func(name, address string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
 			Status: v1.NodeStatus{
 				Addresses: []v1.NodeAddress{
 					{
 						Type:    v1.NodeInternalIP,
-						Address: "127.0.0.1",
+						Address: address,
 					},
 				},
 			},
-		},
+		}
+	}
+	nodes := []*v1.Node{
+		newNode("node-1", "127.0.0.1"),
+		newNode("node-2", "127.0.0.2"),
+		newNode("node-3", "127.0.0.3"),
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
-
 	fakeClientset := fake.NewSimpleClientset()
 	lb.kubeClient = fakeClientset
 
-	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
-		Region: lb.zone,
-	})
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Fatalf("failed to create NodeBalancer: %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
 
-	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nodeBalancer)
-
-	newNodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
-		Region: lb.zone,
-	})
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
 	if err != nil {
-		t.Fatalf("failed to create new NodeBalancer: %s", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	stubService(fakeClientset, svc)
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeNodeBalancerID: strconv.Itoa(newNodeBalancer.ID),
-	})
-	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Errorf("UpdateLoadBalancer returned an error while updated annotations: %s", err)
-	}
+	Options.BackendDrainPeriod = 50 * time.Millisecond
+	defer func() { Options.BackendDrainPeriod = 0 }()
 
-	clusterName := strings.TrimPrefix(svc.Namespace, "kube-system-")
-	lbStatus, _, err := lb.GetLoadBalancer(context.TODO(), clusterName, svc)
-	if err != nil {
-		t.Errorf("GetLoadBalancer returned an error: %s", err)
+	countDrains := func() int {
+		drains := 0
+		for req := range f.requests {
+			if req.Method == http.MethodPut && strings.Contains(req.Path, "/nodes/") && strings.Contains(req.Body, `"mode":"drain"`) {
+				drains++
+			}
+		}
+		return drains
+	}
+	listNodes := func() []linodego.NodeBalancerNode {
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer configs: %v", err)
+		}
+		var nbNodes []linodego.NodeBalancerNode
+		for _, cfg := range cfgs {
+			if cfg.NodeBalancerID != nb.ID {
+				continue
+			}
+			if nbNodes, err = client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfg.ID, nil); err != nil {
+				t.Fatalf("error getting NodeBalancer nodes: %v", err)
+			}
+		}
+		return nbNodes
 	}
 
-	expectedLBStatus := makeLoadBalancerStatus(svc, newNodeBalancer)
-	if !reflect.DeepEqual(expectedLBStatus, lbStatus) {
-		t.Errorf("LoadBalancer status mismatch: expected %v, got %v", expectedLBStatus, lbStatus)
+	// the update is requeued rather than waiting for the backends to drain
+	f.ResetRequests()
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes[:1])
+	var retryErr *api.RetryError
+	if !stderrors.As(err, &retryErr) {
+		t.Fatalf("expected a RetryError while the backends drain, got %v", err)
+	}
+	if retryErr.RetryAfter() <= 0 || retryErr.RetryAfter() > Options.BackendDrainPeriod {
+		t.Errorf("expected to be requeued within %s, got %s", Options.BackendDrainPeriod, retryErr.RetryAfter())
+	}
+	if drains := countDrains(); drains != 2 {
+		t.Errorf("expected 2 backends to be drained, got %d", drains)
+	}
+	if nbNodes := listNodes(); len(nbNodes) != 3 {
+		t.Errorf("expected draining backends to be kept, got %v", nbNodes)
 	}
 
-	if !fakeAPI.didRequestOccur(http.MethodDelete, fmt.Sprintf("/nodebalancers/%d", nodeBalancer.ID), "") {
-		t.Errorf("expected old NodeBalancer to have been deleted")
+	// the update requeued once the drain period is over removes them
+	time.Sleep(Options.BackendDrainPeriod)
+	f.ResetRequests()
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes[:1]); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if drains := countDrains(); drains != 0 {
+		t.Errorf("expected drained backends not to be drained again, got %d drains", drains)
+	}
+	if nbNodes := listNodes(); len(nbNodes) != 1 || nbNodes[0].Address != "127.0.0.1:30000" {
+		t.Errorf("expected drained backends to be removed, got %v", nbNodes)
 	}
 }
 
-func Test_getConnectionThrottle(t *testing.T) {
-	testcases := []struct {
-		name     string
-		service  *v1.Service
-		expected int
-	}{
-		{
-			"throttle not specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        randString(),
-					UID:         "abc123",
-					Annotations: map[string]string{},
-				},
-			},
-			0,
+func testUpdateLoadBalancerDrainUnschedulableNodes(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
 		},
-		{
-			"throttle value is a string",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeThrottle: "foo",
-					},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
 				},
 			},
-			0,
 		},
-		{
-			"throttle value is less than 0",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeThrottle: "-123",
+	}
+
+	newNode := func(name, address string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: address,
 					},
 				},
 			},
-			0,
+		}
+	}
+	nodes := []*v1.Node{
+		newNode("node-1", "127.0.0.1"),
+		newNode("node-2", "127.0.0.2"),
+	}
+
+	Options.UnschedulableNodesPolicy = unschedulableNodesDrain
+	defer func() { Options.UnschedulableNodesPolicy = "" }()
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	backendModes := func() map[string]linodego.NodeMode {
+		t.Helper()
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer configs: %v", err)
+		}
+		modes := map[string]linodego.NodeMode{}
+		for _, cfg := range cfgs {
+			if cfg.NodeBalancerID != nb.ID {
+				continue
+			}
+			nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfg.ID, nil)
+			if err != nil {
+				t.Fatalf("error getting NodeBalancer nodes: %v", err)
+			}
+			for _, node := range nbNodes {
+				modes[node.Address] = node.Mode
+			}
+		}
+		return modes
+	}
+
+	nodes[1].Spec.Unschedulable = true
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	expected := map[string]linodego.NodeMode{"127.0.0.1:30000": linodego.ModeAccept, "127.0.0.2:30000": linodego.ModeDrain}
+	if modes := backendModes(); !reflect.DeepEqual(modes, expected) {
+		t.Errorf("expected the backend of the cordoned node to be drained, got %v", modes)
+	}
+
+	nodes[1].Spec.Unschedulable = false
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	expected["127.0.0.2:30000"] = linodego.ModeAccept
+	if modes := backendModes(); !reflect.DeepEqual(modes, expected) {
+		t.Errorf("expected the backend of the uncordoned node to accept connections, got %v", modes)
+	}
+}
+
+func testUpdateLoadBalancerConfigCountMetric(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	registerMetrics()
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
 		},
-		{
-			"throttle value is valid",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeThrottle: "1",
-					},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     "http",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
 				},
 			},
-			1,
 		},
+	}
+	nodes := []*v1.Node{
 		{
-			"throttle value is too high",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeThrottle: "21",
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
 					},
 				},
 			},
-			20,
 		},
 	}
 
-	for _, test := range testcases {
-		t.Run(test.name, func(t *testing.T) {
-			connThrottle := getConnectionThrottle(test.service)
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
 
-			if test.expected != connThrottle {
-				t.Fatalf("expected throttle value (%d) does not match actual value (%d)", test.expected, connThrottle)
-			}
-		})
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	labels := map[string]string{"service": getServiceNn(svc), "nodebalancer_id": strconv.Itoa(nb.ID)}
+
+	expectConfigCount := func(expected float64) {
+		t.Helper()
+		if value, found := getGaugeValue(t, "ccm_nodebalancer_config_count", labels); !found || value != expected {
+			t.Errorf("expected config count %v, got %v (found: %t)", expected, value, found)
+		}
+	}
+	expectConfigCount(1)
+
+	svc.Spec.Ports = append(svc.Spec.Ports, v1.ServicePort{
+		Name:     "https",
+		Protocol: "TCP",
+		Port:     int32(443),
+		NodePort: int32(30001),
+	})
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	expectConfigCount(2)
+
+	svc.Spec.Ports = svc.Spec.Ports[1:]
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	expectConfigCount(1)
+
+	if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+		t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+	}
+	if _, found := getGaugeValue(t, "ccm_nodebalancer_config_count", labels); found {
+		t.Error("expected the config count to be removed with the NodeBalancer")
 	}
 }
 
-func Test_getPortConfig(t *testing.T) {
-	testcases := []struct {
-		name               string
-		service            *v1.Service
-		expectedPortConfig portConfig
-		err                error
-	}{
-		{
-			"default no proxy protocol specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-				},
-			},
-			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolNone},
-			nil,
-		},
+func testEnsureLoadBalancerLoadBalancerIP(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	nodes := []*v1.Node{
 		{
-			"default proxy protocol specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProxyProtocol: string(linodego.ProxyProtocolV2),
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
 					},
 				},
 			},
-			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolV2},
-			nil,
 		},
-		{
-			"port specific proxy protocol specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProxyProtocol:     string(linodego.ProxyProtocolV2),
-						annotations.AnnLinodePortConfigPrefix + "443": fmt.Sprintf(`{"proxy-protocol": "%s"}`, linodego.ProxyProtocolV1),
-					},
-				},
+	}
+
+	newService := func(loadBalancerIP string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randString(),
+				UID:  "foobar123",
 			},
-			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolV1},
-			nil,
-		},
-		{
-			"default invalid proxy protocol",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProxyProtocol: "invalid",
+			Spec: v1.ServiceSpec{
+				LoadBalancerIP: loadBalancerIP,
+				Ports: []v1.ServicePort{
+					{
+						Name:     randString(),
+						Protocol: "TCP",
+						Port:     int32(80),
+						NodePort: int32(30000),
 					},
 				},
 			},
-			portConfig{},
-			fmt.Errorf("invalid NodeBalancer proxy protocol value '%s'", "invalid"),
-		},
-		{
-			"default no protocol specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
+		}
+	}
+
+	owned, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: "us-west",
+		Tags:   []string{"linodelb"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create NodeBalancer: %s", err)
+	}
+	defer func() { _ = client.DeleteNodeBalancer(context.TODO(), owned.ID) }()
+
+	foreign, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: "us-west",
+		Tags:   []string{"other-cluster"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create NodeBalancer: %s", err)
+	}
+	defer func() { _ = client.DeleteNodeBalancer(context.TODO(), foreign.ID) }()
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+
+	t.Run("matching loadBalancerIP adopts the NodeBalancer", func(t *testing.T) {
+		svc := newService(*owned.IPv4)
+		f.ResetRequests()
+
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		if ingressAddress(lbStatus.Ingress) != *owned.IPv4 {
+			t.Errorf("expected ingress %s, got %v", *owned.IPv4, lbStatus.Ingress)
+		}
+		for req := range f.requests {
+			if req.Method == http.MethodPost && req.Path == "/nodebalancers" {
+				t.Error("expected the existing NodeBalancer to be adopted rather than a new one created")
+			}
+		}
+	})
+
+	t.Run("non-matching loadBalancerIP returns an error", func(t *testing.T) {
+		svc := newService("203.0.113.10")
+		f.ResetRequests()
+
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); err == nil || !strings.Contains(err.Error(), "203.0.113.10") {
+			t.Errorf("expected an error naming the loadBalancerIP, got %v", err)
+		}
+		for req := range f.requests {
+			if req.Method == http.MethodPost && req.Path == "/nodebalancers" {
+				t.Error("expected no NodeBalancer to be created")
+			}
+		}
+	})
+
+	t.Run("loadBalancerIP of another cluster's NodeBalancer returns an error", func(t *testing.T) {
+		svc := newService(*foreign.IPv4)
+
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); err == nil {
+			t.Error("expected an error for a NodeBalancer not owned by the cluster")
+		}
+	})
+
+	t.Run("loadBalancerIP of another Service's NodeBalancer returns an error", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		lb.eventRecorder = recorder
+		defer func() { lb.eventRecorder = nil }()
+
+		first := newService("")
+		first.UID = "first-uid"
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", first, nodes)
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		first.Status.LoadBalancer = *lbStatus
+		stubService(lb.kubeClient.(*fake.Clientset), first)
+		defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", first) }()
+		ip := ingressAddress(lbStatus.Ingress)
+
+		second := newService(ip)
+		second.UID = "second-uid"
+		f.ResetRequests()
+		_, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", second, nodes)
+		assert.ErrorIs(t, err, errNodeBalancerOwned)
+		for req := range f.requests {
+			if req.Method == http.MethodPost && req.Path == "/nodebalancers" {
+				t.Error("expected no NodeBalancer to be created")
+			}
+			if req.Method == http.MethodPut {
+				t.Errorf("expected the NodeBalancer of the first service to be left alone, got %s %s", req.Method, req.Path)
+			}
+		}
+
+		select {
+		case event := <-recorder.Events:
+			assert.True(t, strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonNodeBalancerOwned), "unexpected event: %s", event)
+		default:
+			t.Error("expected a Warning event for the NodeBalancer of another service")
+		}
+
+		// the Service the NodeBalancer was created for may still select it
+		own := newService(ip)
+		own.Name = first.Name
+		own.UID = first.UID
+		if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", own, nodes); err != nil {
+			t.Errorf("EnsureLoadBalancer returned an error for the owner of the NodeBalancer: %s", err)
+		}
+
+		// the NodeBalancer of a deleted Service, e.g. a preserved one, may be
+		// adopted
+		if err = lb.kubeClient.CoreV1().Services(first.Namespace).Delete(context.TODO(), first.Name, metav1.DeleteOptions{}); err != nil {
+			t.Fatalf("failed to delete service: %s", err)
+		}
+		if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", second, nodes); err != nil {
+			t.Errorf("EnsureLoadBalancer returned an error for the NodeBalancer of a deleted service: %s", err)
+		}
+	})
+}
+
+func testEnsureLoadBalancerHostNetworking(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeHostNetworking: "true",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:       randString(),
+					Protocol:   "TCP",
+					Port:       int32(80),
+					TargetPort: intstr.FromInt32(8080),
+					NodePort:   int32(30000),
 				},
 			},
-			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolNone},
-
-			nil,
 		},
+	}
+
+	nodes := []*v1.Node{
 		{
-			"default tcp protocol specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProtocol: "tcp",
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
 					},
 				},
 			},
-			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolNone},
-			nil,
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatalf("error getting NodeBalancer configs: %v", err)
+	}
+	var nbNodes []linodego.NodeBalancerNode
+	for _, cfg := range cfgs {
+		if cfg.NodeBalancerID != nb.ID {
+			continue
+		}
+		if nbNodes, err = client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfg.ID, nil); err != nil {
+			t.Fatalf("error getting NodeBalancer nodes: %v", err)
+		}
+	}
+	if len(nbNodes) != 1 || nbNodes[0].Address != "127.0.0.1:8080" {
+		t.Errorf("expected backend addressing the container port, got %v", nbNodes)
+	}
+}
+
+func Test_getBackendPort(t *testing.T) {
+	hostNetworking := map[string]string{annotations.AnnLinodeHostNetworking: "true"}
+
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		port        v1.ServicePort
+		expected    int32
+		expectErr   bool
+	}{
+		{
+			name:     "node port by default",
+			port:     v1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080), NodePort: 30000},
+			expected: 30000,
 		},
 		{
-			"default capitalized protocol specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProtocol: "HTTP",
-					},
+			name:        "target port with host networking",
+			annotations: hostNetworking,
+			port:        v1.ServicePort{Port: 80, TargetPort: intstr.FromInt32(8080), NodePort: 30000},
+			expected:    8080,
+		},
+		{
+			name:        "service port when target port is unset",
+			annotations: hostNetworking,
+			port:        v1.ServicePort{Port: 80, NodePort: 30000},
+			expected:    80,
+		},
+		{
+			name:        "named target port is rejected",
+			annotations: hostNetworking,
+			port:        v1.ServicePort{Port: 80, TargetPort: intstr.FromString("http"), NodePort: 30000},
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Annotations: tc.annotations}}
+			port, err := getBackendPort(svc, tc.port)
+			if tc.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if port != tc.expected {
+				t.Errorf("expected port %d, got %d", tc.expected, port)
+			}
+		})
+	}
+}
+
+func testUpdateLoadBalancerUnchangedService(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       randString(),
+			UID:        "foobar123",
+			Generation: 1,
+			Annotations: map[string]string{
+				annotations.AnnLinodeThrottle: "15",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
 				},
 			},
-			portConfig{Port: 443, Protocol: "http", ProxyProtocol: linodego.ProxyProtocolNone},
-			nil,
 		},
-		{
-			"default invalid protocol",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProtocol: "invalid",
+	}
+
+	newNode := func(name, address string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: address,
 					},
 				},
 			},
-			portConfig{},
-			fmt.Errorf("invalid protocol: %q specified", "invalid"),
+		}
+	}
+	nodes := []*v1.Node{newNode("node-1", "127.0.0.1"), newNode("node-2", "127.0.0.2")}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
+
+	mutatingRequests := func() []fakeRequest {
+		var requests []fakeRequest
+		for request := range f.requests {
+			if request.Method != http.MethodGet {
+				requests = append(requests, request)
+			}
+		}
+		return requests
+	}
+
+	for _, reconcile := range []struct {
+		name string
+		f    func() error
+	}{
+		{name: "EnsureLoadBalancer", f: func() error {
+			_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+			return err
+		}},
+		{name: "UpdateLoadBalancer", f: func() error {
+			return lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		}},
+	} {
+		f.ResetRequests()
+		if err = reconcile.f(); err != nil {
+			t.Fatalf("%s returned an error: %s", reconcile.name, err)
+		}
+		if requests := mutatingRequests(); len(requests) != 0 {
+			t.Errorf("expected %s of an unchanged service to make no mutating requests, got %v", reconcile.name, requests)
+		}
+	}
+
+	// nodes given in a different order are still the same set
+	f.ResetRequests()
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{nodes[1], nodes[0]}); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if requests := mutatingRequests(); len(requests) != 0 {
+		t.Errorf("expected reordered nodes to make no mutating requests, got %v", requests)
+	}
+
+	f.ResetRequests()
+	nodes = append(nodes, newNode("node-3", "127.0.0.3"))
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if len(mutatingRequests()) == 0 {
+		t.Error("expected a changed node set to update the NodeBalancer")
+	}
+
+	f.ResetRequests()
+	svc.Annotations[annotations.AnnLinodeThrottle] = "10"
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if len(mutatingRequests()) == 0 {
+		t.Error("expected changed annotations to update the NodeBalancer")
+	}
+
+	f.ResetRequests()
+	svc.Generation = 2
+	svc.Spec.Ports[0].NodePort = 30001
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if len(mutatingRequests()) == 0 {
+		t.Error("expected a new service generation to update the NodeBalancer")
+	}
+}
+
+func testUpdateLoadBalancerDeleteOrphanedBackends(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       randString(),
+			UID:        "foobar123",
+			Generation: 1,
 		},
-		{
-			"port config falls back to default",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProtocol:          "http",
-						annotations.AnnLinodePortConfigPrefix + "443": `{}`,
-					},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
 				},
 			},
-			portConfig{Port: 443, Protocol: "http", ProxyProtocol: linodego.ProxyProtocolNone},
-			nil,
 		},
+	}
+
+	nodes := []*v1.Node{
 		{
-			"port config capitalized protocol",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "HTTp" }`,
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
 					},
 				},
 			},
-			portConfig{Port: 443, Protocol: "http", ProxyProtocol: linodego.ProxyProtocolNone},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil || len(cfgs) != 1 {
+		t.Fatalf("expected a single NodeBalancer config, got %v (error: %v)", cfgs, err)
+	}
+
+	// seed the backends a partially failed reconcile could leave behind
+	for id, address := range map[int]string{90001: "127.0.0.2:30000", 90002: "127.0.0.1:30000"} {
+		f.nbn[strconv.Itoa(id)] = &linodego.NodeBalancerNode{
+			ID:             id,
+			Address:        address,
+			Label:          "stale",
+			Mode:           linodego.ModeAccept,
+			NodeBalancerID: nb.ID,
+			ConfigID:       cfgs[0].ID,
+		}
+	}
+
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfgs[0].ID, nil)
+	if err != nil {
+		t.Fatalf("error getting NodeBalancer nodes: %v", err)
+	}
+	if len(nbNodes) != 1 || nbNodes[0].Address != "127.0.0.1:30000" {
+		t.Errorf("expected only backend 127.0.0.1:30000 to remain, got %v", nbNodes)
+	}
+}
+
+func testUpdateLoadBalancerRetainHealthyBackend(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       randString(),
+			UID:        "foobar123",
+			Generation: 1,
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	newNode := func(name, address string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: address,
+					},
+				},
+			},
+		}
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{newNode("node-1", "127.0.0.1")})
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil || len(cfgs) != 1 {
+		t.Fatalf("expected a single NodeBalancer config, got %v (error: %v)", cfgs, err)
+	}
+
+	markUp := func(address string) {
+		for _, n := range f.nbn {
+			if n.ConfigID == cfgs[0].ID && n.Address == address {
+				n.Status = nodeBalancerNodeUp
+			}
+		}
+	}
+	backendAddresses := func() []string {
+		nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfgs[0].ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer nodes: %v", err)
+		}
+		addresses := make([]string, 0, len(nbNodes))
+		for _, n := range nbNodes {
+			addresses = append(addresses, n.Address)
+		}
+		slices.Sort(addresses)
+		return addresses
+	}
+
+	// the only healthy backend is replaced by one which has not passed its health checks yet
+	markUp("127.0.0.1:30000")
+	replaced := []*v1.Node{newNode("node-2", "127.0.0.2")}
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, replaced); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if got, want := backendAddresses(), []string{"127.0.0.1:30000", "127.0.0.2:30000"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the healthy backend to be kept alongside the new one, got %v, want %v", got, want)
+	}
+
+	// once the new backend is healthy, the retained one is removed
+	markUp("127.0.0.2:30000")
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, replaced); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if got, want := backendAddresses(), []string{"127.0.0.2:30000"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected only the new backend to remain, got %v, want %v", got, want)
+	}
+}
+
+func testEnsureLoadBalancerMinTLSVersion(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	newHTTPSService := func(minTLSVersion string) *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randString(),
+				UID:  "foobar123",
+				Annotations: map[string]string{
+					annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret" }`,
+					annotations.AnnLinodeMinTLSVersion:            minTLSVersion,
+				},
+			},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Name:     randString(),
+						Protocol: "TCP",
+						Port:     int32(443),
+						NodePort: int32(30000),
+					},
+				},
+			},
+		}
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+	addTLSSecret(t, lb.kubeClient)
+
+	t.Run("supported version", func(t *testing.T) {
+		svc := newHTTPSService("1.1")
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		svc.Status.LoadBalancer = *lbStatus
+		defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+		nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+		if err != nil {
+			t.Fatalf("failed to get NodeBalancer by status: %v", err)
+		}
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer configs: %v", err)
+		}
+		for _, cfg := range cfgs {
+			if cfg.NodeBalancerID == nb.ID && cfg.CipherSuite != linodego.CipherLegacy {
+				t.Errorf("expected cipher suite %q, got %q", linodego.CipherLegacy, cfg.CipherSuite)
+			}
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		svc := newHTTPSService("1.3")
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); err == nil {
+			t.Error("expected EnsureLoadBalancer to fail for an unsupported minimum TLS version")
+		}
+	})
+}
+
+func Test_getCipherSuite(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		expected    linodego.ConfigCipher
+		expectErr   bool
+	}{
+		{name: "not specified", expected: ""},
+		{name: "TLS 1.0", annotations: map[string]string{annotations.AnnLinodeMinTLSVersion: "1.0"}, expected: linodego.CipherLegacy},
+		{name: "TLS 1.1", annotations: map[string]string{annotations.AnnLinodeMinTLSVersion: "1.1"}, expected: linodego.CipherLegacy},
+		{name: "TLS 1.2", annotations: map[string]string{annotations.AnnLinodeMinTLSVersion: "1.2"}, expected: linodego.CipherRecommended},
+		{name: "TLS 1.3 cannot be enforced", annotations: map[string]string{annotations.AnnLinodeMinTLSVersion: "1.3"}, expectErr: true},
+		{name: "invalid version", annotations: map[string]string{annotations.AnnLinodeMinTLSVersion: "tls12"}, expectErr: true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			cipher, err := getCipherSuite(svc)
+			if test.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if cipher != test.expected {
+				t.Errorf("expected cipher suite %q, got %q", test.expected, cipher)
+			}
+		})
+	}
+}
+
+func Test_validatePortProtocol(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		protocol    v1.Protocol
+		expectErr   bool
+	}{
+		{name: "TCP port with default protocol", protocol: v1.ProtocolTCP},
+		{name: "unset port protocol defaults to TCP", protocol: ""},
+		{
+			name:        "TCP port with http protocol",
+			annotations: map[string]string{annotations.AnnLinodeDefaultProtocol: "http"},
+			protocol:    v1.ProtocolTCP,
+		},
+		{
+			name:        "TCP port with https port config",
+			annotations: map[string]string{annotations.AnnLinodePortConfigPrefix + "80": `{ "protocol": "https" }`},
+			protocol:    v1.ProtocolTCP,
+		},
+		{name: "UDP port with default protocol", protocol: v1.ProtocolUDP, expectErr: true},
+		{
+			name:        "UDP port with http protocol",
+			annotations: map[string]string{annotations.AnnLinodeDefaultProtocol: "http"},
+			protocol:    v1.ProtocolUDP,
+			expectErr:   true,
+		},
+		{
+			name:        "UDP port with udp protocol",
+			annotations: map[string]string{annotations.AnnLinodeDefaultProtocol: "udp"},
+			protocol:    v1.ProtocolUDP,
+		},
+		{
+			name:        "TCP port with udp port config",
+			annotations: map[string]string{annotations.AnnLinodePortConfigPrefix + "80": `{ "protocol": "udp" }`},
+			protocol:    v1.ProtocolTCP,
+			expectErr:   true,
+		},
+		{name: "SCTP port", protocol: v1.ProtocolSCTP, expectErr: true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			lb := &loadbalancers{eventRecorder: recorder}
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+
+			err := lb.validatePortProtocol(svc, v1.ServicePort{Port: 80, Protocol: test.protocol})
+			if !test.expectErr {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				if len(recorder.Events) != 0 {
+					t.Errorf("unexpected event: %s", <-recorder.Events)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonInvalidPortProtocol) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				t.Error("expected a Warning event for the incompatible protocol")
+			}
+		})
+	}
+}
+
+func Test_healthCheckUpToDate(t *testing.T) {
+	wanted := linodego.NodeBalancerConfig{
+		Check:         linodego.CheckHTTP,
+		CheckPath:     "/healthz",
+		CheckInterval: 5,
+		CheckTimeout:  3,
+		CheckAttempts: 2,
+		CheckPassive:  true,
+	}
+
+	testcases := []struct {
+		name     string
+		modify   func(*linodego.NodeBalancerConfig)
+		expected bool
+	}{
+		{"unchanged", func(*linodego.NodeBalancerConfig) {}, true},
+		{"check disabled", func(c *linodego.NodeBalancerConfig) { c.Check = linodego.CheckNone }, false},
+		{"path changed", func(c *linodego.NodeBalancerConfig) { c.CheckPath = "/" }, false},
+		{"interval changed", func(c *linodego.NodeBalancerConfig) { c.CheckInterval = 30 }, false},
+		{"passive disabled", func(c *linodego.NodeBalancerConfig) { c.CheckPassive = false }, false},
+		{"unused body", func(c *linodego.NodeBalancerConfig) { c.CheckBody = "ok" }, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			current := wanted
+			test.modify(&current)
+			if upToDate := healthCheckUpToDate(current, wanted); upToDate != test.expected {
+				t.Errorf("expected up to date %t, got %t", test.expected, upToDate)
+			}
+		})
+	}
+}
+
+func Test_validateServicePorts(t *testing.T) {
+	testcases := []struct {
+		name      string
+		ports     []v1.ServicePort
+		expectErr bool
+	}{
+		{
+			name: "distinct ports",
+			ports: []v1.ServicePort{
+				{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+				{Name: "https", Protocol: v1.ProtocolTCP, Port: 443},
+			},
+		},
+		{
+			name: "same port with different names",
+			ports: []v1.ServicePort{
+				{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+				{Name: "web", Protocol: v1.ProtocolTCP, Port: 80},
+			},
+			expectErr: true,
+		},
+		{
+			name: "same port with different protocols",
+			ports: []v1.ServicePort{
+				{Name: "dns-tcp", Protocol: v1.ProtocolTCP, Port: 53},
+				{Name: "dns-udp", Protocol: v1.ProtocolUDP, Port: 53},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			lb := &loadbalancers{eventRecorder: recorder}
+			svc := &v1.Service{Spec: v1.ServiceSpec{Ports: test.ports}}
+
+			err := lb.validateServicePorts(svc)
+			if !test.expectErr {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if !stderrors.As(err, &duplicatePortError{}) {
+				t.Fatalf("expected a duplicate port error, got %v", err)
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonDuplicatePort) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				t.Error("expected a Warning event for the duplicate port")
+			}
+		})
+	}
+}
+
+func Test_validateServicePortsPrivileged(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+				{Name: "alt", Protocol: v1.ProtocolTCP, Port: 8080},
+			},
+		},
+	}
+	defer func() { Options.PrivilegedPortsPolicy = "" }()
+
+	t.Run("allow", func(t *testing.T) {
+		Options.PrivilegedPortsPolicy = privilegedPortsAllow
+		lb := &loadbalancers{eventRecorder: record.NewFakeRecorder(1)}
+		if err := lb.validateServicePorts(svc); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		Options.PrivilegedPortsPolicy = privilegedPortsReject
+		recorder := record.NewFakeRecorder(1)
+		lb := &loadbalancers{eventRecorder: recorder}
+
+		if err := lb.validateServicePorts(svc); !stderrors.Is(err, errPrivilegedPort) {
+			t.Fatalf("expected errPrivilegedPort, got %v", err)
+		}
+		select {
+		case event := <-recorder.Events:
+			if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonPrivilegedPort) {
+				t.Errorf("unexpected event: %s", event)
+			}
+		default:
+			t.Error("expected a Warning event for the privileged port")
+		}
+
+		unprivileged := svc.DeepCopy()
+		unprivileged.Spec.Ports = unprivileged.Spec.Ports[1:]
+		if err := lb.validateServicePorts(unprivileged); err != nil {
+			t.Errorf("unexpected error for unprivileged ports: %s", err)
+		}
+	})
+}
+
+func Test_validateIdleTimeout(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		timeout     int
+		err         error
+	}{
+		{"not set", nil, 0, nil},
+		{"configured timeout", map[string]string{annotations.AnnLinodeIdleTimeout: "600"}, 600, errIdleTimeoutUnsupported},
+		{"out of range", map[string]string{annotations.AnnLinodeIdleTimeout: "7200"}, 0, invalidAnnotationError{}},
+		{"not a number", map[string]string{annotations.AnnLinodeIdleTimeout: "10m"}, 0, invalidAnnotationError{}},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			lb := &loadbalancers{eventRecorder: recorder}
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Annotations: test.annotations}}
+
+			if timeout, _, _ := getIdleTimeout(svc); timeout != test.timeout {
+				t.Errorf("expected timeout %d, got %d", test.timeout, timeout)
+			}
+
+			err := lb.validateIdleTimeout(svc)
+			switch test.err.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			case invalidAnnotationError:
+				if !stderrors.As(err, &invalidAnnotationError{}) {
+					t.Fatalf("expected an invalid annotation error, got %v", err)
+				}
+			default:
+				if !stderrors.Is(err, test.err) {
+					t.Fatalf("expected %v, got %v", test.err, err)
+				}
+			}
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonUnsupportedSetting) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				t.Error("expected a Warning event for the idle timeout")
+			}
+		})
+	}
+}
+
+func Test_drainRemovedBackends(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	backendIPs := map[string]string{"node-1": "10.0.0.1"}
+	nb := &linodego.NodeBalancer{ID: 10}
+	nbCfgs := []linodego.NodeBalancerConfig{{ID: 1, Port: 80}}
+	nbNodes := []linodego.NodeBalancerNode{
+		{ID: 1, Address: "10.0.0.1:30000", Mode: linodego.ModeAccept},
+		{ID: 2, Address: "10.0.0.2:30000", Mode: linodego.ModeAccept},
+		{ID: 3, Address: "10.0.0.3:30000", Mode: linodego.ModeAccept},
+	}
+
+	defer func() { Options.BackendDrainPeriod = 0 }()
+
+	t.Run("removed backends are drained until the drain period is over", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendDrainPeriod = 50 * time.Millisecond
+
+		drain := func(_ context.Context, _, _, _ int, opts linodego.NodeBalancerNodeUpdateOptions) (*linodego.NodeBalancerNode, error) {
+			if opts.Mode != linodego.ModeDrain {
+				t.Errorf("expected backend to be set to drain, got mode %q", opts.Mode)
+			}
+			return &linodego.NodeBalancerNode{}, nil
+		}
+		mc.EXPECT().ListNodeBalancerNodes(gomock.Any(), nb.ID, 1, nil).Times(1).Return(nbNodes, nil)
+		mc.EXPECT().UpdateNodeBalancerNode(gomock.Any(), nb.ID, 1, 2, gomock.Any()).Times(1).DoAndReturn(drain)
+		mc.EXPECT().UpdateNodeBalancerNode(gomock.Any(), nb.ID, 1, 3, gomock.Any()).Times(1).DoAndReturn(drain)
+
+		var retryErr *api.RetryError
+		if err := lb.drainRemovedBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); !stderrors.As(err, &retryErr) {
+			t.Fatalf("expected a RetryError, got %v", err)
+		}
+		if retryErr.RetryAfter() <= 0 || retryErr.RetryAfter() > Options.BackendDrainPeriod {
+			t.Errorf("expected to be requeued within %s, got %s", Options.BackendDrainPeriod, retryErr.RetryAfter())
+		}
+
+		// the backends in drain mode are not drained again
+		draining := []linodego.NodeBalancerNode{nbNodes[0], nbNodes[1], nbNodes[2]}
+		draining[1].Mode, draining[2].Mode = linodego.ModeDrain, linodego.ModeDrain
+		mc.EXPECT().ListNodeBalancerNodes(gomock.Any(), nb.ID, 1, nil).Times(2).Return(draining, nil)
+
+		if err := lb.drainRemovedBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); !stderrors.As(err, &retryErr) {
+			t.Fatalf("expected a RetryError within the drain period, got %v", err)
+		}
+
+		time.Sleep(Options.BackendDrainPeriod)
+		if err := lb.drainRemovedBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); err != nil {
+			t.Fatalf("expected the backends to be drained, got: %s", err)
+		}
+	})
+
+	t.Run("backends drained out of band are not waited for", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendDrainPeriod = time.Hour
+
+		draining := []linodego.NodeBalancerNode{nbNodes[0], nbNodes[1]}
+		draining[1].Mode = linodego.ModeDrain
+		mc.EXPECT().ListNodeBalancerNodes(gomock.Any(), nb.ID, 1, nil).Times(1).Return(draining, nil)
+
+		if err := lb.drainRemovedBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("nothing is drained without a drain period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendDrainPeriod = 0
+		if err := lb.drainRemovedBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+}
+
+func Test_removeStaleBackends(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	backendIPs := map[string]string{"node-1": "10.0.0.1"}
+	nb := &linodego.NodeBalancer{ID: 10}
+	nbCfgs := []linodego.NodeBalancerConfig{{ID: 1, Port: 80}}
+	nbNodes := []linodego.NodeBalancerNode{{ID: 1, Address: "10.0.0.1:30000", Mode: linodego.ModeAccept}}
+	for i := 2; i <= 6; i++ {
+		nbNodes = append(nbNodes, linodego.NodeBalancerNode{ID: i, Address: fmt.Sprintf("10.0.0.%d:30000", i), Mode: linodego.ModeAccept})
+	}
+
+	defer func() {
+		Options.BackendRemovalBatchSize = 0
+		Options.BackendRemovalBatchDelay = 0
+	}()
+
+	t.Run("removed backends are deleted in batches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendRemovalBatchSize = 2
+		Options.BackendRemovalBatchDelay = 50 * time.Millisecond
+
+		var deletedIDs []int
+		var deletedAt []time.Time
+		mc.EXPECT().ListNodeBalancerNodes(gomock.Any(), nb.ID, 1, nil).Times(1).Return(nbNodes, nil)
+		mc.EXPECT().DeleteNodeBalancerNode(gomock.Any(), nb.ID, 1, gomock.Any()).Times(5).DoAndReturn(
+			func(_ context.Context, _, _, nodeID int) error {
+				deletedIDs = append(deletedIDs, nodeID)
+				deletedAt = append(deletedAt, time.Now())
+				return nil
+			})
+
+		if err := lb.removeStaleBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !reflect.DeepEqual(deletedIDs, []int{2, 3, 4, 5, 6}) {
+			t.Fatalf("expected backends 2-6 to be deleted, got %v", deletedIDs)
+		}
+		// batches of 2: {2, 3}, {4, 5}, {6}
+		for _, i := range []int{2, 4} {
+			if gap := deletedAt[i].Sub(deletedAt[i-1]); gap < Options.BackendRemovalBatchDelay {
+				t.Errorf("expected a %s delay before deleting backend %d, waited %s", Options.BackendRemovalBatchDelay, deletedIDs[i], gap)
+			}
+		}
+		for _, i := range []int{1, 3} {
+			if gap := deletedAt[i].Sub(deletedAt[i-1]); gap >= Options.BackendRemovalBatchDelay {
+				t.Errorf("expected backend %d to be deleted in the same batch as backend %d, waited %s", deletedIDs[i], deletedIDs[i-1], gap)
+			}
+		}
+	})
+
+	t.Run("cancelled between batches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendRemovalBatchSize = 2
+		Options.BackendRemovalBatchDelay = time.Hour
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		mc.EXPECT().ListNodeBalancerNodes(gomock.Any(), nb.ID, 1, nil).Times(1).Return(nbNodes, nil)
+		mc.EXPECT().DeleteNodeBalancerNode(gomock.Any(), nb.ID, 1, gomock.Any()).Times(2).DoAndReturn(
+			func(_ context.Context, _, _, _ int) error {
+				cancel()
+				return nil
+			})
+
+		if err := lb.removeStaleBackends(ctx, svc, backendIPs, nb, nbCfgs); !stderrors.Is(err, context.Canceled) {
+			t.Fatalf("expected the removal to stop once cancelled, got: %v", err)
+		}
+	})
+
+	t.Run("nothing is deleted without a batch size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendRemovalBatchSize = 0
+		if err := lb.removeStaleBackends(context.TODO(), svc, backendIPs, nb, nbCfgs); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	})
+}
+
+func Test_isNamespaceManaged(t *testing.T) {
+	defer func() {
+		Options.ServiceNamespaces = nil
+		Options.ExcludedServiceNamespaces = nil
+	}()
+
+	testcases := []struct {
+		name      string
+		allowed   []string
+		excluded  []string
+		namespace string
+		expected  bool
+	}{
+		{name: "no restrictions", namespace: "default", expected: true},
+		{name: "in allowlist", allowed: []string{"default", "prod"}, namespace: "prod", expected: true},
+		{name: "not in allowlist", allowed: []string{"default"}, namespace: "prod", expected: false},
+		{name: "in denylist", excluded: []string{"legacy"}, namespace: "legacy", expected: false},
+		{name: "not in denylist", excluded: []string{"legacy"}, namespace: "default", expected: true},
+		{name: "denylist wins over allowlist", allowed: []string{"legacy"}, excluded: []string{"legacy"}, namespace: "legacy", expected: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			Options.ServiceNamespaces = tc.allowed
+			Options.ExcludedServiceNamespaces = tc.excluded
+			if got := isNamespaceManaged(tc.namespace); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func testUpdateLoadBalancerAddTLSPort(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeThrottle: "15",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	extraPort := v1.ServicePort{
+		Name:     randString(),
+		Protocol: "TCP",
+		Port:     int32(443),
+		NodePort: int32(30001),
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	addTLSSecret(t, lb.kubeClient)
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+
+	stubService(fakeClientset, svc)
+	svc.Spec.Ports = append(svc.Spec.Ports, extraPort)
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
+	})
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error while updated annotations: %s", err)
+	}
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfgs, errConfigs := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if errConfigs != nil {
+		t.Fatalf("error getting NodeBalancer configs: %v", errConfigs)
+	}
+
+	expectedPorts := map[int]struct{}{
+		80:  {},
+		443: {},
+	}
+
+	observedPorts := make(map[int]struct{})
+
+	for _, cfg := range cfgs {
+		nodes, errNodes := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfg.ID, nil)
+		if errNodes != nil {
+			t.Errorf("error getting NodeBalancer nodes: %v", errNodes)
+		}
+
+		if len(nodes) == 0 {
+			t.Errorf("no nodes found for port %d", cfg.Port)
+		}
+
+		observedPorts[cfg.Port] = struct{}{}
+	}
+
+	if !reflect.DeepEqual(expectedPorts, observedPorts) {
+		t.Errorf("NodeBalancer ports mismatch: expected %v, got %v", expectedPorts, observedPorts)
+	}
+}
+
+func testUpdateLoadBalancerRepairHealthCheck(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeHealthCheckType:     "http",
+				annotations.AnnLinodeCheckPath:           "/healthz",
+				annotations.AnnLinodeHealthCheckInterval: "10",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("expected a single NodeBalancer config, got %v: %v", configs, err)
+	}
+
+	// the check is disabled out of band
+	update := configs[0].GetUpdateOptions()
+	update.Check = linodego.CheckNone
+	update.CheckPath = ""
+	if _, err = client.UpdateNodeBalancerConfig(context.TODO(), nb.ID, configs[0].ID, update); err != nil {
+		t.Fatalf("failed to update NodeBalancer config: %s", err)
+	}
+	for len(recorder.Events) > 0 {
+		<-recorder.Events
+	}
+
+	// the Service and nodes are unchanged since the last update
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	configs, err = client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("expected a single NodeBalancer config, got %v: %v", configs, err)
+	}
+	repaired := configs[0]
+	if repaired.Check != linodego.CheckHTTP || repaired.CheckPath != "/healthz" || repaired.CheckInterval != 10 {
+		t.Errorf("expected the http check on /healthz every 10s to be restored, got %s check on %q every %ds",
+			repaired.Check, repaired.CheckPath, repaired.CheckInterval)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonHealthCheckDrift) {
+			t.Errorf("expected a %s event, got %q", eventReasonHealthCheckDrift, event)
+		}
+	default:
+		t.Errorf("expected a %s event", eventReasonHealthCheckDrift)
+	}
+}
+
+func testUpdateLoadBalancerRemovePort(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
+				{Name: "alt", Protocol: "TCP", Port: int32(8080), NodePort: int32(30001)},
+				{Name: "admin", Protocol: "TCP", Port: int32(9090), NodePort: int32(30002)},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	// the first update adds the backends to the configs created with the NodeBalancer
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	configsByPort := func() map[int]int {
+		t.Helper()
+		configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil {
+			t.Fatalf("failed to list NodeBalancer configs: %s", err)
+		}
+		ids := make(map[int]int, len(configs))
+		for _, config := range configs {
+			ids[config.Port] = config.ID
+		}
+		return ids
+	}
+	before := configsByPort()
+
+	fakeAPI.ResetRequests()
+	svc.Spec.Ports = []v1.ServicePort{svc.Spec.Ports[0], svc.Spec.Ports[2]}
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	rebuild := regexp.MustCompile("/nodebalancers/[0-9]+/configs/[0-9]+/rebuild")
+	removed := fmt.Sprintf("/nodebalancers/%d/configs/%d", nb.ID, before[8080])
+	for request := range fakeAPI.requests {
+		switch {
+		case request.Method == http.MethodDelete && request.Path != removed:
+			t.Errorf("unexpected %s %s, only %s should be deleted", request.Method, request.Path, removed)
+		case rebuild.MatchString(request.Path):
+			t.Errorf("unexpected %s %s of a config left in the service", request.Method, request.Path)
+		}
+	}
+	if !fakeAPI.didRequestOccur(http.MethodDelete, removed, "") {
+		t.Errorf("expected the config for the removed port to be deleted with DELETE %s", removed)
+	}
+
+	expected := map[int]int{80: before[80], 9090: before[9090]}
+	if after := configsByPort(); !reflect.DeepEqual(after, expected) {
+		t.Errorf("expected configs %v to be left intact, got %v", expected, after)
+	}
+}
+
+func testUpdateLoadBalancerUnownedConfigs(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
+				{Name: "alt", Protocol: "TCP", Port: int32(8080), NodePort: int32(30001)},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	defer func() {
+		Options.NodeBalancerConfigPolicy = ""
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+
+	// a side-channel config added to the NodeBalancer out of band
+	checkPassive := true
+	unowned, err := client.CreateNodeBalancerConfig(context.TODO(), nb.ID, linodego.NodeBalancerConfigCreateOptions{
+		Port:         9000,
+		Protocol:     linodego.ProtocolTCP,
+		CheckPassive: &checkPassive,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NodeBalancer config: %s", err)
+	}
+
+	configPorts := func() []int {
+		t.Helper()
+		configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil {
+			t.Fatalf("failed to list NodeBalancer configs: %s", err)
+		}
+		ports := make([]int, 0, len(configs))
+		for _, config := range configs {
+			ports = append(ports, config.Port)
+		}
+		slices.Sort(ports)
+		return ports
+	}
+
+	for i := 0; i < 2; i++ {
+		lb.reconciled.forget(svc)
+		if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+			t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+		}
+		if ports := configPorts(); !reflect.DeepEqual(ports, []int{80, 8080, 9000}) {
+			t.Fatalf("expected the unowned config to be preserved across reconciles, got configs for ports %v", ports)
+		}
+	}
+
+	// removing a port of the Service still deletes the config it owned
+	svc.Spec.Ports = svc.Spec.Ports[:1]
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if ports := configPorts(); !reflect.DeepEqual(ports, []int{80, 9000}) {
+		t.Fatalf("expected only the config for the removed port to be deleted, got configs for ports %v", ports)
+	}
+
+	Options.NodeBalancerConfigPolicy = configPolicyManageAll
+	lb.reconciled.forget(svc)
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	if ports := configPorts(); !reflect.DeepEqual(ports, []int{80}) {
+		t.Errorf("expected config (%d) to be deleted under the %s policy, got configs for ports %v", unowned.ID, configPolicyManageAll, ports)
+	}
+}
+
+func testUpdateLoadBalancerReorderedConfigs(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
+				{Name: "alt", Protocol: "TCP", Port: int32(8080), NodePort: int32(30001)},
+				{Name: "admin", Protocol: "TCP", Port: int32(9090), NodePort: int32(30002)},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	defer func() {
+		fakeAPI.configsOrder = nil
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	// the first update adds the backends to the configs created with the NodeBalancer
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	rebuild := regexp.MustCompile("/nodebalancers/[0-9]+/configs/[0-9]+/rebuild")
+	for name, order := range map[string]func(a, b linodego.NodeBalancerConfig) int{
+		"ascending":  func(a, b linodego.NodeBalancerConfig) int { return a.Port - b.Port },
+		"descending": func(a, b linodego.NodeBalancerConfig) int { return b.Port - a.Port },
+	} {
+		t.Run(name, func(t *testing.T) {
+			fakeAPI.configsOrder = order
+			fakeAPI.ResetRequests()
+
+			// force a full reconcile of the unchanged service
+			lb.reconciled.forget(svc)
+			if err := lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+				t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+			}
+			for request := range fakeAPI.requests {
+				if rebuild.MatchString(request.Path) || request.Method == http.MethodDelete {
+					t.Errorf("unexpected %s %s for an unchanged service", request.Method, request.Path)
+				}
+			}
+		})
+	}
+
+	t.Run("changed config is rebuilt", func(t *testing.T) {
+		fakeAPI.ResetRequests()
+		svc.SetAnnotations(map[string]string{annotations.AnnLinodePortConfigPrefix + "8080": `{"protocol": "http"}`})
+		if err := lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+			t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+		}
+
+		var rebuilt []string
+		for request := range fakeAPI.requests {
+			if rebuild.MatchString(request.Path) {
+				var opts linodego.NodeBalancerConfigRebuildOptions
+				if err := json.Unmarshal([]byte(request.Body), &opts); err != nil {
+					t.Fatal(err)
+				}
+				rebuilt = append(rebuilt, strconv.Itoa(opts.Port))
+			}
+		}
+		if !reflect.DeepEqual(rebuilt, []string{"8080"}) {
+			t.Errorf("expected only the config of port 8080 to be rebuilt, got %v", rebuilt)
+		}
+	})
+}
+
+func testUpdateLoadBalancerLogTLSConfig(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	var flags flag.FlagSet
+	klog.InitFlags(&flags)
+	for name, value := range map[string]string{"v": "4", "logtostderr": "false", "alsologtostderr": "false"} {
+		if err := flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var logs bytes.Buffer
+	klog.SetOutput(&logs)
+	defer func() {
+		_ = flags.Set("v", "0")
+		_ = flags.Set("logtostderr", "true")
+		klog.SetOutput(os.Stderr)
+	}()
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(443),
+					NodePort: int32(30001),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	addTLSSecret(t, lb.kubeClient)
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	klog.Flush()
+	if got := strings.Count(logs.String(), "fingerprint sslfingerprint"); got != 1 {
+		t.Errorf("expected the fingerprint of the created config to be logged once, got %d in:\n%s", got, logs.String())
+	}
+
+	lb.reconciled.forget(svc)
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	klog.Flush()
+	if got := strings.Count(logs.String(), "fingerprint sslfingerprint"); got != 2 {
+		t.Errorf("expected the fingerprint of the rebuilt config to be logged, got %d in:\n%s", got, logs.String())
+	}
+	for _, secret := range []string{testKey, testCert, "PRIVATE KEY"} {
+		for _, line := range strings.Split(strings.TrimSpace(secret), "\n") {
+			if strings.Contains(logs.String(), line) {
+				t.Fatalf("key material %q was logged", line)
+			}
+		}
+	}
+}
+
+func testUpdateLoadBalancerAddProxyProtocol(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	for _, tc := range []struct {
+		name                string
+		proxyProtocolConfig linodego.ConfigProxyProtocol
+		invalidErr          bool
+	}{
+		{
+			name:                "with invalid Proxy Protocol",
+			proxyProtocolConfig: "bogus",
+			invalidErr:          true,
+		},
+		{
+			name:                "with none",
+			proxyProtocolConfig: linodego.ProxyProtocolNone,
+		},
+		{
+			name:                "with v1",
+			proxyProtocolConfig: linodego.ProxyProtocolV1,
+		},
+		{
+			name:                "with v2",
+			proxyProtocolConfig: linodego.ProxyProtocolV2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        randString(),
+					UID:         "foobar123",
+					Annotations: map[string]string{},
+				},
+				Spec: v1.ServiceSpec{
+					Ports: []v1.ServicePort{
+						{
+							Name:     randString(),
+							Protocol: "tcp",
+							Port:     int32(80),
+							NodePort: int32(8080),
+						},
+					},
+				},
+			}
+
+			defer func() {
+				_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+			}()
+			nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+				Region: lb.zone,
+			})
+			if err != nil {
+				t.Fatalf("failed to create NodeBalancer: %s", err)
+			}
+
+			svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nodeBalancer)
+			svc.ObjectMeta.SetAnnotations(map[string]string{
+				annotations.AnnLinodeDefaultProxyProtocol: string(tc.proxyProtocolConfig),
+			})
+
+			stubService(fakeClientset, svc)
+			if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+				var annErr invalidAnnotationError
+				if tc.invalidErr && stderrors.As(err, &annErr) && annErr.name == annotations.AnnLinodeDefaultProxyProtocol {
+					return
+				}
+				t.Fatalf("UpdateLoadBalancer returned an unexpected error while updated annotations: %s", err)
+				return
+			}
+			if tc.invalidErr {
+				t.Fatal("expected UpdateLoadBalancer to return an error")
+			}
+
+			nodeBalancerConfigs, err := client.ListNodeBalancerConfigs(context.TODO(), nodeBalancer.ID, nil)
+			if err != nil {
+				t.Fatalf("failed to get NodeBalancer: %s", err)
+			}
+
+			for _, config := range nodeBalancerConfigs {
+				proxyProtocol := config.ProxyProtocol
+				if proxyProtocol != tc.proxyProtocolConfig {
+					t.Errorf("expected ProxyProtocol to be %s; got %s", tc.proxyProtocolConfig, proxyProtocol)
+				}
+			}
+		})
+	}
+}
+
+func testUpdateLoadBalancerAddNewFirewall(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeThrottle: "15",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+	fwClient := firewall.LinodeClient{Client: client}
+	fw, err := fwClient.CreateFirewall(context.TODO(), linodego.FirewallCreateOptions{
+		Label: "test",
+		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
+			Action:      "ACCEPT",
+			Label:       "inbound-rule123",
+			Description: "inbound rule123",
+			Ports:       "4321",
+			Protocol:    linodego.TCP,
+			Addresses: linodego.NetworkAddresses{
+				IPv4: &[]string{"0.0.0.0/0"},
+			},
+		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
+	})
+	if err != nil {
+		t.Errorf("CreatingFirewall returned an error: %s", err)
+	}
+	defer func() {
+		_ = fwClient.DeleteFirewall(context.TODO(), fw)
+	}()
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
+	})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error while updated annotations: %s", err)
+	}
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No attached firewalls found")
+	}
+
+	if firewalls[0].ID != fw.ID {
+		t.Fatalf("Attached firewallID not matching with created firewall")
+	}
+}
+
+// This will also test the firewall with >255 IPs
+func testUpdateLoadBalancerAddNewFirewallACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	}
+
+	if len(firewalls) != 0 {
+		t.Fatalf("Firewalls attached when none specified")
+	}
+
+	var ipv4s []string
+	var ipv6s []string
+	i := 0
+	for i < 400 {
+		ipv4s = append(ipv4s, fmt.Sprintf("%d.%d.%d.%d", 192, rand.Int31n(255), rand.Int31n(255), rand.Int31n(255)))
+		i += 1
+	}
+	i = 0
+	for i < 300 {
+		ip := make([]byte, 16)
+		if _, err := cryptoRand.Read(ip); err != nil {
+			t.Fatalf("unable to read random bytes")
+		}
+		ipv6s = append(ipv6s, fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s:%s",
+			hex.EncodeToString(ip[0:2]),
+			hex.EncodeToString(ip[2:4]),
+			hex.EncodeToString(ip[4:6]),
+			hex.EncodeToString(ip[6:8]),
+			hex.EncodeToString(ip[8:10]),
+			hex.EncodeToString(ip[10:12]),
+			hex.EncodeToString(ip[12:14]),
+			hex.EncodeToString(ip[14:16])))
+		i += 1
+	}
+	acl := map[string]map[string][]string{
+		"allowList": {
+			"ipv4": ipv4s,
+			"ipv6": ipv6s,
+		},
+	}
+	aclString, err := json.Marshal(acl)
+	if err != nil {
+		t.Fatalf("unable to marshal json acl")
+	}
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallACL: string(aclString),
+	})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) == 0 {
+		t.Fatalf("No firewalls found")
+	}
+
+	if firewallsNew[0].Rules.InboundPolicy != "DROP" {
+		t.Errorf("expected DROP inbound policy, got %s", firewallsNew[0].Rules.InboundPolicy)
+	}
+
+	if len(firewallsNew[0].Rules.Inbound) != 4 {
+		t.Errorf("expected 4 rules, got %d", len(firewallsNew[0].Rules.Inbound))
+	}
+}
+
+func testUpdateLoadBalancerDeleteFirewallRemoveACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallACL: `{
+			"allowList": {
+				"ipv4": ["2.2.2.2"]
+			}
+		}`,
+	})
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No firewalls attached")
+	}
+
+	if firewalls[0].Rules.InboundPolicy != "DROP" {
+		t.Errorf("expected DROP inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+	}
+
+	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected IP, got %v", fwIPs)
+	}
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) != 0 {
+		t.Fatalf("firewall's %d still attached", firewallsNew[0].ID)
+	}
+}
+
+func testUpdateLoadBalancerUpdateFirewallRemoveACLaddID(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallACL: `{
+			"allowList": {
+				"ipv4": ["2.2.2.2"]
+			}
+		}`,
+	})
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No firewalls attached")
+	}
+
+	if firewalls[0].Rules.InboundPolicy != "DROP" {
+		t.Errorf("expected DROP inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+	}
+
+	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected IP, got %v", fwIPs)
+	}
+
+	fwClient := firewall.LinodeClient{Client: client}
+	fw, err := fwClient.CreateFirewall(context.TODO(), linodego.FirewallCreateOptions{
+		Label: "test",
+		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
+			Action:      "ACCEPT",
+			Label:       "inbound-rule123",
+			Description: "inbound rule123",
+			Ports:       "4321",
+			Protocol:    linodego.TCP,
+			Addresses: linodego.NetworkAddresses{
+				IPv4: &[]string{"0.0.0.0/0"},
+			},
+		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
+	})
+	if err != nil {
+		t.Errorf("Error creating firewall %s", err)
+	}
+	defer func() {
+		_ = fwClient.DeleteFirewall(context.TODO(), fw)
+	}()
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
+	})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) == 0 {
+		t.Fatalf("No attached firewalls found")
+	}
+
+	if firewallsNew[0].Rules.InboundPolicy != "ACCEPT" {
+		t.Errorf("expected ACCEPT inbound policy, got %s", firewallsNew[0].Rules.InboundPolicy)
+	}
+
+	fwIPs = firewallsNew[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected 2.2.2.2, got %v", fwIPs)
+	}
+
+	if firewallsNew[0].ID != fw.ID {
+		t.Errorf("Firewall ID does not match what we created, something wrong.")
+	}
+}
+
+func testUpdateLoadBalancerUpdateFirewallRemoveIDaddACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	fwClient := firewall.LinodeClient{Client: client}
+	fw, err := fwClient.CreateFirewall(context.TODO(), linodego.FirewallCreateOptions{
+		Label: "test",
+		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
+			Action:      "ACCEPT",
+			Label:       "inbound-rule123",
+			Description: "inbound rule123",
+			Ports:       "4321",
+			Protocol:    linodego.TCP,
+			Addresses: linodego.NetworkAddresses{
+				IPv4: &[]string{"0.0.0.0/0"},
+			},
+		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
+	})
+	if err != nil {
+		t.Errorf("Error creating firewall %s", err)
+	}
+	defer func() {
+		_ = fwClient.DeleteFirewall(context.TODO(), fw)
+	}()
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
+	})
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No firewalls attached")
+	}
+
+	if firewalls[0].Rules.InboundPolicy != "ACCEPT" {
+		t.Errorf("expected ACCEPT inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+	}
+
+	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected IP, got %v", fwIPs)
+	}
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallACL: `{
+			"allowList": {
+				"ipv4": ["2.2.2.2"]
+			}
+		}`,
+	})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) == 0 {
+		t.Fatalf("No attached firewalls found")
+	}
+
+	if firewallsNew[0].Rules.InboundPolicy != "DROP" {
+		t.Errorf("expected DROP inbound policy, got %s", firewallsNew[0].Rules.InboundPolicy)
+	}
+
+	fwIPs = firewallsNew[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected 2.2.2.2, got %v", fwIPs)
+	}
+
+	if firewallsNew[0].ID != fw.ID {
+		t.Errorf("Firewall ID does not match, something wrong.")
+	}
+}
+
+func testUpdateLoadBalancerUpdateFirewallACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeCloudFirewallACL: `{
+					"allowList": {
+						"ipv4": ["2.2.2.2"]
+					}
+				}`,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No firewalls attached")
+	}
+
+	if firewalls[0].Rules.InboundPolicy != "DROP" {
+		t.Errorf("expected DROP inbound policy, got %s", firewalls[0].Rules.InboundPolicy)
+	}
+
+	fwIPs := firewalls[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected 2.2.2.2, got %v", fwIPs)
+	}
+
+	fmt.Printf("got %v", fwIPs)
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallACL: `{
+			"allowList": {
+				"ipv4": ["2.2.2.2"],
+				"ipv6": ["dead:beef::/128"]
+			}
+		}`,
+	})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) == 0 {
+		t.Fatalf("No attached firewalls found")
+	}
+
+	fwIPs = firewallsNew[0].Rules.Inbound[0].Addresses.IPv4
+	if fwIPs == nil {
+		t.Errorf("expected non nil IPv4, got %v", fwIPs)
+	}
+
+	if len(*fwIPs) != 1 {
+		t.Errorf("expected one IPv4, got %v", fwIPs)
+	}
+
+	if firewallsNew[0].Rules.Inbound[0].Addresses.IPv6 == nil {
+		t.Errorf("expected non nil IPv6, got %v", firewallsNew[0].Rules.Inbound[0].Addresses.IPv6)
+	}
+
+	if len(*firewallsNew[0].Rules.Inbound[0].Addresses.IPv6) != 1 {
+		t.Errorf("expected one IPv6, got %v", firewallsNew[0].Rules.Inbound[0].Addresses.IPv6)
+	}
+}
+
+func testUpdateLoadBalancerUpdateFirewall(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	firewallCreateOpts := linodego.FirewallCreateOptions{
+		Label: "test",
+		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
+			Action:      "ACCEPT",
+			Label:       "inbound-rule123",
+			Description: "inbound rule123",
+			Ports:       "4321",
+			Protocol:    linodego.TCP,
+			Addresses: linodego.NetworkAddresses{
+				IPv4: &[]string{"0.0.0.0/0"},
+			},
+		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeThrottle: "15",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	fwClient := firewall.LinodeClient{Client: client}
+	fw, err := fwClient.CreateFirewall(context.TODO(), firewallCreateOpts)
+	if err != nil {
+		t.Errorf("Error creating firewall %s", err)
+	}
+	defer func() {
+		_ = fwClient.DeleteFirewall(context.TODO(), fw)
+	}()
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
+	})
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list nodeBalancer firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No firewalls attached")
+	}
+
+	if fw.ID != firewalls[0].ID {
+		t.Fatalf("Attached firewallID not matching with created firewall")
+	}
+
+	firewallCreateOpts.Label = "test2"
+	firewallNew, err := fwClient.CreateFirewall(context.TODO(), firewallCreateOpts)
+	if err != nil {
+		t.Fatalf("Error in creating firewall %s", err)
+	}
+	defer func() {
+		_ = fwClient.DeleteFirewall(context.TODO(), firewallNew)
+	}()
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(firewallNew.ID),
+	})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	nbUpdated, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nbUpdated.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) == 0 {
+		t.Fatalf("No attached firewalls found")
+	}
+
+	if firewallsNew[0].ID != firewallNew.ID {
+		t.Fatalf("Attached firewallID not matching with created firewall")
+	}
+}
+
+func testUpdateLoadBalancerDeleteFirewallRemoveID(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	firewallCreateOpts := linodego.FirewallCreateOptions{
+		Label: "test",
+		Rules: linodego.FirewallRuleSet{Inbound: []linodego.FirewallRule{{
+			Action:      "ACCEPT",
+			Label:       "inbound-rule123",
+			Description: "inbound rule123",
+			Ports:       "4321",
+			Protocol:    linodego.TCP,
+			Addresses: linodego.NetworkAddresses{
+				IPv4: &[]string{"0.0.0.0/0"},
+			},
+		}}, Outbound: []linodego.FirewallRule{}, InboundPolicy: "ACCEPT", OutboundPolicy: "ACCEPT"},
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	fwClient := firewall.LinodeClient{Client: client}
+	fw, err := fwClient.CreateFirewall(context.TODO(), firewallCreateOpts)
+	if err != nil {
+		t.Errorf("Error in creating firewall %s", err)
+	}
+	defer func() {
+		_ = fwClient.DeleteFirewall(context.TODO(), fw)
+	}()
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeCloudFirewallID: strconv.Itoa(fw.ID),
+	})
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	firewalls, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Errorf("Error in listing firewalls %s", err)
+	}
+
+	if len(firewalls) == 0 {
+		t.Fatalf("No firewalls attached")
+	}
+
+	if fw.ID != firewalls[0].ID {
+		t.Fatalf("Attached firewallID not matching with created firewall")
+	}
+
+	svc.ObjectMeta.SetAnnotations(map[string]string{})
+
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error: %s", err)
+	}
+
+	firewallsNew, err := lb.client.ListNodeBalancerFirewalls(context.TODO(), nb.ID, &linodego.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to List Firewalls %s", err)
+	}
+
+	if len(firewallsNew) != 0 {
+		t.Fatalf("firewall's %d still attached", firewallsNew[0].ID)
+	}
+}
+
+func testUpdateLoadBalancerAddNodeBalancerID(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        randString(),
+			UID:         "foobar123",
+			Annotations: map[string]string{},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "http",
+					Port:     int32(80),
+					NodePort: int32(8080),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
+
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	// the NodeBalancer the CCM created for the service
+	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: lb.zone,
+		Tags:   []string{getOwnerTag(svc)},
+	})
+	if err != nil {
+		t.Fatalf("failed to create NodeBalancer: %s", err)
+	}
+
+	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nodeBalancer)
+
+	newNodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: lb.zone,
+	})
+	if err != nil {
+		t.Fatalf("failed to create new NodeBalancer: %s", err)
+	}
+
+	stubService(fakeClientset, svc)
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeNodeBalancerID: strconv.Itoa(newNodeBalancer.ID),
+	})
+	err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Errorf("UpdateLoadBalancer returned an error while updated annotations: %s", err)
+	}
+
+	clusterName := strings.TrimPrefix(svc.Namespace, "kube-system-")
+	lbStatus, _, err := lb.GetLoadBalancer(context.TODO(), clusterName, svc)
+	if err != nil {
+		t.Errorf("GetLoadBalancer returned an error: %s", err)
+	}
+
+	expectedLBStatus := makeLoadBalancerStatus(svc, newNodeBalancer)
+	if !reflect.DeepEqual(expectedLBStatus, lbStatus) {
+		t.Errorf("LoadBalancer status mismatch: expected %v, got %v", expectedLBStatus, lbStatus)
+	}
+
+	if !fakeAPI.didRequestOccur(http.MethodDelete, fmt.Sprintf("/nodebalancers/%d", nodeBalancer.ID), "") {
+		t.Errorf("expected old NodeBalancer to have been deleted")
+	}
+}
+
+func Test_getConnectionThrottle(t *testing.T) {
+	testcases := []struct {
+		name      string
+		service   *v1.Service
+		expected  int
+		expectErr bool
+	}{
+		{
+			"throttle not specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        randString(),
+					UID:         "abc123",
+					Annotations: map[string]string{},
+				},
+			},
+			0,
+			false,
+		},
+		{
+			"throttle value is a string",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeThrottle: "foo",
+					},
+				},
+			},
+			0,
+			true,
+		},
+		{
+			"throttle value is less than 0",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeThrottle: "-123",
+					},
+				},
+			},
+			0,
+			true,
+		},
+		{
+			"throttle value is valid",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeThrottle: "1",
+					},
+				},
+			},
+			1,
+			false,
+		},
+		{
+			"throttle value is too high",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeThrottle: "21",
+					},
+				},
+			},
+			0,
+			true,
+		},
+		{
+			"throttle value is the maximum",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeThrottle: "20",
+					},
+				},
+			},
+			20,
+			false,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			connThrottle, err := getConnectionThrottle(test.service)
+			if test.expectErr != (err != nil) {
+				t.Fatalf("expected error %t, got %v", test.expectErr, err)
+			}
+
+			if test.expected != connThrottle {
+				t.Fatalf("expected throttle value (%d) does not match actual value (%d)", test.expected, connThrottle)
+			}
+		})
+	}
+}
+
+func Test_validateServiceAnnotationsThrottle(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	lb := &loadbalancers{eventRecorder: recorder}
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        randString(),
+			Annotations: map[string]string{annotations.AnnLinodeThrottle: "25"},
+		},
+	}
+
+	err := lb.validateServiceAnnotations(svc)
+	assert.IsType(t, invalidAnnotationError{}, err)
+	event := <-recorder.Events
+	assert.Contains(t, event, eventReasonInvalidAnnotation)
+	assert.Contains(t, event, "must be between 0 and 20")
+}
+
+func Test_getPortConfig(t *testing.T) {
+	testcases := []struct {
+		name               string
+		service            *v1.Service
+		expectedPortConfig portConfig
+		err                error
+	}{
+		{
+			"default no proxy protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+				},
+			},
+			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"default proxy protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProxyProtocol: string(linodego.ProxyProtocolV2),
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolV2},
+			nil,
+		},
+		{
+			"port specific proxy protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProxyProtocol:     string(linodego.ProxyProtocolV2),
+						annotations.AnnLinodePortConfigPrefix + "443": fmt.Sprintf(`{"proxy-protocol": "%s"}`, linodego.ProxyProtocolV1),
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolV1},
+			nil,
+		},
+		{
+			"default invalid proxy protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProxyProtocol: "invalid",
+					},
+				},
+			},
+			portConfig{},
+			invalidAnnotationError{name: annotations.AnnLinodeDefaultProxyProtocol, value: "invalid", reason: "options are none, v1, v2"},
+		},
+		{
+			"default no protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+				},
+			},
+			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolNone},
+
+			nil,
+		},
+		{
+			"default tcp protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProtocol: "tcp",
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "tcp", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"default capitalized protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProtocol: "HTTP",
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "http", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"default invalid protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProtocol: "invalid",
+					},
+				},
+			},
+			portConfig{},
+			invalidAnnotationError{name: annotations.AnnLinodeDefaultProtocol, value: "invalid", reason: "options are tcp, http, https, udp"},
+		},
+		{
+			"port config falls back to default",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProtocol:          "http",
+						annotations.AnnLinodePortConfigPrefix + "443": `{}`,
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "http", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"port config capitalized protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "HTTp" }`,
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "http", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"port config invalid protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "invalid" }`,
+					},
+				},
+			},
+			portConfig{},
+			invalidAnnotationError{name: annotations.AnnLinodePortConfigPrefix + "443", value: "invalid", reason: "options are tcp, http, https, udp"},
+		},
+		{
+			"default udp protocol specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProtocol: "udp",
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "udp", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"port config udp protocol ignores default proxy protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProxyProtocol:     string(linodego.ProxyProtocolV2),
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "udp" }`,
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "udp", ProxyProtocol: linodego.ProxyProtocolNone},
+			nil,
+		},
+		{
+			"port config udp protocol with proxy protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "udp", "proxy-protocol": "v1" }`,
+					},
+				},
+			},
+			portConfig{},
+			invalidAnnotationError{name: annotations.AnnLinodePortConfigPrefix + "443", value: "v1", reason: "proxy protocol is not supported for udp"},
+		},
+		{
+			"port config http protocol with proxy protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "http", "proxy-protocol": "v2" }`,
+					},
+				},
+			},
+			portConfig{},
+			invalidAnnotationError{name: annotations.AnnLinodePortConfigPrefix + "443", value: "v2", reason: "proxy protocol is not supported for http"},
+		},
+		{
+			"port config https protocol ignores default proxy protocol",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProxyProtocol:     string(linodego.ProxyProtocolV1),
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret" }`,
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "https", ProxyProtocol: linodego.ProxyProtocolNone, TLSSecretName: "tls-secret"},
+			nil,
+		},
+		{
+			"port config SNI hostnames sharing a secret",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret", "tls-secrets": { "b.linode.test": "tls-secret", "a.linode.test": "tls-secret" } }`,
+					},
+				},
+			},
+			portConfig{Port: 443, Protocol: "https", ProxyProtocol: linodego.ProxyProtocolNone, TLSSecretName: "tls-secret", TLSHostnames: []string{"a.linode.test", "b.linode.test"}},
+			nil,
+		},
+		{
+			"port config SNI hostnames with different secrets",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secret-name": "tls-secret", "tls-secrets": { "a.linode.test": "a-tls-secret" } }`,
+					},
+				},
+			},
+			portConfig{},
+			fmt.Errorf("%w: port 443 references TLS secrets a-tls-secret, tls-secret, use a single secret whose certificate covers all of its SNI hostnames", errMultipleTLSSecrets),
+		},
+		{
+			"port config SNI hostname without secret",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "https", "tls-secrets": { "a.linode.test": "" } }`,
+					},
+				},
+			},
+			portConfig{},
+			invalidAnnotationError{name: annotations.AnnLinodePortConfigPrefix + "443", value: `"a.linode.test": ""`, reason: "tls-secrets must map SNI hostnames to secret names"},
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			testPort := 443
+			portConfig, err := getPortConfig(test.service, testPort)
+
+			if !reflect.DeepEqual(portConfig, test.expectedPortConfig) {
+				t.Error("unexpected port config")
+				t.Logf("expected: %q", test.expectedPortConfig)
+				t.Logf("actual: %q", portConfig)
+			}
+
+			if !reflect.DeepEqual(err, test.err) {
+				t.Error("unexpected error")
+				t.Logf("expected: %q", test.err)
+				t.Logf("actual: %q", err)
+			}
+		})
+	}
+}
+
+func Test_setHealthCheck(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		expected    linodego.NodeBalancerConfig
+		expectErr   bool
+	}{
+		{
+			name:     "defaults",
+			expected: linodego.NodeBalancerConfig{Check: linodego.CheckConnection, CheckInterval: 5, CheckTimeout: 3, CheckAttempts: 2, CheckPassive: true},
+		},
+		{
+			name: "all settings annotated",
+			annotations: map[string]string{
+				annotations.AnnLinodeHealthCheckType:     "http_body",
+				annotations.AnnLinodeCheckPath:           "/ready",
+				annotations.AnnLinodeCheckBody:           "ok",
+				annotations.AnnLinodeHealthCheckInterval: "60",
+				annotations.AnnLinodeHealthCheckTimeout:  "30",
+				annotations.AnnLinodeHealthCheckAttempts: "10",
+				annotations.AnnLinodeHealthCheckPassive:  "false",
+			},
+			expected: linodego.NodeBalancerConfig{Check: linodego.CheckHTTPBody, CheckPath: "/ready", CheckBody: "ok", CheckInterval: 60, CheckTimeout: 30, CheckAttempts: 10},
+		},
+		{
+			name:        "interval not greater than the timeout",
+			annotations: map[string]string{annotations.AnnLinodeHealthCheckInterval: "10", annotations.AnnLinodeHealthCheckTimeout: "10"},
+			expectErr:   true,
+		},
+		{
+			name:        "interval too short",
+			annotations: map[string]string{annotations.AnnLinodeHealthCheckInterval: "1"},
+			expectErr:   true,
+		},
+		{
+			name:        "timeout too long",
+			annotations: map[string]string{annotations.AnnLinodeHealthCheckInterval: "60", annotations.AnnLinodeHealthCheckTimeout: "31"},
+			expectErr:   true,
+		},
+		{
+			name:        "no attempts",
+			annotations: map[string]string{annotations.AnnLinodeHealthCheckAttempts: "0"},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid check type",
+			annotations: map[string]string{annotations.AnnLinodeHealthCheckType: "ping"},
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			config := linodego.NodeBalancerConfig{}
+			err := setHealthCheck(svc, &config, 1)
+			if test.expectErr {
+				assert.IsType(t, invalidAnnotationError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, config)
+		})
+	}
+}
+
+func Test_setBalancing(t *testing.T) {
+	testcases := []struct {
+		name               string
+		annotations        map[string]string
+		protocol           linodego.ConfigProtocol
+		expectedAlgorithm  linodego.ConfigAlgorithm
+		expectedStickiness linodego.ConfigStickiness
+		expectErr          bool
+	}{
+		{name: "not annotated", protocol: linodego.ProtocolTCP},
+		{
+			name: "annotated",
+			annotations: map[string]string{
+				annotations.AnnLinodeAlgorithm:  "leastconn",
+				annotations.AnnLinodeStickiness: "table",
+			},
+			protocol:           linodego.ProtocolTCP,
+			expectedAlgorithm:  linodego.AlgorithmLeastConn,
+			expectedStickiness: linodego.StickinessTable,
+		},
+		{
+			name:               "http cookie for http",
+			annotations:        map[string]string{annotations.AnnLinodeStickiness: "http_cookie"},
+			protocol:           linodego.ProtocolHTTPS,
+			expectedStickiness: linodego.StickinessHTTPCookie,
+		},
+		{
+			name:        "http cookie not applied to tcp",
+			annotations: map[string]string{annotations.AnnLinodeStickiness: "http_cookie"},
+			protocol:    linodego.ProtocolTCP,
+		},
+		{
+			name: "source and table not applied to udp",
+			annotations: map[string]string{
+				annotations.AnnLinodeAlgorithm:  "source",
+				annotations.AnnLinodeStickiness: "table",
+			},
+			protocol: protocolUDP,
+		},
+		{
+			name:        "invalid algorithm",
+			annotations: map[string]string{annotations.AnnLinodeAlgorithm: "random"},
+			protocol:    linodego.ProtocolTCP,
+			expectErr:   true,
+		},
+		{
+			name:        "invalid stickiness",
+			annotations: map[string]string{annotations.AnnLinodeStickiness: "cookie"},
+			protocol:    linodego.ProtocolHTTP,
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			config := linodego.NodeBalancerConfig{Protocol: test.protocol}
+			err := setBalancing(svc, &config)
+			if test.expectErr {
+				assert.IsType(t, invalidAnnotationError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedAlgorithm, config.Algorithm)
+			assert.Equal(t, test.expectedStickiness, config.Stickiness)
+		})
+	}
+}
+
+func Test_getHealthCheckType(t *testing.T) {
+	testcases := []struct {
+		name       string
+		service    *v1.Service
+		healthType linodego.ConfigCheck
+		err        error
+	}{
+		{
+			"no type specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        randString(),
+					UID:         "abc123",
+					Annotations: map[string]string{},
+				},
+			},
+			linodego.CheckConnection,
+			nil,
+		},
+		{
+			"http specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeHealthCheckType: "http",
+					},
+				},
+			},
+			linodego.CheckHTTP,
+			nil,
+		},
+		{
+			"invalid specified",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					UID:  "abc123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeHealthCheckType: "invalid",
+					},
+				},
+			},
+			"",
+			invalidAnnotationError{name: annotations.AnnLinodeHealthCheckType, value: "invalid", reason: "options are none, connection, http, http_body"},
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			hType, err := getHealthCheckType(test.service)
+			if !reflect.DeepEqual(hType, test.healthType) {
+				t.Error("unexpected health check type")
+				t.Logf("expected: %v", test.healthType)
+				t.Logf("actual: %v", hType)
+			}
+
+			if !reflect.DeepEqual(err, test.err) {
+				t.Error("unexpected error")
+				t.Logf("expected: %v", test.err)
+				t.Logf("actual: %v", err)
+			}
+		})
+	}
+}
+
+func Test_getNodePrivateIP(t *testing.T) {
+	testcases := []struct {
+		name    string
+		node    *v1.Node
+		address string
+	}{
+		{
+			"node internal ip specified",
+			&v1.Node{
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{
+							Type:    v1.NodeInternalIP,
+							Address: "127.0.0.1",
+						},
+					},
+				},
+			},
+			"127.0.0.1",
+		},
+		{
+			"node internal ip not specified",
+			&v1.Node{
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{
+							Type:    v1.NodeExternalIP,
+							Address: "127.0.0.1",
+						},
+					},
+				},
+			},
+			"",
+		},
+		{
+			"node internal ip annotation present",
+			&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						annotations.AnnLinodeNodePrivateIP: "192.168.42.42",
+					},
+				},
+				Status: v1.NodeStatus{
+					Addresses: []v1.NodeAddress{
+						{
+							Type:    v1.NodeInternalIP,
+							Address: "10.0.1.1",
+						},
+					},
+				},
+			},
+			"192.168.42.42",
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			ip := getNodePrivateIP(test.node)
+			if ip != test.address {
+				t.Error("unexpected certificate")
+				t.Logf("expected: %q", test.address)
+				t.Logf("actual: %q", ip)
+			}
+		})
+	}
+}
+
+func Test_getNodeBackendIP(t *testing.T) {
+	multiNICNode := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "multi-nic",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "192.168.10.5",
+				},
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "10.0.0.5",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "45.79.0.5",
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		node        *v1.Node
+		address     string
+		expectErr   bool
+	}{
+		{
+			"no backend subnet uses first internal ip",
+			nil,
+			multiNICNode,
+			"192.168.10.5",
+			false,
+		},
+		{
+			"backend subnet selects the in-subnet ip",
+			map[string]string{annotations.AnnLinodeBackendSubnet: "10.0.0.0/24"},
+			multiNICNode,
+			"10.0.0.5",
+			false,
+		},
+		{
+			"any of several backend subnets",
+			map[string]string{annotations.AnnLinodeBackendSubnet: "172.16.0.0/12, 10.0.0.0/24"},
+			multiNICNode,
+			"10.0.0.5",
+			false,
+		},
+		{
+			"backend subnet matches private ip annotation",
+			map[string]string{annotations.AnnLinodeBackendSubnet: "192.168.128.0/17"},
+			&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						annotations.AnnLinodeNodePrivateIP: "192.168.200.1",
+					},
+				},
+				Status: multiNICNode.Status,
+			},
+			"192.168.200.1",
+			false,
+		},
+		{
+			"no address within backend subnet",
+			map[string]string{annotations.AnnLinodeBackendSubnet: "172.16.0.0/12"},
+			multiNICNode,
+			"",
+			true,
+		},
+		{
+			"invalid backend subnet",
+			map[string]string{annotations.AnnLinodeBackendSubnet: "10.0.0.0"},
+			multiNICNode,
+			"",
+			true,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			ip, err := getNodeBackendIP(svc, test.node)
+			if test.expectErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if ip != test.address {
+				t.Error("unexpected backend ip")
+				t.Logf("expected: %q", test.address)
+				t.Logf("actual: %q", ip)
+			}
+		})
+	}
+}
+
+func Test_getNodeBackendIPPreference(t *testing.T) {
+	multiAddressNode := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "multi-address",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "10.0.0.5",
+				},
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "192.168.150.5",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "45.79.0.5",
+				},
+			},
+		},
+	}
+	publicOnlyNode := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "public-only",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "45.79.0.6",
+				},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name       string
+		flag       string
+		annotation string
+		node       *v1.Node
+		address    string
+		expectErr  bool
+	}{
+		{"vpc first", "vpc,private,public", "", multiAddressNode, "10.0.0.5", false},
+		{"private first", "private,vpc,public", "", multiAddressNode, "192.168.150.5", false},
+		{"public first", "public,private", "", multiAddressNode, "45.79.0.5", false},
+		{"falls through to available type", "vpc,private,public", "", publicOnlyNode, "45.79.0.6", false},
+		{"no available type", "vpc,private", "", publicOnlyNode, "", true},
+		{"annotation overrides flag", "vpc,private,public", "public", multiAddressNode, "45.79.0.5", false},
+		{"annotation without flag", "", " Private , vpc ", multiAddressNode, "192.168.150.5", false},
+		{"invalid type", "vpc,internal", "", multiAddressNode, "", true},
+		{"no preference uses first internal ip", "", "", multiAddressNode, "10.0.0.5", false},
+	}
+
+	defer func() { Options.BackendIPPreference = "" }()
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			Options.BackendIPPreference = test.flag
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+			if test.annotation != "" {
+				svc.Annotations[annotations.AnnLinodeBackendIPPreference] = test.annotation
+			}
+
+			ip, err := getNodeBackendIP(svc, test.node)
+			if test.expectErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if ip != test.address {
+				t.Error("unexpected backend ip")
+				t.Logf("expected: %q", test.address)
+				t.Logf("actual: %q", ip)
+			}
+		})
+	}
+}
+
+func Test_buildNodeBalancerNodesDuplicateAddresses(t *testing.T) {
+	backendIPs := map[string]string{
+		"node-c": "10.0.0.1",
+		"node-b": "10.0.0.2",
+		"node-a": "10.0.0.1",
+	}
+	lb := &loadbalancers{}
+
+	t.Run("keeps the first node by name", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			backends, err := lb.buildNodeBalancerNodes(&v1.Service{}, backendIPs, nil, 30000)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var got []string
+			for _, backend := range backends {
+				got = append(got, backend.Label+"="+backend.Address)
+			}
+			want := []string{"node-a=10.0.0.1:30000", "node-b=10.0.0.2:30000"}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("expected backends %v, got %v", want, got)
+			}
+		}
+	})
+
+	t.Run("fails when configured to", func(t *testing.T) {
+		Options.DuplicateBackendAddressPolicy = duplicateBackendAddressFail
+		defer func() { Options.DuplicateBackendAddressPolicy = "" }()
+
+		if _, err := lb.buildNodeBalancerNodes(&v1.Service{}, backendIPs, nil, 30000); !stderrors.Is(err, errDuplicateBackendAddress) {
+			t.Errorf("expected %v, got %v", errDuplicateBackendAddress, err)
+		}
+	})
+}
+
+func Test_resolveBackendIPInstanceSource(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       v1.NodeSpec{ProviderID: providerIDPrefix + "123"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "192.168.200.1",
+				},
+			},
+		},
+	}
+	vpcAddress := "10.0.0.2"
+	ips := &linodego.InstanceIPAddressResponse{
+		IPv4: &linodego.InstanceIPv4Response{
+			Public:  []*linodego.InstanceIP{{Address: "45.0.0.2"}},
+			Private: []*linodego.InstanceIP{{Address: "192.168.150.2"}},
+			VPC:     []*linodego.VPCIP{{Address: &vpcAddress}},
+		},
+	}
+
+	defer func() { Options.BackendIPSource = "" }()
+
+	testcases := []struct {
+		name        string
+		source      string
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name:     "node source uses the node addresses",
+			source:   backendIPSourceNode,
+			expected: "192.168.200.1",
+		},
+		{
+			name:     "instance source defaults to the VPC address",
+			source:   backendIPSourceInstance,
+			expected: "10.0.0.2",
+		},
+		{
+			name:        "instance source with backend IP preference",
+			source:      backendIPSourceInstance,
+			annotations: map[string]string{annotations.AnnLinodeBackendIPPreference: "private,vpc"},
+			expected:    "192.168.150.2",
+		},
+		{
+			name:        "instance source with backend subnet",
+			source:      backendIPSourceInstance,
+			annotations: map[string]string{annotations.AnnLinodeBackendSubnet: "45.0.0.0/24"},
+			expected:    "45.0.0.2",
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mc := mocks.NewMockClient(ctrl)
+			lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+			Options.BackendIPSource = test.source
+			if test.source == backendIPSourceInstance {
+				mc.EXPECT().GetInstanceIPAddresses(gomock.Any(), 123).Times(1).Return(ips, nil)
+			}
+
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			address, err := lb.resolveBackendIP(context.TODO(), svc, node)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if address != test.expected {
+				t.Errorf("expected backend address %s, got %s", test.expected, address)
+			}
+		})
+	}
+
+	t.Run("instance source without provider ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		lb := newLoadbalancers(mocks.NewMockClient(ctrl), "us-west").(*loadbalancers)
+
+		Options.BackendIPSource = backendIPSourceInstance
+		node := node.DeepCopy()
+		node.Spec.ProviderID = ""
+		if _, err := lb.resolveBackendIP(context.TODO(), &v1.Service{}, node); err == nil {
+			t.Error("expected an error for a node without a provider ID")
+		}
+	})
+
+	t.Run("instance source looks up each node once per reconcile", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mc := mocks.NewMockClient(ctrl)
+		lb := newLoadbalancers(mc, "us-west").(*loadbalancers)
+
+		Options.BackendIPSource = backendIPSourceInstance
+		mc.EXPECT().GetInstanceIPAddresses(gomock.Any(), 123).Times(1).Return(ips, nil)
+
+		svc := &v1.Service{
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{Protocol: "TCP", Port: 80, NodePort: 30000},
+					{Protocol: "TCP", Port: 443, NodePort: 30001},
+				},
+			},
+		}
+		backendIPs, err := lb.resolveBackendIPs(context.TODO(), svc, []*v1.Node{node})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		for _, port := range svc.Spec.Ports {
+			wanted, err := lb.wantedBackends(svc, backendIPs, port)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if address := fmt.Sprintf("10.0.0.2:%d", port.NodePort); !wanted[address] {
+				t.Errorf("expected backend %s for port %d, got %v", address, port.Port, wanted)
+			}
+		}
+	})
+}
+
+func testBuildLoadBalancerRequest(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol: "tcp",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     "test",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-2",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.2",
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-3",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.3",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	nb, err := lb.buildLoadBalancerRequest(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(err, err) {
+		t.Error("unexpected error")
+		t.Logf("expected: %v", nil)
+		t.Logf("actual: %v", err)
+	}
+
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != len(svc.Spec.Ports) {
+		t.Error("unexpected nodebalancer config count")
+		t.Logf("expected: %v", len(svc.Spec.Ports))
+		t.Logf("actual: %v", len(configs))
+	}
+
+	nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, configs[0].ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nbNodes) != len(nodes) {
+		t.Error("unexpected nodebalancer nodes count")
+		t.Logf("expected: %v", len(nodes))
+		t.Logf("actual: %v", len(nbNodes))
+	}
+}
+
+func testEnsureLoadBalancerPreserveAnnotation(t *testing.T, client *linodego.Client, fake *fakeAPI) {
+	testServiceSpec := v1.ServiceSpec{
+		Ports: []v1.ServicePort{
+			{
+				Name:     "test",
+				Protocol: "TCP",
+				Port:     int32(80),
+				NodePort: int32(30000),
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	for _, test := range []struct {
+		name        string
+		deleted     bool
+		expectErr   bool
+		annotations map[string]string
+	}{
+		{
+			name:        "load balancer preserved",
+			annotations: map[string]string{annotations.AnnLinodeLoadBalancerPreserve: "true"},
+			deleted:     false,
+		},
+		{
+			name:        "load balancer not preserved (deleted)",
+			annotations: map[string]string{annotations.AnnLinodeLoadBalancerPreserve: "false"},
+			deleted:     true,
+		},
+		{
+			name:        "invalid value is an error (preserved)",
+			annotations: map[string]string{annotations.AnnLinodeLoadBalancerPreserve: "bogus"},
+			deleted:     false,
+			expectErr:   true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					UID:         types.UID("foobar" + randString()),
+					Annotations: test.annotations,
+				},
+				Spec: testServiceSpec,
+			}
+
+			nb, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, lb.zone, []*linodego.NodeBalancerConfigCreateOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nb)
+			err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+
+			didDelete := fake.didRequestOccur(http.MethodDelete, fmt.Sprintf("/nodebalancers/%d", nb.ID), "")
+			if didDelete && !test.deleted {
+				t.Fatal("load balancer was unexpectedly deleted")
+			} else if !didDelete && test.deleted {
+				t.Fatal("load balancer was unexpectedly preserved")
+			}
+
+			if test.expectErr {
+				if !stderrors.As(err, &invalidAnnotationError{}) {
+					t.Fatalf("expected an invalid annotation error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func testEnsureLoadBalancerReattachPreserved(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeLoadBalancerPreserve: "true",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
+				{Name: "alt", Protocol: "TCP", Port: int32(8080), NodePort: int32(30001)},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer via status: %s", err)
+	}
+
+	if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+		t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+	}
+	if _, ok := f.nb[strconv.Itoa(nb.ID)]; !ok {
+		t.Fatal("expected the NodeBalancer to be preserved")
+	}
+
+	// the Service is recreated with the ID of the preserved NodeBalancer and
+	// without one of its former ports
+	recreated := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			UID:  "foobar456",
+			Annotations: map[string]string{
+				annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nb.ID),
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30002)},
+			},
+		},
+	}
+	stubService(fakeClientset, recreated)
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", recreated) }()
+
+	nbCount := len(f.nb)
+	lbStatus, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", recreated, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	if len(f.nb) != nbCount {
+		t.Errorf("expected the preserved NodeBalancer to be reattached, got %d new", len(f.nb)-nbCount)
+	}
+	if lbStatus.Ingress[0].IP != *nb.IPv4 {
+		t.Errorf("expected the IP of the preserved NodeBalancer %s, got %s", *nb.IPv4, lbStatus.Ingress[0].IP)
+	}
+
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer configs: %s", err)
+	}
+	if len(configs) != 1 || configs[0].Port != 80 {
+		t.Fatalf("expected only the config for port 80 to be kept, got %v", configs)
+	}
+	nodeBalancerNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, configs[0].ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer nodes: %s", err)
+	}
+	for _, node := range nodeBalancerNodes {
+		if node.Address != "127.0.0.1:30002" {
+			t.Errorf("expected the backend to use the node port of the recreated service, got %s", node.Address)
+		}
+	}
+}
+
+func testEnsureLoadBalancerDeleted(t *testing.T, client *linodego.Client, fake *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol: "tcp",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     "test",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	testcases := []struct {
+		name        string
+		clusterName string
+		service     *v1.Service
+		err         error
+	}{
+		{
+			"load balancer delete",
+			"linodelb",
+			svc,
+			nil,
+		},
+		{
+			"load balancer not exists",
+			"linodelb",
+			&v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "notexists",
+					UID:  "notexists123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeDefaultProtocol: "tcp",
+					},
+				},
+				Spec: v1.ServiceSpec{
+					Ports: []v1.ServicePort{
+						{
+							Name:     "test",
+							Protocol: "TCP",
+							Port:     int32(80),
+							NodePort: int32(30000),
+						},
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	configs := []*linodego.NodeBalancerConfigCreateOptions{}
+	_, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, lb.zone, configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			err := lb.EnsureLoadBalancerDeleted(context.TODO(), test.clusterName, test.service)
+			if !reflect.DeepEqual(err, test.err) {
+				t.Error("unexpected error")
+				t.Logf("expected: %v", test.err)
+				t.Logf("actual: %v", err)
+			}
+		})
+	}
+}
+
+func testEnsureLoadBalancerDeletedFirewallACL(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeCloudFirewallACL: `{
+					"allowList": {
+						"ipv4": ["2.2.2.2"]
+					}
+				}`,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		{
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	if len(fakeAPI.fw) != 1 {
+		t.Fatalf("expected a firewall to be created for the ACL, got %d", len(fakeAPI.fw))
+	}
+
+	if err := lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+		t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+	}
+
+	if len(fakeAPI.fw) != 0 {
+		t.Errorf("expected the firewall created for the ACL to be deleted, %d remain", len(fakeAPI.fw))
+	}
+}
+
+func testEnsureExistingLoadBalancer(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testensure",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol:           "tcp",
+				annotations.AnnLinodePortConfigPrefix + "8443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     "test",
+					Protocol: "TCP",
+					Port:     int32(8443),
+					NodePort: int32(30000),
+				},
+				{
+					Name:     "test2",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30001),
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+	addTLSSecret(t, lb.kubeClient)
+
+	configs := []*linodego.NodeBalancerConfigCreateOptions{}
+	nb, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, lb.zone, configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nb)
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	getLBStatus, exists, err := lb.GetLoadBalancer(context.TODO(), "linodelb", svc)
+	if err != nil {
+		t.Fatalf("failed to create nodebalancer: %s", err)
+	}
+	if !exists {
+		t.Fatal("Node balancer not found")
+	}
+
+	testcases := []struct {
+		name        string
+		service     *v1.Service
+		nodes       []*v1.Node
+		clusterName string
+		nbIP        string
+		err         error
+	}{
+		{
+			"update load balancer",
+			svc,
+			[]*v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "node-1",
+					},
+					Status: v1.NodeStatus{
+						Addresses: []v1.NodeAddress{
+							{
+								Type:    v1.NodeInternalIP,
+								Address: "127.0.0.1",
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "node-2",
+					},
+					Status: v1.NodeStatus{
+						Addresses: []v1.NodeAddress{
+							{
+								Type:    v1.NodeInternalIP,
+								Address: "127.0.0.2",
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "node-3",
+					},
+					Status: v1.NodeStatus{
+						Addresses: []v1.NodeAddress{
+							{
+								Type:    v1.NodeInternalIP,
+								Address: "127.0.0.3",
+							},
+						},
+					},
+				},
+			},
+			"linodelb",
+			getLBStatus.Ingress[0].IP,
 			nil,
 		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), test.clusterName, test.service, test.nodes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lbStatus.Ingress[0].IP != test.nbIP {
+				t.Error("unexpected error")
+				t.Logf("expected: %v", test.nbIP)
+				t.Logf("actual: %v", lbStatus.Ingress)
+			}
+			if !reflect.DeepEqual(err, test.err) {
+				t.Error("unexpected error")
+				t.Logf("expected: %v", test.err)
+				t.Logf("actual: %v", err)
+			}
+		})
+	}
+}
+
+func testEnsureLoadBalancerRecreated(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testrecreate",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol: "tcp",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     "test",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expected no events on initial creation, got %d", len(recorder.Events))
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	oldNB, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the NodeBalancer disappears out from under the Service
+	if err = client.DeleteNodeBalancer(context.TODO(), oldNB.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	lbStatus, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+	svc.Status.LoadBalancer = *lbStatus
+	newNB, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newNB.ID == oldNB.ID {
+		t.Fatalf("expected a new NodeBalancer, got the same ID %d", newNB.ID)
+	}
+	if lbStatus.Ingress[0].IP != *newNB.IPv4 {
+		t.Errorf("expected status to reflect the new NodeBalancer IP %s, got %s", *newNB.IPv4, lbStatus.Ingress[0].IP)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonNodeBalancerRecreated) {
+			t.Errorf("unexpected event: %s", event)
+		}
+		if !strings.Contains(event, *oldNB.IPv4) || !strings.Contains(event, *newNB.IPv4) {
+			t.Errorf("expected event to mention old IP %s and new IP %s, got: %s", *oldNB.IPv4, *newNB.IPv4, event)
+		}
+	default:
+		t.Error("expected a Warning event when the NodeBalancer is recreated")
+	}
+}
+
+func testEnsureLoadBalancerDualStack(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "testdualstack",
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeDefaultProtocol: "tcp",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			IPFamilies: []v1.IPFamily{v1.IPv4Protocol, v1.IPv6Protocol},
+			Ports: []v1.ServicePort{
+				{
+					Name:     "test",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "192.168.0.1",
+					},
+					{
+						Type:    v1.NodeExternalIP,
+						Address: "2600:3c00::1",
+					},
+				},
+			},
+		},
 		{
-			"port config invalid protocol",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: randString(),
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodePortConfigPrefix + "443": `{ "protocol": "invalid" }`,
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-2",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "192.168.0.2",
 					},
+					{
+						Type:    v1.NodeExternalIP,
+						Address: "2600:3c00::2",
+					},
+				},
+			},
+		},
+	}
+
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
+
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedIngress := []v1.LoadBalancerIngress{
+		{Hostname: *nb.Hostname, IP: *nb.IPv4},
+		{IP: *nb.IPv6},
+	}
+	if !reflect.DeepEqual(lbStatus.Ingress, expectedIngress) {
+		t.Errorf("expected ingress %#v, got %#v", expectedIngress, lbStatus.Ingress)
+	}
+
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 1 {
+		t.Fatalf("expected 1 config, got %d", len(configs))
+	}
+
+	nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, configs[0].ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses := make([]string, 0, len(nbNodes))
+	for _, node := range nbNodes {
+		addresses = append(addresses, node.Address)
+	}
+	sort.Strings(addresses)
+	// backends are only ever private IPv4 addresses
+	expectedAddresses := []string{"192.168.0.1:30000", "192.168.0.2:30000"}
+	if !reflect.DeepEqual(addresses, expectedAddresses) {
+		t.Errorf("expected backends %v, got %v", expectedAddresses, addresses)
+	}
+
+	// single-stack Services get a single ingress
+	svc.Spec.IPFamilies = []v1.IPFamily{v1.IPv4Protocol}
+	if status := makeLoadBalancerStatus(svc, nb); len(status.Ingress) != 1 {
+		t.Errorf("expected a single ingress for single-stack service, got %#v", status.Ingress)
+	}
+}
+
+func Test_updateServiceLoadBalancerStatus(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Status: v1.ServiceStatus{
+			LoadBalancer: v1.LoadBalancerStatus{
+				Ingress: []v1.LoadBalancerIngress{{IP: "192.168.0.2"}},
+			},
+			Conditions: []metav1.Condition{{
+				Type:   "Example",
+				Status: metav1.ConditionTrue,
+				Reason: "SetByAnotherController",
+			}},
+		},
+	}
+	status := &v1.LoadBalancerStatus{
+		Ingress: []v1.LoadBalancerIngress{{
+			Hostname: "nb-192-168-0-1.newark.nodebalancer.linode.com",
+			IP:       "192.168.0.1",
+		}},
+	}
+
+	fakeClientset := fake.NewSimpleClientset(svc)
+	var patches []k8stesting.PatchAction
+	fakeClientset.PrependReactor("*", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		patch, ok := action.(k8stesting.PatchAction)
+		if !ok {
+			t.Errorf("expected the status to be patched, got a %s", action.GetVerb())
+			return false, nil, nil
+		}
+		patches = append(patches, patch)
+		return false, nil, nil
+	})
+
+	lb := &loadbalancers{kubeClient: fakeClientset}
+	if err := lb.updateServiceLoadBalancerStatus(context.TODO(), svc, status); err != nil {
+		t.Fatalf("expected status update to succeed, got: %s", err)
+	}
+
+	if len(patches) != 1 {
+		t.Fatalf("expected a single status patch, got %d", len(patches))
+	}
+	if patches[0].GetPatchType() != types.ApplyPatchType {
+		t.Errorf("expected the status to be applied server-side, got patch type %s", patches[0].GetPatchType())
+	}
+	var applied map[string]any
+	if err := json.Unmarshal(patches[0].GetPatch(), &applied); err != nil {
+		t.Fatal(err)
+	}
+	if appliedStatus, _ := applied["status"].(map[string]any); len(appliedStatus) != 1 || appliedStatus["loadBalancer"] == nil {
+		t.Errorf("expected only status.loadBalancer to be applied, got %s", patches[0].GetPatch())
+	}
+
+	updated, err := fakeClientset.CoreV1().Services(svc.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(updated.Status.LoadBalancer, *status) {
+		t.Errorf("expected status %#v, got %#v", *status, updated.Status.LoadBalancer)
+	}
+	if !reflect.DeepEqual(updated.Status.Conditions, svc.Status.Conditions) {
+		t.Errorf("expected unrelated status fields to be preserved, got conditions %#v", updated.Status.Conditions)
+	}
+
+	t.Run("owned by another manager", func(t *testing.T) {
+		// the service controller set status.loadBalancer
+		fakeClientset := &ownedStatusClientset{Clientset: fake.NewSimpleClientset(svc), owner: "kube-controller-manager"}
+
+		lb := &loadbalancers{kubeClient: fakeClientset}
+		if err := lb.updateServiceLoadBalancerStatus(context.TODO(), svc, status); err != nil {
+			t.Fatalf("expected status update to succeed despite the other owner, got: %s", err)
+		}
+		if fakeClientset.owner != serviceStatusFieldManager {
+			t.Errorf("expected status.loadBalancer to be owned by %s, got %s", serviceStatusFieldManager, fakeClientset.owner)
+		}
+
+		updated, err := fakeClientset.CoreV1().Services(svc.Namespace).Get(context.TODO(), svc.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(updated.Status.LoadBalancer, *status) {
+			t.Errorf("expected status %#v, got %#v", *status, updated.Status.LoadBalancer)
+		}
+		if !reflect.DeepEqual(updated.Status.Conditions, svc.Status.Conditions) {
+			t.Errorf("expected unrelated status fields to be preserved, got conditions %#v", updated.Status.Conditions)
+		}
+	})
+}
+
+// ownedStatusClientset enforces the ownership of status.loadBalancer by its
+// field manager, owner, on server-side apply as the API server does: applying
+// it with another field manager conflicts unless forced.
+type ownedStatusClientset struct {
+	*fake.Clientset
+	owner string
+}
+
+func (c *ownedStatusClientset) CoreV1() typedcorev1.CoreV1Interface {
+	return ownedStatusCoreV1{CoreV1Interface: c.Clientset.CoreV1(), clientset: c}
+}
+
+type ownedStatusCoreV1 struct {
+	typedcorev1.CoreV1Interface
+	clientset *ownedStatusClientset
+}
+
+func (c ownedStatusCoreV1) Services(namespace string) typedcorev1.ServiceInterface {
+	return ownedStatusServices{ServiceInterface: c.CoreV1Interface.Services(namespace), clientset: c.clientset}
+}
+
+type ownedStatusServices struct {
+	typedcorev1.ServiceInterface
+	clientset *ownedStatusClientset
+}
+
+func (s ownedStatusServices) ApplyStatus(ctx context.Context, service *corev1apply.ServiceApplyConfiguration, opts metav1.ApplyOptions) (*v1.Service, error) {
+	if opts.FieldManager != s.clientset.owner && !opts.Force {
+		return nil, errors.NewApplyConflict([]metav1.StatusCause{{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: fmt.Sprintf("conflict with %q", s.clientset.owner),
+			Field:   ".status.loadBalancer",
+		}}, fmt.Sprintf("Apply failed with 1 conflict: conflict with %q: .status.loadBalancer", s.clientset.owner))
+	}
+	s.clientset.owner = opts.FieldManager
+	return s.ServiceInterface.ApplyStatus(ctx, service, opts)
+}
+
+func testMakeLoadBalancerStatus(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	ipv4 := "192.168.0.1"
+	hostname := "nb-192-168-0-1.newark.nodebalancer.linode.com"
+	nb := &linodego.NodeBalancer{
+		IPv4:     &ipv4,
+		Hostname: &hostname,
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: make(map[string]string, 1),
+		},
+	}
+
+	expectedStatus := &v1.LoadBalancerStatus{
+		Ingress: []v1.LoadBalancerIngress{{
+			Hostname: hostname,
+			IP:       ipv4,
+		}},
+	}
+	status := makeLoadBalancerStatus(svc, nb)
+	if !reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected status for basic service to be %#v; got %#v", expectedStatus, status)
+	}
+
+	svc.Annotations[annotations.AnnLinodeHostnameOnlyIngress] = "true"
+	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
+	status = makeLoadBalancerStatus(svc, nb)
+	if !reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	}
+
+	for _, provisioning := range []*linodego.NodeBalancer{{}, {IPv4: &ipv4}, {Hostname: &hostname}} {
+		if status = makeLoadBalancerStatus(svc, provisioning); len(status.Ingress) != 0 {
+			t.Errorf("expected no ingress for a NodeBalancer without an IP or hostname, got %#v", status.Ingress)
+		}
+	}
+}
+
+func testMakeLoadBalancerStatusEnvVar(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	ipv4 := "192.168.0.1"
+	hostname := "nb-192-168-0-1.newark.nodebalancer.linode.com"
+	nb := &linodego.NodeBalancer{
+		IPv4:     &ipv4,
+		Hostname: &hostname,
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: make(map[string]string, 1),
+		},
+	}
+
+	expectedStatus := &v1.LoadBalancerStatus{
+		Ingress: []v1.LoadBalancerIngress{{
+			Hostname: hostname,
+			IP:       ipv4,
+		}},
+	}
+	status := makeLoadBalancerStatus(svc, nb)
+	if !reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected status for basic service to be %#v; got %#v", expectedStatus, status)
+	}
+
+	t.Setenv("LINODE_HOSTNAME_ONLY_INGRESS", "true")
+	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
+	status = makeLoadBalancerStatus(svc, nb)
+	if !reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	}
+
+	t.Setenv("LINODE_HOSTNAME_ONLY_INGRESS", "false")
+	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
+	status = makeLoadBalancerStatus(svc, nb)
+	if reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	}
+
+	t.Setenv("LINODE_HOSTNAME_ONLY_INGRESS", "banana")
+	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
+	status = makeLoadBalancerStatus(svc, nb)
+	if reflect.DeepEqual(status, expectedStatus) {
+		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	}
+	os.Unsetenv("LINODE_HOSTNAME_ONLY_INGRESS")
+}
+
+func testCleanupDoesntCall(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	region := "us-west"
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	svcAnn := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+	}
+
+	// the previous NodeBalancer was created by the CCM for the service
+	nb1, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{Region: region, Tags: []string{getOwnerTag(svcAnn)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb2, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{Region: region})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcAnn.Annotations = map[string]string{annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nb2.ID)}
+	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nb1)
+	svcAnn.Status.LoadBalancer = *makeLoadBalancerStatus(svcAnn, nb1)
+	lb := newLoadbalancers(client, region).(*loadbalancers)
+
+	fakeAPI.ResetRequests()
+	t.Run("non-annotated service shouldn't call the API during cleanup", func(t *testing.T) {
+		if err := lb.cleanupOldNodeBalancer(context.TODO(), svc); err != nil {
+			t.Fatal(err)
+		}
+		if len(fakeAPI.requests) != 0 {
+			t.Fatalf("unexpected API calls: %v", fakeAPI.requests)
+		}
+	})
+
+	fakeAPI.ResetRequests()
+	t.Run("annotated service calls the API to load said NB", func(t *testing.T) {
+		if err := lb.cleanupOldNodeBalancer(context.TODO(), svcAnn); err != nil {
+			t.Fatal(err)
+		}
+		expectedRequests := map[fakeRequest]struct{}{
+			{Path: "/nodebalancers", Body: "", Method: "GET"}:                            {},
+			{Path: fmt.Sprintf("/nodebalancers/%v", nb2.ID), Body: "", Method: "GET"}:    {},
+			{Path: fmt.Sprintf("/nodebalancers/%v", nb1.ID), Body: "", Method: "DELETE"}: {},
+		}
+		if !reflect.DeepEqual(fakeAPI.requests, expectedRequests) {
+			t.Fatalf("expected requests %#v, got %#v instead", expectedRequests, fakeAPI.requests)
+		}
+	})
+}
+
+func testUpdateLoadBalancerNoNodes(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        randString(),
+			UID:         "foobar123",
+			Annotations: map[string]string{},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "http",
+					Port:     int32(80),
+					NodePort: int32(8080),
 				},
 			},
-			portConfig{},
-			fmt.Errorf("invalid protocol: %q specified", "invalid"),
 		},
 	}
 
-	for _, test := range testcases {
-		t.Run(test.name, func(t *testing.T) {
-			testPort := 443
-			portConfig, err := getPortConfig(test.service, testPort)
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	defer func() {
+		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	}()
 
-			if !reflect.DeepEqual(portConfig, test.expectedPortConfig) {
-				t.Error("unexpected port config")
-				t.Logf("expected: %q", test.expectedPortConfig)
-				t.Logf("actual: %q", portConfig)
-			}
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
 
-			if !reflect.DeepEqual(err, test.err) {
-				t.Error("unexpected error")
-				t.Logf("expected: %q", test.err)
-				t.Logf("actual: %q", err)
-			}
-		})
+	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: lb.zone,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NodeBalancer: %s", err)
 	}
-}
+	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nodeBalancer)
+	stubService(fakeClientset, svc)
+	svc.ObjectMeta.SetAnnotations(map[string]string{
+		annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nodeBalancer.ID),
+	})
 
-func Test_getHealthCheckType(t *testing.T) {
-	testcases := []struct {
-		name       string
-		service    *v1.Service
-		healthType linodego.ConfigCheck
-		err        error
-	}{
-		{
-			"no type specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        randString(),
-					UID:         "abc123",
-					Annotations: map[string]string{},
-				},
-			},
-			linodego.CheckConnection,
-			nil,
-		},
-		{
-			"http specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeHealthCheckType: "http",
-					},
-				},
-			},
-			linodego.CheckHTTP,
-			nil,
-		},
-		{
-			"invalid specified",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-					UID:  "abc123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeHealthCheckType: "invalid",
-					},
-				},
-			},
-			"",
-			fmt.Errorf("invalid health check type: %q specified in annotation: %q", "invalid", annotations.AnnLinodeHealthCheckType),
-		},
-	}
+	// setup done, test ensure/update
+	nodes := []*v1.Node{}
 
-	for _, test := range testcases {
-		t.Run(test.name, func(t *testing.T) {
-			hType, err := getHealthCheckType(test.service)
-			if !reflect.DeepEqual(hType, test.healthType) {
-				t.Error("unexpected health check type")
-				t.Logf("expected: %v", test.healthType)
-				t.Logf("actual: %v", hType)
-			}
+	t.Run("defer keeps the NodeBalancer as is", func(t *testing.T) {
+		Options.NoBackendNodesPolicy = noBackendNodesDefer
+		defer func() { Options.NoBackendNodesPolicy = "" }()
 
-			if !reflect.DeepEqual(err, test.err) {
-				t.Error("unexpected error")
-				t.Logf("expected: %v", test.err)
-				t.Logf("actual: %v", err)
-			}
-		})
-	}
+		if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, errNoNodesAvailable) {
+			t.Errorf("EnsureLoadBalancer should return %v, got %v", errNoNodesAvailable, err)
+		}
+
+		if err := lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, errNoNodesAvailable) {
+			t.Errorf("UpdateLoadBalancer should return %v, got %v", errNoNodesAvailable, err)
+		}
+	})
+
+	t.Run("keep updates the NodeBalancer without backends", func(t *testing.T) {
+		Options.NoBackendNodesPolicy = noBackendNodesKeep
+		defer func() { Options.NoBackendNodesPolicy = "" }()
+
+		if err := lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+			t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+		}
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nodeBalancer.ID, nil)
+		if err != nil || len(cfgs) != 1 {
+			t.Fatalf("expected a single NodeBalancer config, got %v (error: %v)", cfgs, err)
+		}
+		nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nodeBalancer.ID, cfgs[0].ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer nodes: %v", err)
+		}
+		if len(nbNodes) != 0 {
+			t.Errorf("expected no backends, got %v", nbNodes)
+		}
+	})
 }
 
-func Test_getNodePrivateIP(t *testing.T) {
-	testcases := []struct {
-		name    string
-		node    *v1.Node
-		address string
-	}{
-		{
-			"node internal ip specified",
-			&v1.Node{
-				Status: v1.NodeStatus{
-					Addresses: []v1.NodeAddress{
-						{
-							Type:    v1.NodeInternalIP,
-							Address: "127.0.0.1",
-						},
-					},
-				},
-			},
-			"127.0.0.1",
-		},
-		{
-			"node internal ip not specified",
-			&v1.Node{
-				Status: v1.NodeStatus{
-					Addresses: []v1.NodeAddress{
-						{
-							Type:    v1.NodeExternalIP,
-							Address: "127.0.0.1",
-						},
-					},
-				},
+func testEnsureLoadBalancerNoBackendNodes(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	newService := func() *v1.Service {
+		return &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: randString(),
+				UID:  "foobar123",
 			},
-			"",
-		},
-		{
-			"node internal ip annotation present",
-			&v1.Node{
-				ObjectMeta: metav1.ObjectMeta{
-					Annotations: map[string]string{
-						annotations.AnnLinodeNodePrivateIP: "192.168.42.42",
-					},
-				},
-				Status: v1.NodeStatus{
-					Addresses: []v1.NodeAddress{
-						{
-							Type:    v1.NodeInternalIP,
-							Address: "10.0.1.1",
-						},
+			Spec: v1.ServiceSpec{
+				Ports: []v1.ServicePort{
+					{
+						Name:     randString(),
+						Protocol: "TCP",
+						Port:     int32(80),
+						NodePort: int32(30000),
 					},
 				},
 			},
-			"192.168.42.42",
-		},
+		}
 	}
-
-	for _, test := range testcases {
-		t.Run(test.name, func(t *testing.T) {
-			ip := getNodePrivateIP(test.node)
-			if ip != test.address {
-				t.Error("unexpected certificate")
-				t.Logf("expected: %q", test.address)
-				t.Logf("actual: %q", ip)
+	expectEvent := func(t *testing.T, recorder *record.FakeRecorder) {
+		t.Helper()
+		select {
+		case event := <-recorder.Events:
+			if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonNoBackendNodes) {
+				t.Errorf("unexpected event: %s", event)
 			}
-		})
+		default:
+			t.Error("expected a Warning event for the missing backend nodes")
+		}
 	}
+
+	t.Run("keep creates the NodeBalancer without backends", func(t *testing.T) {
+		Options.NoBackendNodesPolicy = noBackendNodesKeep
+		defer func() { Options.NoBackendNodesPolicy = "" }()
+
+		svc := newService()
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		lb.kubeClient = fake.NewSimpleClientset()
+		recorder := record.NewFakeRecorder(10)
+		lb.eventRecorder = recorder
+
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{})
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		svc.Status.LoadBalancer = *lbStatus
+		defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+
+		nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+		if err != nil {
+			t.Fatalf("failed to get NodeBalancer by status: %v", err)
+		}
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil || len(cfgs) != 1 {
+			t.Fatalf("expected a single NodeBalancer config, got %v (error: %v)", cfgs, err)
+		}
+		nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfgs[0].ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer nodes: %v", err)
+		}
+		if len(nbNodes) != 0 {
+			t.Errorf("expected no backends, got %v", nbNodes)
+		}
+		expectEvent(t, recorder)
+	})
+
+	t.Run("defer skips creating the NodeBalancer", func(t *testing.T) {
+		Options.NoBackendNodesPolicy = noBackendNodesDefer
+		defer func() { Options.NoBackendNodesPolicy = "" }()
+
+		svc := newService()
+		lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+		lb.kubeClient = fake.NewSimpleClientset()
+		recorder := record.NewFakeRecorder(10)
+		lb.eventRecorder = recorder
+
+		nbCount := len(f.nb)
+		if _, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{}); !stderrors.Is(err, errNoNodesAvailable) {
+			t.Fatalf("EnsureLoadBalancer should return %v, got %v", errNoNodesAvailable, err)
+		}
+		if len(f.nb) != nbCount {
+			t.Errorf("expected no NodeBalancer to be created, got %d new", len(f.nb)-nbCount)
+		}
+		expectEvent(t, recorder)
+	})
 }
 
-func testBuildLoadBalancerRequest(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+func testEnsureLoadBalancerMultiRegion(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	nodes := []*v1.Node{
+		nodeInRegion("node-1", "us-west"),
+		nodeInRegion("node-2", "us-east"),
+		nodeInRegion("node-3", "us-east"),
+	}
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "test",
+			Name: randString(),
 			UID:  "foobar123",
-			Annotations: map[string]string{
-				annotations.AnnLinodeDefaultProtocol: "tcp",
-			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
-				{
-					Name:     "test",
-					Protocol: "TCP",
-					Port:     int32(80),
-					NodePort: int32(30000),
-				},
-			},
-		},
-	}
-	nodes := []*v1.Node{
-		{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "node-1",
-			},
-		},
-		{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "node-2",
-			},
-		},
-		{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "node-3",
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
 			},
 		},
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	nb, err := lb.buildLoadBalancerRequest(context.TODO(), "linodelb", svc, nodes)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	if !reflect.DeepEqual(err, err) {
-		t.Error("unexpected error")
-		t.Logf("expected: %v", nil)
-		t.Logf("actual: %v", err)
-	}
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
 
-	if len(configs) != len(svc.Spec.Ports) {
-		t.Error("unexpected nodebalancer config count")
-		t.Logf("expected: %v", len(svc.Spec.Ports))
-		t.Logf("actual: %v", len(configs))
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
+	}
+	if nb.Region != "us-east" {
+		t.Errorf("expected the NodeBalancer to be created in the region with the most nodes us-east, got %s", nb.Region)
 	}
 
-	nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, configs[0].ID, nil)
-	if err != nil {
-		t.Fatal(err)
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonCrossRegionBackends) {
+			t.Errorf("expected a %s event, got %q", eventReasonCrossRegionBackends, event)
+		}
+	default:
+		t.Errorf("expected a %s event", eventReasonCrossRegionBackends)
 	}
 
-	if len(nbNodes) != len(nodes) {
-		t.Error("unexpected nodebalancer nodes count")
-		t.Logf("expected: %v", len(nodes))
-		t.Logf("actual: %v", len(nbNodes))
+	// the NodeBalancer is kept in its region on update, where its backends
+	// are not unreachable
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonRegionMismatch) {
+			t.Errorf("unexpected event: %s", event)
+		}
 	}
 }
 
-func testEnsureLoadBalancerPreserveAnnotation(t *testing.T, client *linodego.Client, fake *fakeAPI) {
-	testServiceSpec := v1.ServiceSpec{
-		Ports: []v1.ServicePort{
-			{
-				Name:     "test",
-				Protocol: "TCP",
-				Port:     int32(80),
-				NodePort: int32(30000),
+func testUpdateLoadBalancerRegionMismatchMetric(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	registerMetrics()
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
 			},
 		},
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	for _, test := range []struct {
-		name        string
-		deleted     bool
-		annotations map[string]string
-	}{
-		{
-			name:        "load balancer preserved",
-			annotations: map[string]string{annotations.AnnLinodeLoadBalancerPreserve: "true"},
-			deleted:     false,
-		},
-		{
-			name:        "load balancer not preserved (deleted)",
-			annotations: map[string]string{annotations.AnnLinodeLoadBalancerPreserve: "false"},
-			deleted:     true,
-		},
-		{
-			name:        "invalid value treated as false (deleted)",
-			annotations: map[string]string{annotations.AnnLinodeLoadBalancerPreserve: "bogus"},
-			deleted:     true,
-		},
-	} {
-		t.Run(test.name, func(t *testing.T) {
-			svc := &v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:        "test",
-					UID:         types.UID("foobar" + randString()),
-					Annotations: test.annotations,
-				},
-				Spec: testServiceSpec,
-			}
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
 
-			nb, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, []*linodego.NodeBalancerConfigCreateOptions{})
-			if err != nil {
-				t.Fatal(err)
-			}
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{nodeInRegion("node-1", "us-west")})
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
 
-			svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nb)
-			err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
+	labels := map[string]string{"service": getServiceNn(svc)}
+	if value, found := getGaugeValue(t, "ccm_loadbalancer_region_mismatch", labels); !found || value != 0 {
+		t.Errorf("expected no region mismatch to be reported, got %v (found: %t)", value, found)
+	}
 
-			didDelete := fake.didRequestOccur(http.MethodDelete, fmt.Sprintf("/nodebalancers/%d", nb.ID), "")
-			if didDelete && !test.deleted {
-				t.Fatal("load balancer was unexpectedly deleted")
-			} else if !didDelete && test.deleted {
-				t.Fatal("load balancer was unexpectedly preserved")
-			}
+	// the nodes are replaced by more nodes in another region, where the
+	// NodeBalancer is not moved
+	nodes := []*v1.Node{
+		nodeInRegion("node-1", "us-west"),
+		nodeInRegion("node-2", "us-east"),
+		nodeInRegion("node-3", "us-east"),
+	}
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+	}
 
-			if err != nil {
-				t.Fatalf("unexpected error: %s", err)
-			}
-		})
+	if value, found := getGaugeValue(t, "ccm_loadbalancer_region_mismatch", labels); !found || value != 1 {
+		t.Errorf("expected a region mismatch to be reported, got %v (found: %t)", value, found)
+	}
+	mismatchEvent := false
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonRegionMismatch) {
+			mismatchEvent = true
+		}
+	}
+	if !mismatchEvent {
+		t.Errorf("expected a %s event", eventReasonRegionMismatch)
+	}
+
+	if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+		t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+	}
+	if _, found := getGaugeValue(t, "ccm_loadbalancer_region_mismatch", labels); found {
+		t.Error("expected the region mismatch of the deleted service to be removed")
 	}
 }
 
-func testEnsureLoadBalancerDeleted(t *testing.T, client *linodego.Client, fake *fakeAPI) {
+func testEnsureLoadBalancerDuplicatePorts(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "test",
+			Name: randString(),
 			UID:  "foobar123",
-			Annotations: map[string]string{
-				annotations.AnnLinodeDefaultProtocol: "tcp",
-			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
-				{
-					Name:     "test",
-					Protocol: "TCP",
-					Port:     int32(80),
-					NodePort: int32(30000),
-				},
-			},
-		},
-	}
-	testcases := []struct {
-		name        string
-		clusterName string
-		service     *v1.Service
-		err         error
-	}{
-		{
-			"load balancer delete",
-			"linodelb",
-			svc,
-			nil,
-		},
-		{
-			"load balancer not exists",
-			"linodelb",
-			&v1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "notexists",
-					UID:  "notexists123",
-					Annotations: map[string]string{
-						annotations.AnnLinodeDefaultProtocol: "tcp",
-					},
-				},
-				Spec: v1.ServiceSpec{
-					Ports: []v1.ServicePort{
-						{
-							Name:     "test",
-							Protocol: "TCP",
-							Port:     int32(80),
-							NodePort: int32(30000),
-						},
-					},
-				},
+				{Name: "http", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)},
+				{Name: "web", Protocol: "TCP", Port: int32(80), NodePort: int32(30001)},
 			},
-			nil,
 		},
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	configs := []*linodego.NodeBalancerConfigCreateOptions{}
-	_, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, configs)
-	if err != nil {
-		t.Fatal(err)
-	}
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+	stubService(fakeClientset, svc)
 	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	for _, test := range testcases {
-		t.Run(test.name, func(t *testing.T) {
-			err := lb.EnsureLoadBalancerDeleted(context.TODO(), test.clusterName, test.service)
-			if !reflect.DeepEqual(err, test.err) {
-				t.Error("unexpected error")
-				t.Logf("expected: %v", test.err)
-				t.Logf("actual: %v", err)
-			}
-		})
+	fakeAPI.ResetRequests()
+	_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	var dupErr duplicatePortError
+	if !stderrors.As(err, &dupErr) {
+		t.Fatalf("expected a duplicate port error, got %v", err)
+	}
+	if dupErr.port != 80 || !reflect.DeepEqual(dupErr.names, []string{"http", "web"}) {
+		t.Errorf("unexpected duplicate port error: %s", err)
+	}
+	if len(fakeAPI.requests) != 0 {
+		t.Errorf("expected no NodeBalancer to be created, got requests %v", fakeAPI.requests)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonDuplicatePort) {
+			t.Errorf("expected a %s event, got %q", eventReasonDuplicatePort, event)
+		}
+	default:
+		t.Errorf("expected a %s event", eventReasonDuplicatePort)
+	}
+
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.As(err, &duplicatePortError{}) {
+		t.Errorf("expected UpdateLoadBalancer to reject the duplicate port, got %v", err)
 	}
 }
 
-func testEnsureExistingLoadBalancer(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+func testEnsureLoadBalancerRetryBudget(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
+	Options.MaxReconcileRetries = 2
+	defer func() { Options.MaxReconcileRetries = 0 }()
+
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
+		},
+	}
+
+	// the NodeBalancer ID annotation references a NodeBalancer which does not exist
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "testensure",
+			Name: randString(),
 			UID:  "foobar123",
 			Annotations: map[string]string{
-				annotations.AnnLinodeDefaultProtocol:           "tcp",
-				annotations.AnnLinodePortConfigPrefix + "8443": `{ "protocol": "https", "tls-secret-name": "tls-secret"}`,
+				annotations.AnnLinodeNodeBalancerID: "999999",
 			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
 				{
-					Name:     "test",
-					Protocol: "TCP",
-					Port:     int32(8443),
-					NodePort: int32(30000),
-				},
-				{
-					Name:     "test2",
+					Name:     randString(),
 					Protocol: "TCP",
 					Port:     int32(80),
-					NodePort: int32(30001),
+					NodePort: int32(30000),
 				},
 			},
 		},
 	}
 
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	lb.kubeClient = fake.NewSimpleClientset()
-	addTLSSecret(t, lb.kubeClient)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+	stubService(fakeClientset, svc)
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	configs := []*linodego.NodeBalancerConfigCreateOptions{}
-	nb, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, configs)
-	if err != nil {
-		t.Fatal(err)
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		if err == nil {
+			t.Fatalf("expected attempt %d to fail", attempt)
+		}
+		if stderrors.As(err, &retriesExhaustedError{}) {
+			t.Fatalf("expected attempt %d to be reconciled, got %s", attempt, err)
+		}
 	}
 
-	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nb)
-	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
-	getLBStatus, exists, err := lb.GetLoadBalancer(context.TODO(), "linodelb", svc)
-	if err != nil {
-		t.Fatalf("failed to create nodebalancer: %s", err)
+	// each attempt also reports the missing NodeBalancer
+	var exhausted bool
+	for len(recorder.Events) > 0 {
+		event := <-recorder.Events
+		exhausted = exhausted || strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonRetriesExhausted)
 	}
-	if !exists {
-		t.Fatal("Node balancer not found")
+	if !exhausted {
+		t.Errorf("expected a %s event", eventReasonRetriesExhausted)
 	}
 
-	testcases := []struct {
-		name        string
-		service     *v1.Service
-		nodes       []*v1.Node
-		clusterName string
-		nbIP        string
-		err         error
-	}{
+	t.Run("retries stop after the limit", func(t *testing.T) {
+		fakeAPI.ResetRequests()
+		_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		if !stderrors.As(err, &retriesExhaustedError{}) {
+			t.Fatalf("expected retries to be exhausted, got %v", err)
+		}
+		if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.As(err, &retriesExhaustedError{}) {
+			t.Fatalf("expected retries to be exhausted, got %v", err)
+		}
+		if len(fakeAPI.requests) != 0 {
+			t.Fatalf("unexpected API calls: %v", fakeAPI.requests)
+		}
+		if len(recorder.Events) != 0 {
+			t.Errorf("expected a single %s event, got %q", eventReasonRetriesExhausted, <-recorder.Events)
+		}
+	})
+
+	t.Run("retries resume on a spec change", func(t *testing.T) {
+		delete(svc.Annotations, annotations.AnnLinodeNodeBalancerID)
+		lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+		if err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		svc.Status.LoadBalancer = *lbStatus
+	})
+}
+
+func testEnsureLoadBalancerRegionMismatch(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	nodes := []*v1.Node{
 		{
-			"update load balancer",
-			svc,
-			[]*v1.Node{
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "node-1",
-					},
-					Status: v1.NodeStatus{
-						Addresses: []v1.NodeAddress{
-							{
-								Type:    v1.NodeInternalIP,
-								Address: "127.0.0.1",
-							},
-						},
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
 					},
 				},
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "node-2",
-					},
-					Status: v1.NodeStatus{
-						Addresses: []v1.NodeAddress{
-							{
-								Type:    v1.NodeInternalIP,
-								Address: "127.0.0.2",
-							},
-						},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		reject bool
+	}{
+		{name: "warns and attaches backends"},
+		{name: "refuses to attach backends", reject: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			Options.RejectRegionMismatch = tc.reject
+			defer func() { Options.RejectRegionMismatch = false }()
+
+			nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+				Region: "us-east",
+			})
+			if err != nil {
+				t.Fatalf("failed to create NodeBalancer: %s", err)
+			}
+			defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nodeBalancer.ID) }()
+
+			svc := &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: randString(),
+					UID:  "foobar123",
+					Annotations: map[string]string{
+						annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nodeBalancer.ID),
 					},
 				},
-				{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "node-3",
-					},
-					Status: v1.NodeStatus{
-						Addresses: []v1.NodeAddress{
-							{
-								Type:    v1.NodeInternalIP,
-								Address: "127.0.0.3",
-							},
+				Spec: v1.ServiceSpec{
+					Ports: []v1.ServicePort{
+						{
+							Name:     randString(),
+							Protocol: "TCP",
+							Port:     int32(80),
+							NodePort: int32(30000),
 						},
 					},
 				},
-			},
-			"linodelb",
-			getLBStatus.Ingress[0].IP,
-			nil,
-		},
-	}
+			}
 
-	for _, test := range testcases {
-		t.Run(test.name, func(t *testing.T) {
-			lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), test.clusterName, test.service, test.nodes)
+			lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+			fakeClientset := fake.NewSimpleClientset()
+			lb.kubeClient = fakeClientset
+			recorder := record.NewFakeRecorder(10)
+			lb.eventRecorder = recorder
+			stubService(fakeClientset, svc)
+
+			_, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+			if tc.reject && !stderrors.Is(err, errRegionMismatch) {
+				t.Errorf("EnsureLoadBalancer should return %v, got %v", errRegionMismatch, err)
+			}
+			if !tc.reject && err != nil {
+				t.Errorf("EnsureLoadBalancer returned an error: %s", err)
+			}
+
+			cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nodeBalancer.ID, nil)
 			if err != nil {
-				t.Fatal(err)
+				t.Fatalf("failed to list NodeBalancer configs: %s", err)
 			}
-			if lbStatus.Ingress[0].IP != test.nbIP {
-				t.Error("unexpected error")
-				t.Logf("expected: %v", test.nbIP)
-				t.Logf("actual: %v", lbStatus.Ingress)
+			if tc.reject && len(cfgs) != 0 {
+				t.Errorf("expected no configs on the mismatched NodeBalancer, got %v", cfgs)
 			}
-			if !reflect.DeepEqual(err, test.err) {
-				t.Error("unexpected error")
-				t.Logf("expected: %v", test.err)
-				t.Logf("actual: %v", err)
+			if !tc.reject && len(cfgs) != 1 {
+				t.Errorf("expected a single config on the mismatched NodeBalancer, got %v", cfgs)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if !strings.HasPrefix(event, v1.EventTypeWarning+" "+eventReasonRegionMismatch) {
+					t.Errorf("unexpected event: %s", event)
+				}
+			default:
+				t.Error("expected a Warning event for the region mismatch")
 			}
 		})
 	}
 }
 
-func testMakeLoadBalancerStatus(t *testing.T, client *linodego.Client, _ *fakeAPI) {
-	ipv4 := "192.168.0.1"
-	hostname := "nb-192-168-0-1.newark.nodebalancer.linode.com"
-	nb := &linodego.NodeBalancer{
-		IPv4:     &ipv4,
-		Hostname: &hostname,
-	}
-
+func testEnsureLoadBalancerUniqueBackendLabels(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        "test",
-			Annotations: make(map[string]string, 1),
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
 		},
 	}
 
-	expectedStatus := &v1.LoadBalancerStatus{
-		Ingress: []v1.LoadBalancerIngress{{
-			Hostname: hostname,
-			IP:       ipv4,
-		}},
+	// both node names truncate to infra-logging-controlplane-3-atl
+	newNode := func(name, address string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: address,
+					},
+				},
+			},
+		}
 	}
-	status := makeLoadBalancerStatus(svc, nb)
-	if !reflect.DeepEqual(status, expectedStatus) {
-		t.Errorf("expected status for basic service to be %#v; got %#v", expectedStatus, status)
+	nodes := []*v1.Node{
+		newNode("infra-logging-controlplane-3-atl1-us-prod", "127.0.0.1"),
+		newNode("infra-logging-controlplane-3-atl2-us-prod", "127.0.0.2"),
+		newNode("node-3", "127.0.0.3"),
 	}
 
-	svc.Annotations[annotations.AnnLinodeHostnameOnlyIngress] = "true"
-	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
-	status = makeLoadBalancerStatus(svc, nb)
-	if !reflect.DeepEqual(status, expectedStatus) {
-		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
-}
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
+	stubService(fakeClientset, svc)
 
-func testMakeLoadBalancerStatusEnvVar(t *testing.T, client *linodego.Client, _ *fakeAPI) {
-	ipv4 := "192.168.0.1"
-	hostname := "nb-192-168-0-1.newark.nodebalancer.linode.com"
-	nb := &linodego.NodeBalancer{
-		IPv4:     &ipv4,
-		Hostname: &hostname,
+	checkLabels := func() {
+		t.Helper()
+		nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+		if err != nil {
+			t.Fatalf("failed to get NodeBalancer by status: %v", err)
+		}
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil || len(cfgs) != 1 {
+			t.Fatalf("expected a single NodeBalancer config, got %v (error: %v)", cfgs, err)
+		}
+		nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfgs[0].ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer nodes: %v", err)
+		}
+		labels := make(map[string]bool, len(nbNodes))
+		for _, n := range nbNodes {
+			if len(n.Label) > 32 {
+				t.Errorf("backend label %q exceeds 32 characters", n.Label)
+			}
+			labels[n.Label] = true
+		}
+		if len(labels) != len(nodes) {
+			t.Errorf("expected %d distinct backend labels, got %v", len(nodes), nbNodes)
+		}
+		if !labels["node-3"] {
+			t.Errorf("expected the label of a non-colliding backend to be kept, got %v", nbNodes)
+		}
+	}
+
+	checkLabels()
+
+	lb.reconciled.forget(svc)
+	if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+		t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
 	}
+	checkLabels()
+}
 
+func testEnsureLoadBalancerPendingIP(t *testing.T, client *linodego.Client, f *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        "test",
-			Annotations: make(map[string]string, 1),
+			Name: randString(),
+			UID:  "foobar123",
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     randString(),
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "127.0.0.1",
+					},
+				},
+			},
 		},
 	}
 
-	expectedStatus := &v1.LoadBalancerStatus{
-		Ingress: []v1.LoadBalancerIngress{{
-			Hostname: hostname,
-			IP:       ipv4,
-		}},
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+
+	f.pendingNodeBalancerIPs = true
+	defer func() { f.pendingNodeBalancerIPs = false }()
+
+	_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	var retryErr *api.RetryError
+	if !stderrors.As(err, &retryErr) {
+		t.Fatalf("expected EnsureLoadBalancer to requeue while the NodeBalancer has no IP, got %v", err)
 	}
-	status := makeLoadBalancerStatus(svc, nb)
-	if !reflect.DeepEqual(status, expectedStatus) {
-		t.Errorf("expected status for basic service to be %#v; got %#v", expectedStatus, status)
+	if retryErr.RetryAfter() != defaultNodeBalancerIPRequeueInterval {
+		t.Errorf("expected to requeue after %s, got %s", defaultNodeBalancerIPRequeueInterval, retryErr.RetryAfter())
 	}
 
-	t.Setenv("LINODE_HOSTNAME_ONLY_INGRESS", "true")
-	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
-	status = makeLoadBalancerStatus(svc, nb)
-	if !reflect.DeepEqual(status, expectedStatus) {
-		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	if len(f.nb) != 1 {
+		t.Fatalf("expected a single NodeBalancer to be created, got %d", len(f.nb))
+	}
+	var pending *linodego.NodeBalancer
+	for _, nb := range f.nb {
+		pending = nb
 	}
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 
-	t.Setenv("LINODE_HOSTNAME_ONLY_INGRESS", "false")
-	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
-	status = makeLoadBalancerStatus(svc, nb)
-	if reflect.DeepEqual(status, expectedStatus) {
-		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	// still provisioning on the next attempt
+	if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.As(err, &retryErr) {
+		t.Fatalf("expected EnsureLoadBalancer to requeue while the NodeBalancer has no IP, got %v", err)
 	}
 
-	t.Setenv("LINODE_HOSTNAME_ONLY_INGRESS", "banana")
-	expectedStatus.Ingress[0] = v1.LoadBalancerIngress{Hostname: hostname}
-	status = makeLoadBalancerStatus(svc, nb)
-	if reflect.DeepEqual(status, expectedStatus) {
-		t.Errorf("expected status for %q annotated service to be %#v; got %#v", annotations.AnnLinodeHostnameOnlyIngress, expectedStatus, status)
+	// the IP may be returned before the hostname
+	ip := "192.168.0.10"
+	pending.IPv4 = &ip
+	if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.As(err, &retryErr) {
+		t.Fatalf("expected EnsureLoadBalancer to requeue while the NodeBalancer has no hostname, got %v", err)
 	}
-	os.Unsetenv("LINODE_HOSTNAME_ONLY_INGRESS")
-}
 
-func testCleanupDoesntCall(t *testing.T, client *linodego.Client, fakeAPI *fakeAPI) {
-	region := "us-west"
-	nb1, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{Region: region})
+	// the IP and hostname are assigned once provisioning completes
+	hostname := "nb-192-168-0-10.us-west.linode.com"
+	pending.Hostname = &hostname
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
-	nb2, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{Region: region})
-	if err != nil {
-		t.Fatal(err)
+	if len(lbStatus.Ingress) != 1 || lbStatus.Ingress[0].IP != ip {
+		t.Errorf("expected ingress for IP %s, got %v", ip, lbStatus.Ingress)
 	}
-
-	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
-	svcAnn := &v1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        "test",
-			Annotations: map[string]string{annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nb2.ID)},
-		},
+	if len(f.nb) != 1 {
+		t.Errorf("expected the pending NodeBalancer to be reused, got %d NodeBalancers", len(f.nb))
 	}
-	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nb1)
-	svcAnn.Status.LoadBalancer = *makeLoadBalancerStatus(svcAnn, nb1)
-	lb := newLoadbalancers(client, region).(*loadbalancers)
-
-	fakeAPI.ResetRequests()
-	t.Run("non-annotated service shouldn't call the API during cleanup", func(t *testing.T) {
-		if err := lb.cleanupOldNodeBalancer(context.TODO(), svc); err != nil {
-			t.Fatal(err)
-		}
-		if len(fakeAPI.requests) != 0 {
-			t.Fatalf("unexpected API calls: %v", fakeAPI.requests)
-		}
-	})
-
-	fakeAPI.ResetRequests()
-	t.Run("annotated service calls the API to load said NB", func(t *testing.T) {
-		if err := lb.cleanupOldNodeBalancer(context.TODO(), svcAnn); err != nil {
-			t.Fatal(err)
-		}
-		expectedRequests := map[fakeRequest]struct{}{
-			{Path: "/nodebalancers", Body: "", Method: "GET"}:                            {},
-			{Path: fmt.Sprintf("/nodebalancers/%v", nb2.ID), Body: "", Method: "GET"}:    {},
-			{Path: fmt.Sprintf("/nodebalancers/%v", nb1.ID), Body: "", Method: "DELETE"}: {},
-		}
-		if !reflect.DeepEqual(fakeAPI.requests, expectedRequests) {
-			t.Fatalf("expected requests %#v, got %#v instead", expectedRequests, fakeAPI.requests)
-		}
-	})
+	svc.Status.LoadBalancer = *lbStatus
 }
 
-func testUpdateLoadBalancerNoNodes(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+func testUpdateLoadBalancerExternalTrafficPolicy(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	svc := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:        randString(),
-			UID:         "foobar123",
-			Annotations: map[string]string{},
+			Name:       randString(),
+			UID:        "foobar123",
+			Generation: 1,
+			Annotations: map[string]string{
+				annotations.AnnLinodeHealthCheckType: string(linodego.CheckNone),
+			},
 		},
 		Spec: v1.ServiceSpec{
 			Ports: []v1.ServicePort{
 				{
 					Name:     randString(),
-					Protocol: "http",
+					Protocol: "TCP",
 					Port:     int32(80),
-					NodePort: int32(8080),
+					NodePort: int32(30000),
 				},
 			},
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyCluster,
 		},
 	}
 
-	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
-	defer func() {
-		_ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc)
-	}()
+	newNode := func(name, address string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: address,
+					},
+				},
+			},
+		}
+	}
+	nodes := []*v1.Node{newNode("node-1", "127.0.0.1"), newNode("node-2", "127.0.0.2")}
 
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
 	fakeClientset := fake.NewSimpleClientset()
 	lb.kubeClient = fakeClientset
 
-	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
-		Region: lb.zone,
-	})
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
 	if err != nil {
-		t.Fatalf("failed to create NodeBalancer: %s", err)
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
 	}
-	svc.Status.LoadBalancer = *makeLoadBalancerStatus(svc, nodeBalancer)
+	svc.Status.LoadBalancer = *lbStatus
+	defer func() { _ = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc) }()
 	stubService(fakeClientset, svc)
-	svc.ObjectMeta.SetAnnotations(map[string]string{
-		annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nodeBalancer.ID),
-	})
-
-	// setup done, test ensure/update
-	nodes := []*v1.Node{}
 
-	if _, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, errNoNodesAvailable) {
-		t.Errorf("EnsureLoadBalancer should return %v, got %v", errNoNodesAvailable, err)
+	nb, err := lb.getNodeBalancerByStatus(context.TODO(), svc)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer by status: %v", err)
 	}
 
-	if err := lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); !stderrors.Is(err, errNoNodesAvailable) {
-		t.Errorf("UpdateLoadBalancer should return %v, got %v", errNoNodesAvailable, err)
+	expect := func(check linodego.ConfigCheck, backends ...string) {
+		t.Helper()
+		cfgs, err := client.ListNodeBalancerConfigs(context.TODO(), nb.ID, nil)
+		if err != nil || len(cfgs) != 1 {
+			t.Fatalf("expected a single NodeBalancer config, got %v (error: %v)", cfgs, err)
+		}
+		if cfgs[0].Check != check {
+			t.Errorf("expected health check %s, got %s", check, cfgs[0].Check)
+		}
+		nbNodes, err := client.ListNodeBalancerNodes(context.TODO(), nb.ID, cfgs[0].ID, nil)
+		if err != nil {
+			t.Fatalf("error getting NodeBalancer nodes: %v", err)
+		}
+		addresses := make([]string, 0, len(nbNodes))
+		for _, n := range nbNodes {
+			addresses = append(addresses, n.Address)
+		}
+		slices.Sort(addresses)
+		if !reflect.DeepEqual(addresses, backends) {
+			t.Errorf("expected backends %v, got %v", backends, addresses)
+		}
+	}
+	setPolicy := func(policy v1.ServiceExternalTrafficPolicy) {
+		t.Helper()
+		svc.Spec.ExternalTrafficPolicy = policy
+		svc.Generation++
+		if err = lb.UpdateLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+			t.Fatalf("UpdateLoadBalancer returned an error: %s", err)
+		}
 	}
+
+	expect(linodego.CheckNone, "127.0.0.1:30000", "127.0.0.2:30000")
+
+	// nodes without a ready endpoint stay backends and are taken out by the
+	// connection health checks
+	setPolicy(v1.ServiceExternalTrafficPolicyLocal)
+	expect(linodego.CheckConnection, "127.0.0.1:30000", "127.0.0.2:30000")
+
+	setPolicy(v1.ServiceExternalTrafficPolicyCluster)
+	expect(linodego.CheckNone, "127.0.0.1:30000", "127.0.0.2:30000")
 }
 
 func testGetNodeBalancerForServiceIDDoesNotExist(t *testing.T, client *linodego.Client, _ *fakeAPI) {
@@ -3056,6 +9393,180 @@ func testGetNodeBalancerForServiceIDDoesNotExist(t *testing.T, client *linodego.
 	}
 }
 
+func testEnsureLoadBalancerDeletedReleasesNodeBalancerID(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+
+	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+		Region: lb.zone,
+		Tags:   []string{"managed-by-terraform"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create NodeBalancer: %s", err)
+	}
+	defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nodeBalancer.ID) }()
+	if _, err = client.CreateNodeBalancerConfig(context.TODO(), nodeBalancer.ID, linodego.NodeBalancerConfigCreateOptions{Port: 9000, Protocol: linodego.ProtocolTCP}); err != nil {
+		t.Fatalf("failed to create NodeBalancer config: %s", err)
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nodeBalancer.ID),
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Name:     "test",
+					Protocol: "TCP",
+					Port:     int32(80),
+					NodePort: int32(30000),
+				},
+			},
+		},
+	}
+	nodes := []*v1.Node{nodeInRegion("node-1", "us-west")}
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+
+	if err = lb.EnsureLoadBalancerDeleted(context.TODO(), "linodelb", svc); err != nil {
+		t.Fatalf("EnsureLoadBalancerDeleted returned an error: %s", err)
+	}
+	if f.didRequestOccur(http.MethodDelete, fmt.Sprintf("/nodebalancers/%d", nodeBalancer.ID), "") {
+		t.Fatal("expected the NodeBalancer not created for the service to be kept")
+	}
+
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nodeBalancer.ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer configs: %s", err)
+	}
+	if len(configs) != 1 || configs[0].Port != 9000 {
+		t.Errorf("expected only the config not created by the CCM to be kept, got %v", configs)
+	}
+	nb, err := client.GetNodeBalancer(context.TODO(), nodeBalancer.ID)
+	if err != nil {
+		t.Fatalf("failed to get NodeBalancer: %s", err)
+	}
+	for _, tag := range nb.Tags {
+		if strings.HasPrefix(tag, configPortTagPrefix) {
+			t.Errorf("expected the config port tags to be removed, got %v", nb.Tags)
+		}
+	}
+	assert.Contains(t, nb.Tags, "managed-by-terraform")
+
+	var released bool
+	for len(recorder.Events) > 0 {
+		released = released || strings.Contains(<-recorder.Events, eventReasonNodeBalancerReleased)
+	}
+	assert.True(t, released, "expected a %s event", eventReasonNodeBalancerReleased)
+}
+
+func testUpdateLoadBalancerSwitchNodeBalancerID(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	fakeClientset := fake.NewSimpleClientset()
+	lb.kubeClient = fakeClientset
+	lb.eventRecorder = record.NewFakeRecorder(100)
+
+	// both NodeBalancers are provisioned by the user
+	var nodeBalancers []*linodego.NodeBalancer
+	for range 2 {
+		nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
+			Region: lb.zone,
+			Tags:   []string{"managed-by-terraform"},
+		})
+		if err != nil {
+			t.Fatalf("failed to create NodeBalancer: %s", err)
+		}
+		defer func() { _ = client.DeleteNodeBalancer(context.TODO(), nodeBalancer.ID) }()
+		nodeBalancers = append(nodeBalancers, nodeBalancer)
+	}
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeNodeBalancerID: strconv.Itoa(nodeBalancers[0].ID),
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{Name: "test", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)}},
+		},
+	}
+	nodes := []*v1.Node{nodeInRegion("node-1", "us-west")}
+
+	lbStatus, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes)
+	if err != nil {
+		t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+	}
+	svc.Status.LoadBalancer = *lbStatus
+	stubService(fakeClientset, svc)
+
+	for _, next := range []*linodego.NodeBalancer{nodeBalancers[1], nodeBalancers[0]} {
+		svc.Annotations[annotations.AnnLinodeNodeBalancerID] = strconv.Itoa(next.ID)
+		if lbStatus, err = lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, nodes); err != nil {
+			t.Fatalf("EnsureLoadBalancer returned an error: %s", err)
+		}
+		svc.Status.LoadBalancer = *lbStatus
+	}
+
+	for _, nodeBalancer := range nodeBalancers {
+		if f.didRequestOccur(http.MethodDelete, fmt.Sprintf("/nodebalancers/%d", nodeBalancer.ID), "") {
+			t.Errorf("expected NodeBalancer (%d) not created by the CCM to be kept", nodeBalancer.ID)
+		}
+		nb, err := client.GetNodeBalancer(context.TODO(), nodeBalancer.ID)
+		if err != nil {
+			t.Fatalf("expected NodeBalancer (%d) to still exist: %s", nodeBalancer.ID, err)
+		}
+		assert.Contains(t, nb.Tags, "managed-by-terraform")
+	}
+
+	// the NodeBalancer switched away from no longer has the configs of the service
+	configs, err := client.ListNodeBalancerConfigs(context.TODO(), nodeBalancers[1].ID, nil)
+	if err != nil {
+		t.Fatalf("failed to list NodeBalancer configs: %s", err)
+	}
+	assert.Empty(t, configs)
+}
+
+func testEnsureLoadBalancerNodeBalancerIDNotFound(t *testing.T, client *linodego.Client, f *fakeAPI) {
+	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
+	lb.kubeClient = fake.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+	lb.eventRecorder = recorder
+
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: randString(),
+			UID:  "foobar123",
+			Annotations: map[string]string{
+				annotations.AnnLinodeNodeBalancerID: "123456789",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{Name: "test", Protocol: "TCP", Port: int32(80), NodePort: int32(30000)}},
+		},
+	}
+
+	nbCount := len(f.nb)
+	_, err := lb.EnsureLoadBalancer(context.TODO(), "linodelb", svc, []*v1.Node{nodeInRegion("node-1", "us-west")})
+	assert.Equal(t, lbNotFoundError{serviceNn: getServiceNn(svc), nodeBalancerID: 123456789}, err)
+	assert.Len(t, f.nb, nbCount, "expected no NodeBalancer to be created in place of the missing one")
+
+	event := <-recorder.Events
+	assert.Contains(t, event, eventReasonNodeBalancerNotFound)
+	assert.Contains(t, event, "NodeBalancer (123456789) selected by the")
+}
+
 func testEnsureNewLoadBalancerWithNodeBalancerID(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	lb := newLoadbalancers(client, "us-west").(*loadbalancers)
 	nodeBalancer, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{
@@ -3186,7 +9697,7 @@ func testGetLoadBalancer(t *testing.T, client *linodego.Client, _ *fakeAPI) {
 	}
 
 	configs := []*linodego.NodeBalancerConfigCreateOptions{}
-	nb, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, configs)
+	nb, err := lb.createNodeBalancer(context.TODO(), "linodelb", svc, lb.zone, configs)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -3410,6 +9921,58 @@ func Test_getTLSCertInfo(t *testing.T) {
 	}
 }
 
+func Test_getTLSCertInfoNamespace(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	for _, namespace := range []string{"default", "other"} {
+		_, err := kubeClient.CoreV1().Secrets(namespace).Create(context.TODO(), &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-secret", Namespace: namespace},
+			Data: map[string][]byte{
+				v1.TLSCertKey:       []byte(testCert),
+				v1.TLSPrivateKeyKey: []byte(testKey),
+			},
+			Type: "kubernetes.io/tls",
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("failed to add TLS secret: %s", err)
+		}
+	}
+	defer func() { Options.AllowCrossNamespaceTLSSecrets = false }()
+
+	testcases := []struct {
+		name       string
+		secretName string
+		allowCross bool
+		err        error
+	}{
+		{"same namespace", "tls-secret", false, nil},
+		{"same namespace reference", "default/tls-secret", false, nil},
+		{"cross-namespace reference", "other/tls-secret", false, errCrossNamespaceSecret},
+		{"allowed cross-namespace reference", "other/tls-secret", true, nil},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			Options.AllowCrossNamespaceTLSSecrets = test.allowCross
+			cert, _, err := getTLSCertInfo(context.TODO(), kubeClient, "default", portConfig{TLSSecretName: test.secretName, Port: 443})
+			if test.err != nil {
+				if !stderrors.Is(err, test.err) {
+					t.Fatalf("expected %v, got %v", test.err, err)
+				}
+				if cert != "" {
+					t.Error("expected the secret not to be read")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if cert != testCert {
+				t.Errorf("unexpected certificate %q", cert)
+			}
+		})
+	}
+}
+
 func addTLSSecret(t *testing.T, kubeClient kubernetes.Interface) {
 	_, err := kubeClient.CoreV1().Secrets("").Create(context.TODO(), &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -3467,3 +10030,181 @@ func Test_LoadbalNodeNameCoercion(t *testing.T) {
 		}
 	}
 }
+
+func Test_getCheckPath(t *testing.T) {
+	Options.DefaultCheckPaths = map[string]string{"http": "/healthz"}
+	defer func() { Options.DefaultCheckPaths = nil }()
+
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		protocol    linodego.ConfigProtocol
+		expected    string
+	}{
+		{
+			name:     "cluster-wide default for the protocol",
+			protocol: linodego.ProtocolHTTP,
+			expected: "/healthz",
+		},
+		{
+			name:     "no default for the protocol",
+			protocol: linodego.ProtocolHTTPS,
+			expected: "/",
+		},
+		{
+			name:        "annotation overrides the default",
+			annotations: map[string]string{annotations.AnnLinodeCheckPath: "/ready"},
+			protocol:    linodego.ProtocolHTTP,
+			expected:    "/ready",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			if path := getCheckPath(svc, tc.protocol); path != tc.expected {
+				t.Errorf("expected check path %q, got %q", tc.expected, path)
+			}
+		})
+	}
+}
+
+func Test_nodeBalancerIPRetryAfter(t *testing.T) {
+	Options.NodeBalancerIPRequeueInterval = 2 * time.Second
+	defer func() { Options.NodeBalancerIPRequeueInterval = 0 }()
+
+	now := time.Now()
+	created := func(age time.Duration) *time.Time {
+		c := now.Add(-age)
+		return &c
+	}
+	testcases := []struct {
+		name     string
+		created  *time.Time
+		expected time.Duration
+	}{
+		{name: "unknown creation time", expected: 2 * time.Second},
+		{name: "new NodeBalancer", created: created(time.Second), expected: 2 * time.Second},
+		{name: "grows with age", created: created(10 * time.Second), expected: 10 * time.Second},
+		{name: "capped", created: created(time.Hour), expected: 32 * time.Second},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			nb := &linodego.NodeBalancer{Created: tc.created}
+			if retryAfter := nodeBalancerIPRetryAfter(nb, now); retryAfter != tc.expected {
+				t.Errorf("expected to retry after %s, got %s", tc.expected, retryAfter)
+			}
+		})
+	}
+}
+
+func Test_scaleCheckInterval(t *testing.T) {
+	testcases := []struct {
+		name     string
+		interval int
+		timeout  int
+		backends int
+		expected int
+	}{
+		{name: "no backends", interval: 5, timeout: 3, backends: 0, expected: 5},
+		{name: "single batch", interval: 5, timeout: 3, backends: 10, expected: 5},
+		{name: "partial second batch", interval: 5, timeout: 3, backends: 11, expected: 10},
+		{name: "many backends", interval: 5, timeout: 3, backends: 95, expected: 50},
+		{name: "capped at maximum", interval: 300, timeout: 3, backends: 200, expected: maxCheckInterval},
+		{name: "raised above timeout", interval: 1, timeout: 3, backends: 10, expected: 4},
+		{name: "raised to minimum", interval: 1, timeout: 0, backends: 1, expected: minCheckInterval},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			if interval := scaleCheckInterval(test.interval, test.timeout, test.backends); interval != test.expected {
+				t.Errorf("expected check interval %d, got %d", test.expected, interval)
+			}
+		})
+	}
+
+	t.Run("interval does not decrease with more backends", func(t *testing.T) {
+		previous := 0
+		for backends := 1; backends <= 1000; backends++ {
+			interval := scaleCheckInterval(5, 3, backends)
+			if interval < previous || interval < minCheckInterval || interval > maxCheckInterval {
+				t.Fatalf("unexpected check interval %d for %d backends (previous %d)", interval, backends, previous)
+			}
+			previous = interval
+		}
+	})
+
+	t.Run("applied when annotated", func(t *testing.T) {
+		lb := &loadbalancers{}
+		svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			annotations.AnnLinodeHealthCheckInterval: "5",
+		}}}
+
+		config, err := lb.buildNodeBalancerConfig(context.TODO(), svc, 80, 25)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if config.CheckInterval != 5 {
+			t.Errorf("expected unscaled check interval 5, got %d", config.CheckInterval)
+		}
+
+		svc.Annotations[annotations.AnnLinodeHealthCheckIntervalScaling] = "true"
+		config, err = lb.buildNodeBalancerConfig(context.TODO(), svc, 80, 25)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if config.CheckInterval != 15 {
+			t.Errorf("expected scaled check interval 15, got %d", config.CheckInterval)
+		}
+	})
+}
+
+func testMigrateClusterTag(t *testing.T, client *linodego.Client, _ *fakeAPI) {
+	createNodeBalancer := func(tags ...string) int {
+		nb, err := client.CreateNodeBalancer(context.TODO(), linodego.NodeBalancerCreateOptions{Region: "us-west", Tags: tags})
+		if err != nil {
+			t.Fatalf("failed to create NodeBalancer: %s", err)
+		}
+		return nb.ID
+	}
+	old := createNodeBalancer("old-cluster", "team:payments", ownerTagPrefix+"abc123")
+	both := createNodeBalancer("old-cluster", "new-cluster")
+	other := createNodeBalancer("other-cluster")
+
+	assertTags := func(expected map[int][]string) {
+		t.Helper()
+		for id, tags := range expected {
+			nb, err := client.GetNodeBalancer(context.TODO(), id)
+			if err != nil {
+				t.Fatalf("failed to get NodeBalancer (%d): %s", id, err)
+			}
+			if !reflect.DeepEqual(nb.Tags, tags) {
+				t.Errorf("expected NodeBalancer (%d) tags %v, got %v", id, tags, nb.Tags)
+			}
+		}
+	}
+
+	defer func() { Options.ClusterID, Options.PreviousClusterID = "", "" }()
+
+	// without a current cluster ID there is nothing to re-tag with
+	Options.ClusterID, Options.PreviousClusterID = "", "old-cluster"
+	migrateClusterTag(client)
+	assertTags(map[int][]string{old: {"old-cluster", "team:payments", ownerTagPrefix + "abc123"}})
+
+	Options.ClusterID = "new-cluster"
+	migrateClusterTag(client)
+	assertTags(map[int][]string{
+		old:   {"new-cluster", "team:payments", ownerTagPrefix + "abc123"},
+		both:  {"new-cluster"},
+		other: {"other-cluster"},
+	})
+
+	retagged, err := retagNodeBalancers(context.TODO(), client, "old-cluster", "new-cluster")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if retagged != 0 {
+		t.Errorf("expected nothing left to re-tag, got %d re-tagged", retagged)
+	}
+}
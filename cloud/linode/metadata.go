@@ -0,0 +1,109 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// metadataServiceURL is the Linode metadata service, reachable from
+	// within linodes for which it is enabled.
+	metadataServiceURL = "http://169.254.169.254/v1"
+
+	// metadataTokenExpiry is how long the metadata tokens requested by the
+	// CCM are valid, in seconds; a token is only used for a single request.
+	metadataTokenExpiry = "60"
+
+	// nodeNameEnv is the name of the node the CCM runs on, typically set from
+	// spec.nodeName with the downward API; the hostname is used when unset.
+	nodeNameEnv = "NODE_NAME"
+)
+
+// metadataClient reads the ID of the linode the CCM runs on from the Linode
+// metadata service. The ID is cached once read, as it never changes.
+type metadataClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu sync.Mutex
+	id int
+}
+
+func newMetadataClient() *metadataClient {
+	return &metadataClient{
+		baseURL:    metadataServiceURL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// instanceID returns the ID of the linode the CCM runs on.
+func (m *metadataClient) instanceID(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.id != 0 {
+		return m.id, nil
+	}
+
+	token, err := m.do(ctx, http.MethodPut, "/token", map[string]string{"Metadata-Token-Expiry-Seconds": metadataTokenExpiry})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get a metadata service token: %w", err)
+	}
+	body, err := m.do(ctx, http.MethodGet, "/instance", map[string]string{"Metadata-Token": strings.TrimSpace(string(token)), "Accept": "application/json"})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the instance from the metadata service: %w", err)
+	}
+
+	var instance struct {
+		ID int `json:"id"`
+	}
+	if err = json.Unmarshal(body, &instance); err != nil {
+		return 0, fmt.Errorf("failed to decode the instance from the metadata service: %w", err)
+	}
+	if instance.ID == 0 {
+		return 0, fmt.Errorf("the metadata service reported no instance ID")
+	}
+	m.id = instance.ID
+	return m.id, nil
+}
+
+// do sends a request to the metadata service and returns the response body.
+func (m *metadataClient) do(ctx context.Context, method, path string, header map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+path, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return body, nil
+}
+
+// localNodeName returns the name of the node the CCM runs on.
+func localNodeName() string {
+	if name := os.Getenv(nodeNameEnv); name != "" {
+		return name
+	}
+	name, _ := os.Hostname()
+	return name
+}
@@ -0,0 +1,126 @@
+package linode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
+)
+
+// newFakeMetadataService serves the token and instance endpoints of the
+// metadata service, reporting the instance as body. It counts the instance
+// requests in requests.
+func newFakeMetadataService(t *testing.T, body string, requests *int32) *metadataClient {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /v1/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Token-Expiry-Seconds") == "" {
+			http.Error(w, "missing expiry", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("some-token"))
+	})
+	mux.HandleFunc("GET /v1/instance", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.Header.Get("Metadata-Token") != "some-token" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	metadata := newMetadataClient()
+	metadata.baseURL = server.URL + "/v1"
+	return metadata
+}
+
+func TestMetadataClient(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("reads and caches the instance ID", func(t *testing.T) {
+		var requests int32
+		metadata := newFakeMetadataService(t, `{"id": 123, "label": "some-label"}`, &requests)
+
+		for range 2 {
+			id, err := metadata.instanceID(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 123, id)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("fails without an instance ID", func(t *testing.T) {
+		var requests int32
+		metadata := newFakeMetadataService(t, `{}`, &requests)
+
+		_, err := metadata.instanceID(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("fails when the service is unreachable", func(t *testing.T) {
+		metadata := newMetadataClient()
+		metadata.baseURL = "http://127.0.0.1:0/v1"
+
+		_, err := metadata.instanceID(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestLookupLocalLinode(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+	linodes := []linodego.Instance{
+		{ID: 123, Label: "custom-hostname"},
+		{ID: 456, Label: "some-node"},
+	}
+
+	t.Run("local node is looked up by the metadata service ID", func(t *testing.T) {
+		var requests int32
+		instances := newInstances(client)
+		instances.metadata = newFakeMetadataService(t, `{"id": 123}`, &requests)
+		instances.localNodeName = "some-node"
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return(linodes, nil)
+
+		linode, err := instances.lookupLinode(ctx, nodeWithName("some-node"))
+		assert.NoError(t, err)
+		assert.Equal(t, 123, linode.ID)
+	})
+
+	t.Run("other nodes are looked up by name", func(t *testing.T) {
+		var requests int32
+		instances := newInstances(client)
+		instances.metadata = newFakeMetadataService(t, `{"id": 123}`, &requests)
+		instances.localNodeName = "other-node"
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return(linodes, nil)
+
+		linode, err := instances.lookupLinode(ctx, nodeWithName("some-node"))
+		assert.NoError(t, err)
+		assert.Equal(t, 456, linode.ID)
+		assert.Zero(t, atomic.LoadInt32(&requests))
+	})
+
+	t.Run("falls back to the name when the metadata service fails", func(t *testing.T) {
+		var requests int32
+		instances := newInstances(client)
+		instances.metadata = newFakeMetadataService(t, `{}`, &requests)
+		instances.localNodeName = "some-node"
+		client.EXPECT().ListInstances(gomock.Any(), nil).Times(1).Return(linodes, nil)
+
+		linode, err := instances.lookupLinode(ctx, nodeWithName("some-node"))
+		assert.NoError(t, err)
+		assert.Equal(t, 456, linode.ID)
+	})
+}
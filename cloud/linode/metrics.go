@@ -0,0 +1,146 @@
+package linode
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+	cloudprovider "k8s.io/cloud-provider"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	certExpirySeconds = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Name:           "ccm_nodebalancer_cert_expiry_seconds",
+			Help:           "Seconds until the TLS certificate of a NodeBalancer config expires",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "port"},
+	)
+
+	instanceLookupTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "ccm_instance_lookup_total",
+			Help:           "Number of lookups of the linode backing a node, by result",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+
+	instanceLookupDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Name:           "ccm_instance_lookup_duration_seconds",
+			Help:           "Duration of lookups of the linode backing a node, including refreshing the instance cache",
+			Buckets:        metrics.DefBuckets,
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	instanceAPIErrorsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Name:           "ccm_instance_api_errors_total",
+			Help:           "Number of Linode API errors while listing instances, by HTTP status code",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"code"},
+	)
+
+	nodeBalancerTransferUsed = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Name:           "ccm_nodebalancer_transfer_used_megabytes",
+			Help:           "Network transfer used by the NodeBalancer of a service this month, in MB",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service"},
+	)
+
+	nodeBalancerConfigCount = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Name:           "ccm_nodebalancer_config_count",
+			Help:           "Number of configs (ports) of the NodeBalancer of a service",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service", "nodebalancer_id"},
+	)
+
+	regionMismatch = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Name:           "ccm_loadbalancer_region_mismatch",
+			Help:           "Whether the NodeBalancer of a service is outside of the region of most of its backend nodes",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"service"},
+	)
+
+	accountNodeBalancersUsed = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Name:           "ccm_account_nodebalancers_used",
+			Help:           "Number of NodeBalancers on the Linode account",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	accountNodeBalancersLimit = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Name:           "ccm_account_nodebalancers_limit",
+			Help:           "Maximum number of NodeBalancers on the Linode account, as configured on the CCM",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// results of instance lookups
+const (
+	instanceLookupFound    = "found"
+	instanceLookupNotFound = "not_found"
+	instanceLookupError    = "error"
+)
+
+// registerMetrics registers the CCM metrics with the legacy registry, which is
+// served by the cloud-controller-manager metrics endpoint.
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(
+			certExpirySeconds,
+			instanceLookupTotal,
+			instanceLookupDuration,
+			instanceAPIErrorsTotal,
+			nodeBalancerTransferUsed,
+			nodeBalancerConfigCount,
+			regionMismatch,
+			accountNodeBalancersUsed,
+			accountNodeBalancersLimit,
+		)
+	})
+}
+
+// observeInstanceLookup records the result and duration of an instance lookup
+// started at start.
+func observeInstanceLookup(start time.Time, err error) {
+	result := instanceLookupFound
+	switch {
+	case errors.Is(err, cloudprovider.InstanceNotFound):
+		result = instanceLookupNotFound
+	case err != nil:
+		result = instanceLookupError
+	}
+	instanceLookupTotal.WithLabelValues(result).Inc()
+	instanceLookupDuration.Observe(time.Since(start).Seconds())
+}
+
+// observeInstanceAPIError records a Linode API error by its HTTP status code.
+func observeInstanceAPIError(err error) {
+	code := "unknown"
+	var apiErr *linodego.Error
+	if errors.As(err, &apiErr) && apiErr.Code != 0 {
+		code = strconv.Itoa(apiErr.Code)
+	}
+	instanceAPIErrorsTotal.WithLabelValues(code).Inc()
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,39 @@ const (
 	defaultMetadataTTL   = 300 * time.Second
 )
 
+// classes of Linode types which nodes are labelled with
+const (
+	instanceClassStandard  = "standard"
+	instanceClassDedicated = "dedicated"
+	instanceClassGPU       = "gpu"
+	instanceClassHighMem   = "highmem"
+	instanceClassOther     = "other"
+)
+
+// instanceClasses maps the family of a Linode type, e.g. dedicated in
+// g6-dedicated-2, to its class.
+var instanceClasses = map[string]string{
+	"nanode":    instanceClassStandard,
+	"standard":  instanceClassStandard,
+	"dedicated": instanceClassDedicated,
+	"premium":   instanceClassDedicated,
+	"gpu":       instanceClassGPU,
+	"highmem":   instanceClassHighMem,
+}
+
+// getInstanceClass returns the class of linodeType, or other for types of an
+// unknown family.
+func getInstanceClass(linodeType string) string {
+	parts := strings.SplitN(linodeType, "-", 3)
+	if len(parts) < 2 {
+		return instanceClassOther
+	}
+	if class, ok := instanceClasses[parts[1]]; ok {
+		return class
+	}
+	return instanceClassOther
+}
+
 type nodeController struct {
 	sync.RWMutex
 
@@ -42,7 +76,10 @@ type nodeController struct {
 	queue workqueue.DelayingInterface
 }
 
-func newNodeController(kubeclient kubernetes.Interface, client client.Client, informer v1informers.NodeInformer) *nodeController {
+// newNodeController returns a controller looking up the linodes of nodes with
+// instances, which is shared with the cloud provider so that its caches and
+// lookup limit apply to both.
+func newNodeController(kubeclient kubernetes.Interface, instances *instances, informer v1informers.NodeInformer) *nodeController {
 	timeout := defaultMetadataTTL
 	if raw, ok := os.LookupEnv("LINODE_METADATA_TTL"); ok {
 		if t, _ := strconv.Atoi(raw); t > 0 {
@@ -51,8 +88,8 @@ func newNodeController(kubeclient kubernetes.Interface, client client.Client, in
 	}
 
 	return &nodeController{
-		client:             client,
-		instances:          newInstances(client),
+		client:             instances.client,
+		instances:          instances,
 		kubeclient:         kubeclient,
 		informer:           informer,
 		ttl:                timeout,
@@ -82,6 +119,17 @@ func (s *nodeController) Run(stopCh <-chan struct{}) {
 				klog.Infof("NodeController will handle newly updated node (%s) metadata", node.Name)
 				s.queue.Add(node)
 			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				node, ok := obj.(*v1.Node)
+				if !ok {
+					return
+				}
+
+				s.instances.forgetNode(node.Name)
+			},
 		},
 		informerResyncPeriod,
 	); err != nil {
@@ -178,7 +226,13 @@ func (s *nodeController) handleNode(ctx context.Context, node *v1.Node) error {
 		}
 	}
 
-	if uuid == linode.HostUUID && node.Spec.ProviderID != "" && configuredPrivateIP == expectedPrivateIP {
+	expectedClass := ""
+	if Options.InstanceClassLabel {
+		expectedClass = getInstanceClass(linode.Type)
+	}
+
+	if uuid == linode.HostUUID && node.Spec.ProviderID != "" && configuredPrivateIP == expectedPrivateIP &&
+		(expectedClass == "" || node.Labels[annotations.AnnLinodeInstanceClass] == expectedClass) {
 		s.SetLastMetadataUpdate(node.Name)
 		return nil
 	}
@@ -195,6 +249,11 @@ func (s *nodeController) handleNode(ctx context.Context, node *v1.Node) error {
 			n.Labels[annotations.AnnLinodeHostUUID] = linode.HostUUID
 		}
 
+		// Try to update the instance class if it is enabled and doesn't match
+		if expectedClass != "" && n.Labels[annotations.AnnLinodeInstanceClass] != expectedClass {
+			n.Labels[annotations.AnnLinodeInstanceClass] = expectedClass
+		}
+
 		// Try to update the node ProviderID if it has not been set
 		if n.Spec.ProviderID == "" {
 			n.Spec.ProviderID = providerIDPrefix + strconv.Itoa(linode.ID)
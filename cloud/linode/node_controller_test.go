@@ -0,0 +1,76 @@
+package linode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+	"github.com/linode/linode-cloud-controller-manager/cloud/linode/client/mocks"
+)
+
+func TestGetInstanceClass(t *testing.T) {
+	testcases := map[string]string{
+		"g6-nanode-1":         instanceClassStandard,
+		"g6-standard-2":       instanceClassStandard,
+		"g6-dedicated-4":      instanceClassDedicated,
+		"g7-premium-8":        instanceClassDedicated,
+		"g1-gpu-rtx6000-1":    instanceClassGPU,
+		"g2-gpu-rtx4000a1-s":  instanceClassGPU,
+		"g7-highmem-1":        instanceClassHighMem,
+		"g8-quantum-1":        instanceClassOther,
+		"standard":            instanceClassOther,
+		"":                    instanceClassOther,
+		"g6-dedicated-edge-2": instanceClassDedicated,
+	}
+
+	for linodeType, expected := range testcases {
+		assert.Equal(t, expected, getInstanceClass(linodeType), "class of type %q", linodeType)
+	}
+}
+
+func TestNodeControllerInstanceClassLabel(t *testing.T) {
+	ctx := context.TODO()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockClient(ctrl)
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "mock-instance",
+			Labels: map[string]string{annotations.AnnLinodeHostUUID: "uuid"},
+		},
+		Spec: v1.NodeSpec{ProviderID: providerIDPrefix + "123"},
+	}
+	kubeClient := fake.NewSimpleClientset(node)
+	controller := newNodeController(kubeClient, newInstances(client), nil)
+
+	client.EXPECT().ListInstances(gomock.Any(), nil).AnyTimes().Return([]linodego.Instance{
+		{ID: 123, Label: "mock-instance", Type: "g6-dedicated-2", HostUUID: "uuid"},
+	}, nil)
+
+	defer func() { Options.InstanceClassLabel = false }()
+
+	t.Run("not labelled by default", func(t *testing.T) {
+		assert.NoError(t, controller.handleNode(ctx, node))
+
+		updated, err := kubeClient.CoreV1().Nodes().Get(ctx, node.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.NotContains(t, updated.Labels, annotations.AnnLinodeInstanceClass)
+	})
+
+	t.Run("labelled with the class of its type", func(t *testing.T) {
+		Options.InstanceClassLabel = true
+		assert.NoError(t, controller.handleNode(ctx, node))
+
+		updated, err := kubeClient.CoreV1().Nodes().Get(ctx, node.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, instanceClassDedicated, updated.Labels[annotations.AnnLinodeInstanceClass])
+	})
+}
@@ -0,0 +1,72 @@
+package linode
+
+import (
+	"github.com/linode/linodego"
+)
+
+// nodeBalancerConfigOptions are the options of the API calls creating,
+// updating and rebuilding a NodeBalancer config. They are all built by
+// newNodeBalancerConfigOptions, so that adapting to changes of the linodego
+// options, such as new fields, is done in a single place.
+type nodeBalancerConfigOptions struct {
+	create  linodego.NodeBalancerConfigCreateOptions
+	update  linodego.NodeBalancerConfigUpdateOptions
+	rebuild linodego.NodeBalancerConfigRebuildOptions
+}
+
+// newNodeBalancerConfigOptions returns the options setting config, with nodes
+// as its backends. The protocols default to TCP without proxy protocol, as for
+// Services without annotations. Nodes are only sent on create and rebuild,
+// and the IDs of nodes only on rebuild, where they identify existing backends.
+func newNodeBalancerConfigOptions(config linodego.NodeBalancerConfig, nodes []linodego.NodeBalancerConfigRebuildNodeOptions) nodeBalancerConfigOptions {
+	if config.Protocol == "" {
+		config.Protocol = linodego.ProtocolTCP
+	}
+	if config.ProxyProtocol == "" {
+		config.ProxyProtocol = linodego.ProxyProtocolNone
+	}
+	checkPassive := config.CheckPassive
+
+	create := linodego.NodeBalancerConfigCreateOptions{
+		Port:          config.Port,
+		Protocol:      config.Protocol,
+		ProxyProtocol: config.ProxyProtocol,
+		Algorithm:     config.Algorithm,
+		Stickiness:    config.Stickiness,
+		Check:         config.Check,
+		CheckInterval: config.CheckInterval,
+		CheckAttempts: config.CheckAttempts,
+		CheckPath:     config.CheckPath,
+		CheckBody:     config.CheckBody,
+		CheckPassive:  &checkPassive,
+		CheckTimeout:  config.CheckTimeout,
+		CipherSuite:   config.CipherSuite,
+		SSLCert:       config.SSLCert,
+		SSLKey:        config.SSLKey,
+	}
+	update := linodego.NodeBalancerConfigUpdateOptions(create)
+
+	rebuild := linodego.NodeBalancerConfigRebuildOptions{
+		Port:          create.Port,
+		Protocol:      create.Protocol,
+		ProxyProtocol: create.ProxyProtocol,
+		Algorithm:     create.Algorithm,
+		Stickiness:    create.Stickiness,
+		Check:         create.Check,
+		CheckInterval: create.CheckInterval,
+		CheckAttempts: create.CheckAttempts,
+		CheckPath:     create.CheckPath,
+		CheckBody:     create.CheckBody,
+		CheckPassive:  create.CheckPassive,
+		CheckTimeout:  create.CheckTimeout,
+		CipherSuite:   create.CipherSuite,
+		SSLCert:       create.SSLCert,
+		SSLKey:        create.SSLKey,
+		Nodes:         nodes,
+	}
+	for _, node := range nodes {
+		create.Nodes = append(create.Nodes, node.NodeBalancerNodeCreateOptions)
+	}
+
+	return nodeBalancerConfigOptions{create: create, update: update, rebuild: rebuild}
+}
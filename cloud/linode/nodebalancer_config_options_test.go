@@ -0,0 +1,176 @@
+package linode
+
+import (
+	"testing"
+
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNodeBalancerConfigOptions(t *testing.T) {
+	nodes := []linodego.NodeBalancerConfigRebuildNodeOptions{
+		{
+			NodeBalancerNodeCreateOptions: linodego.NodeBalancerNodeCreateOptions{
+				Address: "192.168.0.1:30000",
+				Label:   "node-1",
+				Weight:  100,
+				Mode:    linodego.ModeAccept,
+			},
+			ID: 1,
+		},
+		{
+			NodeBalancerNodeCreateOptions: linodego.NodeBalancerNodeCreateOptions{
+				Address: "192.168.0.2:30000",
+				Label:   "node-2",
+				Weight:  100,
+				Mode:    linodego.ModeAccept,
+			},
+		},
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		opts := newNodeBalancerConfigOptions(linodego.NodeBalancerConfig{Port: 80}, nil)
+
+		expected := linodego.NodeBalancerConfigCreateOptions{
+			Port:          80,
+			Protocol:      linodego.ProtocolTCP,
+			ProxyProtocol: linodego.ProxyProtocolNone,
+			CheckPassive:  new(bool),
+		}
+		assert.Equal(t, expected, opts.create)
+		assert.Equal(t, linodego.NodeBalancerConfigUpdateOptions(expected), opts.update)
+		assert.Equal(t, linodego.NodeBalancerConfigRebuildOptions{
+			Port:          80,
+			Protocol:      linodego.ProtocolTCP,
+			ProxyProtocol: linodego.ProxyProtocolNone,
+			CheckPassive:  new(bool),
+		}, opts.rebuild)
+	})
+
+	for _, test := range []struct {
+		name    string
+		config  linodego.NodeBalancerConfig
+		create  func(*linodego.NodeBalancerConfigCreateOptions)
+		rebuild func(*linodego.NodeBalancerConfigRebuildOptions)
+	}{
+		{
+			name:    "port",
+			config:  linodego.NodeBalancerConfig{Port: 443},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.Port = 443 },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.Port = 443 },
+		},
+		{
+			name:    "protocol",
+			config:  linodego.NodeBalancerConfig{Protocol: linodego.ProtocolHTTP},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.Protocol = linodego.ProtocolHTTP },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.Protocol = linodego.ProtocolHTTP },
+		},
+		{
+			name:    "proxy protocol",
+			config:  linodego.NodeBalancerConfig{ProxyProtocol: linodego.ProxyProtocolV2},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.ProxyProtocol = linodego.ProxyProtocolV2 },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.ProxyProtocol = linodego.ProxyProtocolV2 },
+		},
+		{
+			name:    "algorithm",
+			config:  linodego.NodeBalancerConfig{Algorithm: linodego.AlgorithmLeastConn},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.Algorithm = linodego.AlgorithmLeastConn },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.Algorithm = linodego.AlgorithmLeastConn },
+		},
+		{
+			name:    "stickiness",
+			config:  linodego.NodeBalancerConfig{Stickiness: linodego.StickinessTable},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.Stickiness = linodego.StickinessTable },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.Stickiness = linodego.StickinessTable },
+		},
+		{
+			name:    "check",
+			config:  linodego.NodeBalancerConfig{Check: linodego.CheckHTTPBody},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.Check = linodego.CheckHTTPBody },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.Check = linodego.CheckHTTPBody },
+		},
+		{
+			name:    "check interval",
+			config:  linodego.NodeBalancerConfig{CheckInterval: 10},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.CheckInterval = 10 },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.CheckInterval = 10 },
+		},
+		{
+			name:    "check attempts",
+			config:  linodego.NodeBalancerConfig{CheckAttempts: 3},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.CheckAttempts = 3 },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.CheckAttempts = 3 },
+		},
+		{
+			name:    "check path",
+			config:  linodego.NodeBalancerConfig{CheckPath: "/healthz"},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.CheckPath = "/healthz" },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.CheckPath = "/healthz" },
+		},
+		{
+			name:    "check body",
+			config:  linodego.NodeBalancerConfig{CheckBody: "ok"},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.CheckBody = "ok" },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.CheckBody = "ok" },
+		},
+		{
+			name:    "check passive",
+			config:  linodego.NodeBalancerConfig{CheckPassive: true},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { *o.CheckPassive = true },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { *o.CheckPassive = true },
+		},
+		{
+			name:    "check timeout",
+			config:  linodego.NodeBalancerConfig{CheckTimeout: 5},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.CheckTimeout = 5 },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.CheckTimeout = 5 },
+		},
+		{
+			name:    "cipher suite",
+			config:  linodego.NodeBalancerConfig{CipherSuite: linodego.CipherLegacy},
+			create:  func(o *linodego.NodeBalancerConfigCreateOptions) { o.CipherSuite = linodego.CipherLegacy },
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) { o.CipherSuite = linodego.CipherLegacy },
+		},
+		{
+			name:   "tls certificate",
+			config: linodego.NodeBalancerConfig{SSLCert: "cert", SSLKey: "key"},
+			create: func(o *linodego.NodeBalancerConfigCreateOptions) {
+				o.SSLCert, o.SSLKey = "cert", "key"
+			},
+			rebuild: func(o *linodego.NodeBalancerConfigRebuildOptions) {
+				o.SSLCert, o.SSLKey = "cert", "key"
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			create := linodego.NodeBalancerConfigCreateOptions{
+				Protocol:      linodego.ProtocolTCP,
+				ProxyProtocol: linodego.ProxyProtocolNone,
+				CheckPassive:  new(bool),
+			}
+			test.create(&create)
+			rebuild := linodego.NodeBalancerConfigRebuildOptions{
+				Protocol:      linodego.ProtocolTCP,
+				ProxyProtocol: linodego.ProxyProtocolNone,
+				CheckPassive:  new(bool),
+			}
+			test.rebuild(&rebuild)
+
+			opts := newNodeBalancerConfigOptions(test.config, nil)
+			assert.Equal(t, create, opts.create)
+			assert.Equal(t, linodego.NodeBalancerConfigUpdateOptions(create), opts.update)
+			assert.Equal(t, rebuild, opts.rebuild)
+		})
+	}
+
+	t.Run("nodes", func(t *testing.T) {
+		opts := newNodeBalancerConfigOptions(linodego.NodeBalancerConfig{Port: 80}, nodes)
+
+		assert.Equal(t, []linodego.NodeBalancerNodeCreateOptions{
+			nodes[0].NodeBalancerNodeCreateOptions,
+			nodes[1].NodeBalancerNodeCreateOptions,
+		}, opts.create.Nodes)
+		assert.Equal(t, nodes, opts.rebuild.Nodes)
+		assert.Empty(t, opts.update.Nodes)
+	})
+}
@@ -0,0 +1,130 @@
+package linode
+
+import (
+	"slices"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+// nodeBalancerRegion is the region chosen for the NodeBalancer of a Service
+// and how its backend nodes are spread across regions.
+type nodeBalancerRegion struct {
+	region string
+	// backends is the number of nodes with a known region
+	backends int
+	// crossRegion is the number of those nodes outside of region
+	crossRegion int
+	// counts is the number of nodes in each region
+	counts map[string]int
+}
+
+// in returns the spread of the backends for a NodeBalancer in region.
+func (r nodeBalancerRegion) in(region string) nodeBalancerRegion {
+	return nodeBalancerRegion{region: region, backends: r.backends, crossRegion: r.backends - r.counts[region], counts: r.counts}
+}
+
+// majority returns the region with most of the backends, r.region unless
+// another region has more of them.
+func (r nodeBalancerRegion) majority() string {
+	regions := make([]string, 0, len(r.counts))
+	for region := range r.counts {
+		regions = append(regions, region)
+	}
+	slices.Sort(regions)
+
+	majority := r.region
+	for _, region := range regions {
+		if r.counts[region] > r.counts[majority] {
+			majority = region
+		}
+	}
+	return majority
+}
+
+// selectNodeBalancerRegion returns the region for the NodeBalancer of service:
+// of the candidate regions, the one with the most nodes. The candidates are the
+// regions annotation of service, in order of preference, or else the regions
+// of nodes, preferring the region of the cluster on ties. Nodes
+// without a region label are not counted, so that the region of the cluster
+// is used when none of them have one.
+func (l *loadbalancers) selectNodeBalancerRegion(service *v1.Service, nodes []*v1.Node) nodeBalancerRegion {
+	counts := make(map[string]int)
+	backends := 0
+	for _, node := range nodes {
+		if region := node.Labels[v1.LabelTopologyRegion]; region != "" {
+			counts[region]++
+			backends++
+		}
+	}
+
+	candidates, ok := getAnnotationStringList(service, annotations.AnnLinodeRegions)
+	if !ok || len(candidates) == 0 {
+		candidates = make([]string, 0, len(counts)+1)
+		if l.zone != "" {
+			candidates = append(candidates, l.zone)
+		}
+		regions := make([]string, 0, len(counts))
+		for region := range counts {
+			if region != l.zone {
+				regions = append(regions, region)
+			}
+		}
+		slices.Sort(regions)
+		candidates = append(candidates, regions...)
+	}
+	if len(candidates) == 0 {
+		return nodeBalancerRegion{region: l.zone, backends: backends, crossRegion: backends - counts[l.zone], counts: counts}
+	}
+
+	selected := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if counts[candidate] > counts[selected] {
+			selected = candidate
+		}
+	}
+	if selected != l.zone {
+		klog.V(3).Infof("placing NodeBalancer for service (%s) in region %s with %d of %d backends", getServiceNn(service), selected, counts[selected], backends)
+	}
+	return nodeBalancerRegion{region: selected, backends: backends, crossRegion: backends - counts[selected], counts: counts}
+}
+
+// warnCrossRegionBackends records a Warning event when some of the backends
+// of the NodeBalancer in region r are in other regions.
+func (l *loadbalancers) warnCrossRegionBackends(service *v1.Service, r nodeBalancerRegion) {
+	if r.crossRegion == 0 {
+		return
+	}
+	l.recordEvent(service, v1.EventTypeWarning, eventReasonCrossRegionBackends,
+		"%d of %d backends are outside of the NodeBalancer region %s", r.crossRegion, r.backends, r.region)
+}
+
+// warnRegionMismatch exposes whether the NodeBalancer in region r is outside
+// of the region of most of its backends, and records a Warning event when it
+// is, e.g. when a preferred region is set or the nodes moved since it was
+// created. Otherwise the backends in other regions are warned about, if any.
+func (l *loadbalancers) warnRegionMismatch(service *v1.Service, r nodeBalancerRegion) {
+	majority := r.majority()
+	if majority == r.region {
+		reportRegionMismatch(service, false)
+		l.warnCrossRegionBackends(service, r)
+		return
+	}
+
+	reportRegionMismatch(service, true)
+	l.recordEvent(service, v1.EventTypeWarning, eventReasonRegionMismatch,
+		"NodeBalancer is in region %s but most of its backends, %d of %d, are in region %s",
+		r.region, r.counts[majority], r.backends, majority)
+}
+
+// reportRegionMismatch exposes whether the NodeBalancer of service is outside
+// of the region of most of its backends.
+func reportRegionMismatch(service *v1.Service, mismatch bool) {
+	value := 0.0
+	if mismatch {
+		value = 1
+	}
+	regionMismatch.WithLabelValues(getServiceNn(service)).Set(value)
+}
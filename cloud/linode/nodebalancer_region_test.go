@@ -0,0 +1,150 @@
+package linode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+func nodeInRegion(name, region string) *v1.Node {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "127.0.0.1"}},
+		},
+	}
+	if region != "" {
+		node.Labels = map[string]string{v1.LabelTopologyRegion: region}
+	}
+	return node
+}
+
+func TestSelectNodeBalancerRegion(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		nodes       []*v1.Node
+		region      string
+		crossRegion int
+	}{
+		{
+			name:   "no region labels use the cluster region",
+			nodes:  []*v1.Node{nodeInRegion("a", ""), nodeInRegion("b", "")},
+			region: "us-west",
+		},
+		{
+			name:   "all nodes in the cluster region",
+			nodes:  []*v1.Node{nodeInRegion("a", "us-west"), nodeInRegion("b", "us-west")},
+			region: "us-west",
+		},
+		{
+			name:        "dominant region",
+			nodes:       []*v1.Node{nodeInRegion("a", "us-west"), nodeInRegion("b", "us-east"), nodeInRegion("c", "us-east")},
+			region:      "us-east",
+			crossRegion: 1,
+		},
+		{
+			name:        "ties prefer the cluster region",
+			nodes:       []*v1.Node{nodeInRegion("a", "us-east"), nodeInRegion("b", "us-west")},
+			region:      "us-west",
+			crossRegion: 1,
+		},
+		{
+			name:        "ties between other regions prefer the first by name",
+			nodes:       []*v1.Node{nodeInRegion("a", "us-southeast"), nodeInRegion("b", "us-east")},
+			region:      "us-east",
+			crossRegion: 1,
+		},
+		{
+			name:        "configured candidates in order of preference",
+			annotations: map[string]string{annotations.AnnLinodeRegions: "us-central, us-east"},
+			nodes:       []*v1.Node{nodeInRegion("a", "us-central"), nodeInRegion("b", "us-east"), nodeInRegion("c", "us-west"), nodeInRegion("d", "us-west")},
+			region:      "us-central",
+			crossRegion: 3,
+		},
+		{
+			name:        "configured candidate with the most nodes",
+			annotations: map[string]string{annotations.AnnLinodeRegions: "us-central,us-east"},
+			nodes:       []*v1.Node{nodeInRegion("a", "us-central"), nodeInRegion("b", "us-east"), nodeInRegion("c", "us-east")},
+			region:      "us-east",
+			crossRegion: 1,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			lb := &loadbalancers{zone: "us-west"}
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+
+			placement := lb.selectNodeBalancerRegion(svc, test.nodes)
+			assert.Equal(t, test.region, placement.region)
+			assert.Equal(t, test.crossRegion, placement.crossRegion)
+		})
+	}
+}
+
+func TestWarnRegionMismatch(t *testing.T) {
+	registerMetrics()
+
+	testcases := []struct {
+		name     string
+		region   string
+		nodes    []*v1.Node
+		mismatch bool
+		event    string
+	}{
+		{
+			name:   "all backends in the NodeBalancer region",
+			region: "us-west",
+			nodes:  []*v1.Node{nodeInRegion("a", "us-west"), nodeInRegion("b", "us-west")},
+		},
+		{
+			name:   "most backends in the NodeBalancer region",
+			region: "us-west",
+			nodes:  []*v1.Node{nodeInRegion("a", "us-west"), nodeInRegion("b", "us-west"), nodeInRegion("c", "us-east")},
+			event:  eventReasonCrossRegionBackends,
+		},
+		{
+			name:   "ties keep the NodeBalancer region",
+			region: "us-west",
+			nodes:  []*v1.Node{nodeInRegion("a", "us-west"), nodeInRegion("b", "us-east")},
+			event:  eventReasonCrossRegionBackends,
+		},
+		{
+			name:     "most backends in another region",
+			region:   "us-west",
+			nodes:    []*v1.Node{nodeInRegion("a", "us-west"), nodeInRegion("b", "us-east"), nodeInRegion("c", "us-east")},
+			mismatch: true,
+			event:    eventReasonRegionMismatch,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			lb := &loadbalancers{zone: "us-west", eventRecorder: recorder}
+			svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: randString(), Namespace: "default"}}
+
+			lb.warnRegionMismatch(svc, lb.selectNodeBalancerRegion(svc, test.nodes).in(test.region))
+
+			value, found := getGaugeValue(t, "ccm_loadbalancer_region_mismatch", map[string]string{"service": getServiceNn(svc)})
+			assert.True(t, found)
+			assert.Equal(t, test.mismatch, value == 1)
+
+			if test.event == "" {
+				assert.Empty(t, recorder.Events)
+				return
+			}
+			if assert.Len(t, recorder.Events, 1) {
+				event := <-recorder.Events
+				assert.True(t, strings.HasPrefix(event, v1.EventTypeWarning+" "+test.event), event)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package linode
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// pendingNodeBalancers remembers the NodeBalancers created for Services which
+// were not assigned an IP yet. Until they are, the Service status does not
+// reference them, so this is how they are found again.
+type pendingNodeBalancers struct {
+	mu  sync.Mutex
+	ids map[types.UID]int
+}
+
+// get returns the ID of the NodeBalancer pending for service.
+func (p *pendingNodeBalancers) get(service *v1.Service) (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id, ok := p.ids[service.UID]
+	return id, ok
+}
+
+// set records that the NodeBalancer with id is pending for service.
+func (p *pendingNodeBalancers) set(service *v1.Service, id int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ids == nil {
+		p.ids = make(map[types.UID]int)
+	}
+	p.ids[service.UID] = id
+}
+
+// forget drops the NodeBalancer pending for service.
+func (p *pendingNodeBalancers) forget(service *v1.Service) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.ids, service.UID)
+}
@@ -0,0 +1,49 @@
+package linode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// newPprofServer returns the server exposing the pprof endpoints under
+// /debug/pprof on Options.PprofAddress, or nil if Options.EnablePprof is not set.
+func newPprofServer() *http.Server {
+	if !Options.EnablePprof {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return &http.Server{
+		Addr:              Options.PprofAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// runPprofServer serves the pprof endpoints, if enabled, until stopCh is closed.
+func runPprofServer(stopCh <-chan struct{}) {
+	server := newPprofServer()
+	if server == nil {
+		return
+	}
+
+	go func() {
+		<-stopCh
+		_ = server.Close()
+	}()
+
+	klog.Infof("serving pprof endpoints on %s", server.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Errorf("pprof server failed: %s", err)
+	}
+}
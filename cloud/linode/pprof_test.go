@@ -0,0 +1,37 @@
+package linode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewPprofServer(t *testing.T) {
+	defer func() { Options.EnablePprof, Options.PprofAddress = false, "" }()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		Options.EnablePprof = false
+		if server := newPprofServer(); server != nil {
+			t.Errorf("expected no pprof server, got one on %s", server.Addr)
+		}
+	})
+
+	t.Run("registers the endpoints when enabled", func(t *testing.T) {
+		Options.EnablePprof, Options.PprofAddress = true, "127.0.0.1:6060"
+		server := newPprofServer()
+		if server == nil {
+			t.Fatal("expected a pprof server")
+		}
+		if server.Addr != Options.PprofAddress {
+			t.Errorf("expected the pprof server on %s, got %s", Options.PprofAddress, server.Addr)
+		}
+
+		for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/pprof/symbol", "/debug/pprof/heap"} {
+			recorder := httptest.NewRecorder()
+			server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			if recorder.Code != http.StatusOK {
+				t.Errorf("expected %s to be served, got status %d", path, recorder.Code)
+			}
+		}
+	})
+}
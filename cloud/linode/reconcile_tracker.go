@@ -0,0 +1,112 @@
+package linode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+// reconcileTracker remembers the Service state last successfully applied to
+// each NodeBalancer, so that reconciles of an unchanged Service (e.g. on an
+// informer resync) can skip updating the NodeBalancer.
+type reconcileTracker struct {
+	mu      sync.Mutex
+	applied map[types.UID]string
+}
+
+// reconcileFingerprint identifies the state applied to the NodeBalancer: the
+// Service generation, its labels and annotations (which carry most of the
+// NodeBalancer configuration but do not bump the generation) and the backend
+// nodes with their resolved addresses and whether their backends are drained.
+func reconcileFingerprint(service *v1.Service, backendIPs map[string]string, drained map[string]bool, nodeBalancerID int) (string, error) {
+	nodeAddresses := make([]string, 0, len(backendIPs))
+	for nodeName, address := range backendIPs {
+		if drained[nodeName] {
+			address += " drain"
+		}
+		nodeAddresses = append(nodeAddresses, nodeName+"="+address)
+	}
+	slices.Sort(nodeAddresses)
+
+	state := struct {
+		NodeBalancerID  int
+		Generation      int64
+		ResourceVersion string          `json:",omitempty"`
+		Spec            *v1.ServiceSpec `json:",omitempty"`
+		Labels          map[string]string
+		Annotations     map[string]string
+		Nodes           []string
+	}{
+		NodeBalancerID: nodeBalancerID,
+		Generation:     service.Generation,
+		Labels:         service.Labels,
+		Annotations:    service.Annotations,
+		Nodes:          nodeAddresses,
+	}
+	// the status annotations are written back by the CCM itself
+	for _, name := range []string{annotations.AnnLinodeNodeBalancerStatusID, annotations.AnnLinodeEffectiveConfig} {
+		if _, ok := service.Annotations[name]; ok {
+			state.Annotations = maps.Clone(state.Annotations)
+			delete(state.Annotations, name)
+		}
+	}
+	if len(state.Annotations) == 0 {
+		state.Annotations = nil
+	}
+	// the generation is not set on Services by older API servers, so fall
+	// back to the spec itself
+	if service.Generation == 0 {
+		state.Spec = &service.Spec
+	}
+	// audit tags record the resource version the NodeBalancer was updated for
+	auditTags, err := getServiceBoolAnnotation(service, annotations.AnnLinodeAuditTags)
+	if err != nil {
+		return "", err
+	}
+	if auditTags {
+		state.ResourceVersion = service.ResourceVersion
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// isCurrent reports whether fingerprint was the last state applied for service.
+func (r *reconcileTracker) isCurrent(service *v1.Service, fingerprint string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	applied, ok := r.applied[service.UID]
+	return ok && applied == fingerprint
+}
+
+// record stores fingerprint as the last state applied for service.
+func (r *reconcileTracker) record(service *v1.Service, fingerprint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.applied == nil {
+		r.applied = make(map[types.UID]string)
+	}
+	r.applied[service.UID] = fingerprint
+}
+
+// forget drops the state applied for service, so that it is fully reconciled next time.
+func (r *reconcileTracker) forget(service *v1.Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.applied, service.UID)
+}
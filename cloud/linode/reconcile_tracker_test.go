@@ -0,0 +1,80 @@
+package linode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+func TestReconcileTracker(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:             "foobar123",
+			Generation:      1,
+			ResourceVersion: "100",
+			Annotations:     map[string]string{},
+		},
+	}
+	nodes := map[string]string{"node-1": "192.168.0.1", "node-2": "192.168.0.2"}
+
+	fingerprint := func(service *v1.Service, nodes map[string]string) string {
+		t.Helper()
+		fp, err := reconcileFingerprint(service, nodes, nil, 123)
+		assert.NoError(t, err)
+		return fp
+	}
+	applied := fingerprint(service, nodes)
+
+	tracker := reconcileTracker{}
+	assert.False(t, tracker.isCurrent(service, applied))
+	tracker.record(service, applied)
+	assert.True(t, tracker.isCurrent(service, applied))
+
+	t.Run("resolved backend addresses matter", func(t *testing.T) {
+		assert.NotEqual(t, applied, fingerprint(service, map[string]string{"node-1": "192.168.0.1", "node-2": "192.168.0.3"}))
+	})
+
+	t.Run("drained backends matter", func(t *testing.T) {
+		drained, err := reconcileFingerprint(service, nodes, map[string]bool{"node-1": true}, 123)
+		assert.NoError(t, err)
+		assert.NotEqual(t, applied, drained)
+	})
+
+	t.Run("resource version only matters with audit tags", func(t *testing.T) {
+		updated := service.DeepCopy()
+		updated.ResourceVersion = "101"
+		assert.Equal(t, applied, fingerprint(updated, nodes))
+
+		updated.Annotations[annotations.AnnLinodeAuditTags] = "true"
+		audited := fingerprint(updated, nodes)
+		updated.ResourceVersion = "102"
+		assert.NotEqual(t, audited, fingerprint(updated, nodes))
+	})
+
+	t.Run("NodeBalancer ID annotation does not matter", func(t *testing.T) {
+		updated := service.DeepCopy()
+		updated.Annotations[annotations.AnnLinodeNodeBalancerStatusID] = "123"
+		assert.Equal(t, applied, fingerprint(updated, nodes))
+
+		updated.Annotations = map[string]string{annotations.AnnLinodeNodeBalancerStatusID: "123"}
+		unannotated := service.DeepCopy()
+		unannotated.Annotations = nil
+		assert.Equal(t, fingerprint(unannotated, nodes), fingerprint(updated, nodes))
+	})
+
+	t.Run("changes are not current", func(t *testing.T) {
+		updated := service.DeepCopy()
+		updated.Generation = 2
+		assert.False(t, tracker.isCurrent(service, fingerprint(updated, nodes)))
+		assert.False(t, tracker.isCurrent(service, fingerprint(service, map[string]string{"node-1": "192.168.0.1"})))
+	})
+
+	t.Run("forgotten service is not current", func(t *testing.T) {
+		tracker.forget(service)
+		assert.False(t, tracker.isCurrent(service, applied))
+	})
+}
@@ -0,0 +1,166 @@
+package linode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// retriesExhaustedError is returned instead of reconciling a Service whose
+// reconciles failed Options.MaxReconcileRetries times in a row.
+type retriesExhaustedError struct {
+	serviceNn string
+	attempts  int
+	lastErr   error
+}
+
+func (e retriesExhaustedError) Error() string {
+	return fmt.Sprintf("giving up on service (%s) after %d failed reconciles until it or its nodes change, last error: %s", e.serviceNn, e.attempts, e.lastErr)
+}
+
+func (e retriesExhaustedError) Unwrap() error {
+	return e.lastErr
+}
+
+type failedReconciles struct {
+	spec        string
+	attempts    int
+	lastErr     error
+	lastFailure time.Time
+}
+
+// retryBudget counts the consecutive failed reconciles of each Service, so
+// that a Service which keeps failing for the same spec and nodes (e.g. because
+// of an invalid annotation) stops being reconciled until either is changed, or
+// until a cooldown has passed. Only permanent errors are counted, see
+// isTransientReconcileError.
+type retryBudget struct {
+	mu       sync.Mutex
+	failures map[types.UID]failedReconciles
+}
+
+// serviceSpecFingerprint identifies the state a reconcile of service depends
+// on: its spec, the labels and annotations carrying the NodeBalancer
+// configuration, and the names of its candidate backend nodes.
+func serviceSpecFingerprint(service *v1.Service, nodes []*v1.Node) (string, error) {
+	nodeNames := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		nodeNames = append(nodeNames, node.Name)
+	}
+	slices.Sort(nodeNames)
+
+	state := struct {
+		Spec        v1.ServiceSpec
+		Labels      map[string]string
+		Annotations map[string]string
+		Nodes       []string
+	}{
+		Spec:        service.Spec,
+		Labels:      service.Labels,
+		Annotations: service.Annotations,
+		Nodes:       nodeNames,
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// isTransientReconcileError reports whether err is expected to go away without
+// the Service being changed, such as Linode API outages, rate limiting and
+// timeouts, or the Service having no nodes available. Such errors do not count
+// against the retry budget.
+func isTransientReconcileError(err error) bool {
+	if errors.Is(err, errNoNodesAvailable) || errors.Is(err, errProvisioningTimeout) || errors.Is(err, errServiceRemoved) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var apiErr *linodego.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 0 || apiErr.Code == http.StatusRequestTimeout || apiErr.Code == http.StatusTooManyRequests ||
+			apiErr.Code >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return k8serrors.IsServerTimeout(err) || k8serrors.IsTimeout(err) || k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsInternalError(err) || k8serrors.IsServiceUnavailable(err)
+}
+
+// exhausted returns a retriesExhaustedError if the reconciles of service
+// failed at least limit times for its current spec and nodes, the last one
+// less than cooldown before now. A cooldown of 0 never expires.
+func (r *retryBudget) exhausted(service *v1.Service, nodes []*v1.Node, limit int, cooldown time.Duration, now time.Time) error {
+	if limit <= 0 {
+		return nil
+	}
+	spec, err := serviceSpecFingerprint(service, nodes)
+	if err != nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed, ok := r.failures[service.UID]
+	if !ok || failed.spec != spec || failed.attempts < limit {
+		return nil
+	}
+	if cooldown > 0 && now.Sub(failed.lastFailure) >= cooldown {
+		return nil
+	}
+	return retriesExhaustedError{serviceNn: getServiceNn(service), attempts: failed.attempts, lastErr: failed.lastErr}
+}
+
+// fail records a failed reconcile of service and returns the number of
+// consecutive failures for its current spec and nodes.
+func (r *retryBudget) fail(service *v1.Service, nodes []*v1.Node, err error, now time.Time) int {
+	spec, fpErr := serviceSpecFingerprint(service, nodes)
+	if fpErr != nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures == nil {
+		r.failures = make(map[types.UID]failedReconciles)
+	}
+	failed := r.failures[service.UID]
+	if failed.spec != spec {
+		failed = failedReconciles{spec: spec}
+	}
+	failed.attempts++
+	failed.lastErr = err
+	failed.lastFailure = now
+	r.failures[service.UID] = failed
+	return failed.attempts
+}
+
+// forget drops the failed reconciles of service.
+func (r *retryBudget) forget(service *v1.Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.failures, service.UID)
+}
@@ -0,0 +1,115 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+func TestRetryBudget(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			UID:         "foobar123",
+			Annotations: map[string]string{annotations.AnnLinodeDefaultProtocol: "invalid"},
+		},
+	}
+	nodes := []*v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}
+	errInvalid := errors.New("invalid protocol")
+	now := time.Now()
+
+	budget := retryBudget{}
+	assert.NoError(t, budget.exhausted(service, nodes, 2, 0, now))
+	assert.Equal(t, 1, budget.fail(service, nodes, errInvalid, now))
+	assert.NoError(t, budget.exhausted(service, nodes, 2, 0, now))
+	assert.Equal(t, 2, budget.fail(service, nodes, errInvalid, now))
+
+	err := budget.exhausted(service, nodes, 2, 0, now)
+	assert.ErrorAs(t, err, &retriesExhaustedError{})
+	assert.ErrorIs(t, err, errInvalid)
+
+	t.Run("no limit", func(t *testing.T) {
+		assert.NoError(t, budget.exhausted(service, nodes, 0, 0, now))
+	})
+
+	t.Run("status changes do not resume", func(t *testing.T) {
+		updated := service.DeepCopy()
+		updated.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+		updated.ResourceVersion = "101"
+		assert.Error(t, budget.exhausted(updated, nodes, 2, 0, now))
+	})
+
+	t.Run("node order does not resume", func(t *testing.T) {
+		reordered := []*v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}, {ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}}
+		budget := retryBudget{}
+		budget.fail(service, reordered, errInvalid, now)
+		budget.fail(service, reordered, errInvalid, now)
+		assert.Error(t, budget.exhausted(service, []*v1.Node{reordered[1], reordered[0]}, 2, 0, now))
+	})
+
+	t.Run("node changes resume", func(t *testing.T) {
+		recovered := append([]*v1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}}, nodes...)
+		assert.NoError(t, budget.exhausted(service, recovered, 2, 0, now))
+	})
+
+	t.Run("cooldown resumes", func(t *testing.T) {
+		assert.Error(t, budget.exhausted(service, nodes, 2, time.Minute, now.Add(30*time.Second)))
+		assert.NoError(t, budget.exhausted(service, nodes, 2, time.Minute, now.Add(time.Minute)))
+	})
+
+	t.Run("spec changes resume", func(t *testing.T) {
+		updated := service.DeepCopy()
+		updated.Annotations[annotations.AnnLinodeDefaultProtocol] = "tcp"
+		assert.NoError(t, budget.exhausted(updated, nodes, 2, 0, now))
+
+		// failures are counted again from the changed spec
+		assert.Equal(t, 1, budget.fail(updated, nodes, errInvalid, now))
+		assert.NoError(t, budget.exhausted(updated, nodes, 2, 0, now))
+	})
+
+	t.Run("forgotten service resumes", func(t *testing.T) {
+		budget.fail(service, nodes, errInvalid, now)
+		budget.fail(service, nodes, errInvalid, now)
+		budget.forget(service)
+		assert.NoError(t, budget.exhausted(service, nodes, 2, 0, now))
+	})
+}
+
+func Test_isTransientReconcileError(t *testing.T) {
+	testcases := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{name: "validation error", err: errors.New("invalid protocol"), transient: false},
+		{name: "privileged port", err: fmt.Errorf("%w: 80", errPrivilegedPort), transient: false},
+		{name: "no nodes", err: fmt.Errorf("%w: service default/test", errNoNodesAvailable), transient: true},
+		{name: "provisioning timeout", err: fmt.Errorf("%w after 1m", errProvisioningTimeout), transient: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, transient: true},
+		{name: "API bad request", err: &linodego.Error{Code: http.StatusBadRequest}, transient: false},
+		{name: "API not found", err: &linodego.Error{Code: http.StatusNotFound}, transient: false},
+		{name: "API rate limited", err: &linodego.Error{Code: http.StatusTooManyRequests}, transient: true},
+		{name: "API server error", err: fmt.Errorf("updating: %w", &linodego.Error{Code: http.StatusServiceUnavailable}), transient: true},
+		{name: "API connection error", err: &linodego.Error{Code: 0}, transient: true},
+		{name: "Kubernetes API timeout", err: k8serrors.NewServerTimeout(schema.GroupResource{Resource: "services"}, "get", 1), transient: true},
+		{name: "Kubernetes API forbidden", err: k8serrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "tls", errors.New("denied")), transient: false},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.transient, isTransientReconcileError(test.err))
+		})
+	}
+}
@@ -55,7 +55,10 @@ type routes struct {
 	routeCache *routeCache
 }
 
-func newRoutes(client client.Client) (cloudprovider.Routes, error) {
+// newRoutes returns the routes of the VPC, looking up the linodes of nodes with
+// instances, which is shared with the cloud provider so that its caches and
+// lookup limit apply to both.
+func newRoutes(client client.Client, instances *instances) (cloudprovider.Routes, error) {
 	timeout := 60
 	if raw, ok := os.LookupEnv("LINODE_ROUTES_CACHE_TTL_SECONDS"); ok {
 		if t, _ := strconv.Atoi(raw); t > 0 {
@@ -72,7 +75,7 @@ func newRoutes(client client.Client) (cloudprovider.Routes, error) {
 	return &routes{
 		vpcid:     vpcid,
 		client:    client,
-		instances: newInstances(client),
+		instances: instances,
 		routeCache: &routeCache{
 			routes: make(map[int][]linodego.VPCIP, 0),
 			ttl:    time.Duration(timeout) * time.Second,
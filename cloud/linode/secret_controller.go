@@ -0,0 +1,200 @@
+package linode
+
+import (
+	"context"
+	"time"
+
+	"github.com/appscode/go/wait"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	v1informers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+)
+
+// tlsSecretIndex indexes LoadBalancer Services by the namespaced names of the
+// TLS secrets referenced by their port configs.
+const tlsSecretIndex = "tlsSecret"
+
+// newSecretMetadataInformer returns an informer caching only the metadata of
+// secrets, which is all the secret controller needs to notice changes; the
+// secrets themselves are fetched when the NodeBalancer is reconciled.
+func newSecretMetadataInformer(client metadata.Interface) informers.GenericInformer {
+	return metadatainformer.NewFilteredMetadataInformer(client, v1.SchemeGroupVersion.WithResource("secrets"), metav1.NamespaceAll, 0, cache.Indexers{}, nil)
+}
+
+// secretController watches TLS secrets and reconciles the NodeBalancers of the
+// Services referencing them, so that rotated certificates are applied without
+// waiting for a resync.
+type secretController struct {
+	loadbalancers   *loadbalancers
+	secretInformer  informers.GenericInformer
+	serviceInformer v1informers.ServiceInformer
+	nodeInformer    v1informers.NodeInformer
+
+	queue workqueue.DelayingInterface
+}
+
+func newSecretController(loadbalancers *loadbalancers, secretInformer informers.GenericInformer, serviceInformer v1informers.ServiceInformer, nodeInformer v1informers.NodeInformer) *secretController {
+	if err := serviceInformer.Informer().AddIndexers(cache.Indexers{tlsSecretIndex: tlsSecretIndexFunc}); err != nil {
+		klog.Errorf("SecretController didn't successfully register the TLS secret index %s", err)
+	}
+
+	return &secretController{
+		loadbalancers:   loadbalancers,
+		secretInformer:  secretInformer,
+		serviceInformer: serviceInformer,
+		nodeInformer:    nodeInformer,
+		queue:           workqueue.NewDelayingQueue(),
+	}
+}
+
+// tlsSecretIndexFunc returns the namespaced names of the TLS secrets referenced
+// by the port configs of a LoadBalancer Service.
+func tlsSecretIndexFunc(obj interface{}) ([]string, error) {
+	service, ok := obj.(*v1.Service)
+	if !ok || service.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return nil, nil
+	}
+
+	var keys []string
+	for _, port := range service.Spec.Ports {
+		portConfig, err := getPortConfigAnnotation(service, int(port.Port))
+		if err != nil {
+			continue
+		}
+		if portConfig.TLSSecretName != "" {
+			keys = append(keys, getTLSSecretRef(service.Namespace, portConfig.TLSSecretName).String())
+		}
+		for _, secret := range portConfig.TLSSecrets {
+			keys = append(keys, getTLSSecretRef(service.Namespace, secret).String())
+		}
+	}
+	return keys, nil
+}
+
+func (s *secretController) Run(stopCh <-chan struct{}) {
+	if _, err := s.secretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: s.handleSecretUpdate,
+		DeleteFunc: s.handleSecretDelete,
+	}); err != nil {
+		klog.Errorf("SecretController didn't successfully register it's Informer %s", err)
+	}
+
+	go s.secretInformer.Informer().Run(stopCh)
+
+	// the services referencing a secret are looked up in the service informer
+	// and reconciled with the nodes of the node informer, which are started by
+	// the service and node controllers
+	if !cache.WaitForCacheSync(stopCh, s.secretInformer.Informer().HasSynced, s.serviceInformer.Informer().HasSynced, s.nodeInformer.Informer().HasSynced) {
+		klog.Error("SecretController failed to sync its informer caches")
+		return
+	}
+
+	wait.Until(s.worker, time.Second, stopCh)
+}
+
+// handleSecretUpdate enqueues the Services referencing a changed secret. Only
+// the metadata of secrets is cached, so any change to a secret is handled.
+func (s *secretController) handleSecretUpdate(oldObj, newObj interface{}) {
+	oldSecret, ok := oldObj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return
+	}
+	newSecret, ok := newObj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return
+	}
+
+	if oldSecret.ResourceVersion == newSecret.ResourceVersion {
+		return
+	}
+
+	s.enqueueReferencingServices(newSecret)
+}
+
+// handleSecretDelete enqueues the Services referencing a deleted secret, so that
+// the TLS secret missing policy is applied to them.
+func (s *secretController) handleSecretDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*metav1.PartialObjectMetadata)
+	if !ok {
+		return
+	}
+
+	s.enqueueReferencingServices(secret)
+}
+
+func (s *secretController) enqueueReferencingServices(secret *metav1.PartialObjectMetadata) {
+	secretNn := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}.String()
+	services, err := s.serviceInformer.Informer().GetIndexer().ByIndex(tlsSecretIndex, secretNn)
+	if err != nil {
+		klog.Errorf("failed to look up services referencing secret (%s): %s", secretNn, err)
+		return
+	}
+
+	for _, obj := range services {
+		service, ok := obj.(*v1.Service)
+		if !ok || !isNamespaceManaged(service.Namespace) {
+			continue
+		}
+		klog.Infof("SecretController will reconcile service (%s) for changed secret (%s)", getServiceNn(service), secretNn)
+		s.queue.Add(types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
+	}
+}
+
+// worker runs a worker thread that dequeues services referencing updated
+// secrets and reconciles their NodeBalancers.
+func (s *secretController) worker() {
+	for s.processNext() {
+	}
+}
+
+func (s *secretController) processNext() bool {
+	key, quit := s.queue.Get()
+	if quit {
+		return false
+	}
+	defer s.queue.Done(key)
+
+	serviceNn, ok := key.(types.NamespacedName)
+	if !ok {
+		klog.Errorf("expected dequeued key to be of type types.NamespacedName but got %T", key)
+		return true
+	}
+
+	err := s.handleService(context.TODO(), serviceNn)
+	switch {
+	case err == nil:
+	case isTransientReconcileError(err):
+		klog.Errorf("failed to reconcile service (%s) for changed secret; retrying in 1 minute: %s", serviceNn, err)
+		s.queue.AddAfter(serviceNn, retryInterval)
+	default:
+		klog.Errorf("failed to reconcile service (%s) for changed secret; will not retry: %s", serviceNn, err)
+	}
+	return true
+}
+
+// handleService reconciles the NodeBalancer of a Service referencing a changed
+// secret. The secret is not part of the state recorded for the Service, so the
+// recorded state is dropped to force a full reconcile.
+func (s *secretController) handleService(ctx context.Context, serviceNn types.NamespacedName) error {
+	service, err := s.serviceInformer.Lister().Services(serviceNn.Namespace).Get(serviceNn.Name)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.loadbalancers.reconciled.forget(service)
+	return s.loadbalancers.reconcileService(ctx, service.DeepCopy())
+}
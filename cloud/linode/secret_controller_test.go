@@ -0,0 +1,116 @@
+package linode
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+func newTLSService(namespace, name string, secrets map[int32]string) *v1.Service {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{},
+		},
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+		},
+	}
+	for port, secret := range secrets {
+		service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{Port: port})
+		if secret != "" {
+			service.Annotations[annotations.AnnLinodePortConfigPrefix+strconv.Itoa(int(port))] = `{ "protocol": "https", "tls-secret-name": "` + secret + `" }`
+		}
+	}
+	return service
+}
+
+func TestSecretControllerEnqueuesReferencingServices(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	factory := informers.NewSharedInformerFactory(kubeClient, 0)
+	lbs := newLoadbalancers(nil, "us-east").(*loadbalancers)
+	lbs.kubeClient = kubeClient
+	secretInformer := newSecretMetadataInformer(metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()))
+	controller := newSecretController(lbs, secretInformer, factory.Core().V1().Services(), factory.Core().V1().Nodes())
+
+	services := []*v1.Service{
+		newTLSService("default", "web", map[int32]string{443: "web-tls", 80: ""}),
+		newTLSService("default", "api", map[int32]string{443: "web-tls", 8443: "api-tls"}),
+		newTLSService("default", "other", map[int32]string{443: "other-tls"}),
+		newTLSService("staging", "web", map[int32]string{443: "web-tls"}),
+	}
+	indexer := controller.serviceInformer.Informer().GetIndexer()
+	for _, service := range services {
+		assert.NoError(t, indexer.Add(service))
+	}
+
+	oldSecret := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default", ResourceVersion: "1"},
+	}
+
+	t.Run("resync of an unchanged secret does not enqueue", func(t *testing.T) {
+		controller.handleSecretUpdate(oldSecret, oldSecret.DeepCopy())
+		assert.Equal(t, 0, controller.queue.Len())
+	})
+
+	t.Run("index maps secrets to services", func(t *testing.T) {
+		keys, err := tlsSecretIndexFunc(services[1])
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"default/web-tls", "default/api-tls"}, keys)
+
+		keys, err = tlsSecretIndexFunc(newTLSService("staging", "shared", map[int32]string{443: "default/web-tls"}))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"default/web-tls"}, keys)
+
+		sni := newTLSService("default", "sni", map[int32]string{443: ""})
+		sni.Annotations[annotations.AnnLinodePortConfigPrefix+"443"] = `{ "protocol": "https", "tls-secrets": { "a.linode.test": "sni-tls" } }`
+		keys, err = tlsSecretIndexFunc(sni)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"default/sni-tls"}, keys)
+	})
+
+	t.Run("updated secret enqueues referencing services", func(t *testing.T) {
+		newSecret := oldSecret.DeepCopy()
+		newSecret.ResourceVersion = "2"
+		controller.handleSecretUpdate(oldSecret, newSecret)
+
+		var enqueued []types.NamespacedName
+		for controller.queue.Len() > 0 {
+			key, _ := controller.queue.Get()
+			enqueued = append(enqueued, key.(types.NamespacedName))
+			controller.queue.Done(key)
+		}
+		assert.ElementsMatch(t, []types.NamespacedName{
+			{Namespace: "default", Name: "web"},
+			{Namespace: "default", Name: "api"},
+		}, enqueued)
+	})
+
+	t.Run("deleted secret enqueues referencing services", func(t *testing.T) {
+		controller.handleSecretDelete(&metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "api-tls", Namespace: "default"}})
+
+		key, _ := controller.queue.Get()
+		controller.queue.Done(key)
+		assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "api"}, key)
+		assert.Equal(t, 0, controller.queue.Len())
+	})
+
+	t.Run("services are reconciled without being modified", func(t *testing.T) {
+		kubeClient.ClearActions()
+		// a service without a NodeBalancer yet is left to the service controller
+		assert.NoError(t, controller.handleService(context.TODO(), types.NamespacedName{Namespace: "default", Name: "web"}))
+		assert.NoError(t, controller.handleService(context.TODO(), types.NamespacedName{Namespace: "default", Name: "deleted"}))
+		assert.Empty(t, kubeClient.Actions())
+	})
+}
@@ -0,0 +1,143 @@
+package linode
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// invalidAnnotationError is returned for a Service annotation whose value
+// cannot be parsed as the type the annotation expects.
+type invalidAnnotationError struct {
+	name   string
+	value  string
+	reason string
+}
+
+func (e invalidAnnotationError) Error() string {
+	return fmt.Sprintf("invalid value %q for annotation %s: %s", e.value, e.name, e.reason)
+}
+
+// getAnnotationString returns the value of the annotation name of service,
+// with surrounding whitespace trimmed, and whether it is set.
+func getAnnotationString(service *v1.Service, name string) (string, bool) {
+	value, ok := service.GetAnnotations()[name]
+	return strings.TrimSpace(value), ok
+}
+
+// getAnnotationBool returns the boolean value of the annotation name of
+// service, and whether it is set. See parseAnnotationBool.
+func getAnnotationBool(service *v1.Service, name string) (bool, bool, error) {
+	value, ok := getAnnotationString(service, name)
+	if !ok {
+		return false, false, nil
+	}
+	parsed, err := parseAnnotationBool(name, value)
+	return parsed, true, err
+}
+
+// parseAnnotationBool parses the value of the annotation name as a boolean.
+// Besides the forms accepted by strconv.ParseBool, yes/no and on/off are
+// accepted, in any case.
+func parseAnnotationBool(name, value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "t", "true", "y", "yes", "on":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off":
+		return false, nil
+	default:
+		return false, invalidAnnotationError{name: name, value: value, reason: "must be a boolean (true or false)"}
+	}
+}
+
+// getAnnotationInt returns the integer value of the annotation name of
+// service, and whether it is set.
+func getAnnotationInt(service *v1.Service, name string) (int, bool, error) {
+	value, ok := getAnnotationString(service, name)
+	if !ok {
+		return 0, false, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, true, invalidAnnotationError{name: name, value: value, reason: "must be an integer"}
+	}
+	return parsed, true, nil
+}
+
+// getAnnotationFloat returns the floating point value of the annotation name
+// of service, and whether it is set.
+func getAnnotationFloat(service *v1.Service, name string) (float64, bool, error) {
+	value, ok := getAnnotationString(service, name)
+	if !ok {
+		return 0, false, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, true, invalidAnnotationError{name: name, value: value, reason: "must be a number"}
+	}
+	return parsed, true, nil
+}
+
+// getAnnotationEnum returns the value of the annotation name of service,
+// lowercased, and whether it is set. The value must be one of options.
+func getAnnotationEnum(service *v1.Service, name string, options ...string) (string, bool, error) {
+	value, ok := getAnnotationString(service, name)
+	if !ok {
+		return "", false, nil
+	}
+	parsed, err := parseAnnotationEnum(name, value, options...)
+	return parsed, true, err
+}
+
+// parseAnnotationEnum lowercases the value of the annotation name, which must
+// be one of options.
+func parseAnnotationEnum(name, value string, options ...string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	for _, option := range options {
+		if normalized == option {
+			return normalized, nil
+		}
+	}
+	return "", invalidAnnotationError{name: name, value: value, reason: "options are " + strings.Join(options, ", ")}
+}
+
+// getAnnotationStringList returns the comma separated values of the annotation
+// name of service, with whitespace trimmed and empty values dropped, and
+// whether it is set.
+func getAnnotationStringList(service *v1.Service, name string) ([]string, bool) {
+	value, ok := getAnnotationString(service, name)
+	if !ok {
+		return nil, false
+	}
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values, true
+}
+
+// getAnnotationCIDRs returns the comma separated CIDRs of the annotation name
+// of service, and whether it is set. At least one CIDR must be given.
+func getAnnotationCIDRs(service *v1.Service, name string) ([]*net.IPNet, bool, error) {
+	values, ok := getAnnotationStringList(service, name)
+	if !ok {
+		return nil, false, nil
+	}
+	if len(values) == 0 {
+		return nil, true, invalidAnnotationError{name: name, reason: "must be a comma separated list of CIDRs"}
+	}
+	cidrs := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		_, cidr, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, true, invalidAnnotationError{name: name, value: value, reason: "must be a comma separated list of CIDRs"}
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, true, nil
+}
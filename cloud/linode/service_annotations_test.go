@@ -0,0 +1,217 @@
+package linode
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const testAnnotation = "service.beta.kubernetes.io/linode-loadbalancer-test"
+
+func serviceWithAnnotation(value *string) *v1.Service {
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	if value != nil {
+		service.Annotations = map[string]string{testAnnotation: *value}
+	}
+	return service
+}
+
+func TestGetAnnotationBool(t *testing.T) {
+	testcases := []struct {
+		name      string
+		value     *string
+		expected  bool
+		set       bool
+		expectErr bool
+	}{
+		{"unset", nil, false, false, false},
+		{"true", ptr.To("true"), true, true, false},
+		{"yes", ptr.To(" Yes "), true, true, false},
+		{"on", ptr.To("ON"), true, true, false},
+		{"false", ptr.To("False"), false, true, false},
+		{"off", ptr.To("off"), false, true, false},
+		{"zero", ptr.To("0"), false, true, false},
+		{"empty", ptr.To(""), false, true, true},
+		{"invalid", ptr.To("maybe"), false, true, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			value, set, err := getAnnotationBool(serviceWithAnnotation(test.value), testAnnotation)
+			if test.expectErr {
+				assert.ErrorAs(t, err, &invalidAnnotationError{})
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.set, set)
+		})
+	}
+}
+
+func TestGetAnnotationInt(t *testing.T) {
+	testcases := []struct {
+		name      string
+		value     *string
+		expected  int
+		set       bool
+		expectErr bool
+	}{
+		{"unset", nil, 0, false, false},
+		{"positive", ptr.To("42"), 42, true, false},
+		{"negative", ptr.To("-3"), -3, true, false},
+		{"whitespace", ptr.To(" 7 "), 7, true, false},
+		{"empty", ptr.To(""), 0, true, true},
+		{"float", ptr.To("1.5"), 0, true, true},
+		{"word", ptr.To("five"), 0, true, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			value, set, err := getAnnotationInt(serviceWithAnnotation(test.value), testAnnotation)
+			if test.expectErr {
+				assert.EqualError(t, err, `invalid value "`+*test.value+`" for annotation `+testAnnotation+`: must be an integer`)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.set, set)
+		})
+	}
+}
+
+func TestGetAnnotationFloat(t *testing.T) {
+	testcases := []struct {
+		name      string
+		value     *string
+		expected  float64
+		set       bool
+		expectErr bool
+	}{
+		{"unset", nil, 0, false, false},
+		{"integer", ptr.To("42"), 42, true, false},
+		{"fraction", ptr.To("0.25"), 0.25, true, false},
+		{"whitespace", ptr.To(" 1.5 "), 1.5, true, false},
+		{"empty", ptr.To(""), 0, true, true},
+		{"word", ptr.To("fast"), 0, true, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			value, set, err := getAnnotationFloat(serviceWithAnnotation(test.value), testAnnotation)
+			if test.expectErr {
+				assert.EqualError(t, err, `invalid value "`+*test.value+`" for annotation `+testAnnotation+`: must be a number`)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.set, set)
+		})
+	}
+}
+
+func TestGetAnnotationEnum(t *testing.T) {
+	options := []string{"tcp", "http", "https"}
+	testcases := []struct {
+		name      string
+		value     *string
+		expected  string
+		set       bool
+		expectErr bool
+	}{
+		{"unset", nil, "", false, false},
+		{"valid", ptr.To("http"), "http", true, false},
+		{"mixed case", ptr.To(" HTTPS "), "https", true, false},
+		{"empty", ptr.To(""), "", true, true},
+		{"invalid", ptr.To("udp"), "", true, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			value, set, err := getAnnotationEnum(serviceWithAnnotation(test.value), testAnnotation, options...)
+			if test.expectErr {
+				assert.EqualError(t, err, `invalid value "`+*test.value+`" for annotation `+testAnnotation+`: options are tcp, http, https`)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.set, set)
+		})
+	}
+}
+
+func TestGetAnnotationStringList(t *testing.T) {
+	testcases := []struct {
+		name     string
+		value    *string
+		expected []string
+		set      bool
+	}{
+		{"unset", nil, nil, false},
+		{"empty", ptr.To(""), nil, true},
+		{"single", ptr.To("foo"), []string{"foo"}, true},
+		{"multiple", ptr.To("foo, bar ,baz"), []string{"foo", "bar", "baz"}, true},
+		{"empty items", ptr.To(",foo,,bar,"), []string{"foo", "bar"}, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			value, set := getAnnotationStringList(serviceWithAnnotation(test.value), testAnnotation)
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.set, set)
+		})
+	}
+}
+
+func TestGetAnnotationCIDRs(t *testing.T) {
+	mustParseCIDR := func(cidr string) *net.IPNet {
+		_, parsed, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	testcases := []struct {
+		name      string
+		value     *string
+		expected  []*net.IPNet
+		set       bool
+		expectErr bool
+	}{
+		{"unset", nil, nil, false, false},
+		{"single", ptr.To("10.0.0.0/24"), []*net.IPNet{mustParseCIDR("10.0.0.0/24")}, true, false},
+		{"multiple", ptr.To("10.0.0.0/24, 2600:3c00::/64"), []*net.IPNet{mustParseCIDR("10.0.0.0/24"), mustParseCIDR("2600:3c00::/64")}, true, false},
+		{"host bits", ptr.To("10.0.0.5/24"), []*net.IPNet{mustParseCIDR("10.0.0.0/24")}, true, false},
+		{"empty", ptr.To(" , "), nil, true, true},
+		{"missing prefix length", ptr.To("10.0.0.0"), nil, true, true},
+		{"one invalid", ptr.To("10.0.0.0/24,bogus"), nil, true, true},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			value, set, err := getAnnotationCIDRs(serviceWithAnnotation(test.value), testAnnotation)
+			if test.expectErr {
+				assert.ErrorAs(t, err, &invalidAnnotationError{})
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.set, set)
+		})
+	}
+}
+
+func TestGetAnnotationString(t *testing.T) {
+	value, set := getAnnotationString(serviceWithAnnotation(nil), testAnnotation)
+	assert.Equal(t, "", value)
+	assert.False(t, set)
+
+	value, set = getAnnotationString(serviceWithAnnotation(ptr.To(" /healthz ")), testAnnotation)
+	assert.Equal(t, "/healthz", value)
+	assert.True(t, set)
+}
@@ -40,7 +40,7 @@ func (s *serviceController) Run(stopCh <-chan struct{}) {
 				return
 			}
 
-			if service.Spec.Type != "LoadBalancer" {
+			if service.Spec.Type != "LoadBalancer" || !isNamespaceManaged(service.Namespace) {
 				return
 			}
 
@@ -57,6 +57,10 @@ func (s *serviceController) Run(stopCh <-chan struct{}) {
 				return
 			}
 
+			if !isNamespaceManaged(oldSvc.Namespace) {
+				return
+			}
+
 			if newSvc.Spec.Type != "LoadBalancer" && oldSvc.Spec.Type == "LoadBalancer" {
 				klog.Infof("ServiceController will handle service (%s) LoadBalancer deletion", getServiceNn(oldSvc))
 				s.queue.Add(oldSvc)
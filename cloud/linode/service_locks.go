@@ -0,0 +1,54 @@
+package linode
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// serviceLocks serializes the reconciles of each Service. The service
+// controller of the cloud provider and the controllers of this package, such
+// as the secret controller, reconcile Services from their own workers, and
+// concurrent reconciles of a Service could create or rebuild the same
+// NodeBalancer config twice.
+type serviceLocks struct {
+	mu    sync.Mutex
+	locks map[types.NamespacedName]*serviceLock
+}
+
+// serviceLock is the lock of a Service, dropped once no reconcile holds or
+// waits for it.
+type serviceLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock blocks until no other reconcile of service is running, and returns the
+// func ending the reconcile.
+func (s *serviceLocks) lock(service *v1.Service) func() {
+	key := types.NamespacedName{Namespace: service.Namespace, Name: service.Name}
+
+	s.mu.Lock()
+	if s.locks == nil {
+		s.locks = make(map[types.NamespacedName]*serviceLock)
+	}
+	lock, ok := s.locks[key]
+	if !ok {
+		lock = &serviceLock{}
+		s.locks[key] = lock
+	}
+	lock.refs++
+	s.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(s.locks, key)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package linode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceLocks(t *testing.T) {
+	var locks serviceLocks
+	web := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	api := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}}
+
+	unlock := locks.lock(web)
+
+	// other Services are not blocked
+	locks.lock(api)()
+
+	locked := make(chan struct{})
+	go func() {
+		locks.lock(web.DeepCopy())()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("Service was locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Service was not unlocked")
+	}
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	assert.Empty(t, locks.locks, "expected the unused locks to be dropped")
+}
@@ -0,0 +1,126 @@
+package linode
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego"
+	"golang.org/x/time/rate"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+// serviceRateLimitKey is the context key of the rate limiter of the Linode API
+// requests made for a Service.
+type serviceRateLimitKey struct{}
+
+// serviceRateLimiters holds a rate limiter of the Linode API requests made for
+// each Service, so that a Service reconciled often cannot use up the API rate
+// limit of the others.
+type serviceRateLimiters struct {
+	mu       sync.Mutex
+	limiters map[types.UID]*rate.Limiter
+}
+
+// getServiceRateLimit returns the API request rate and burst allowed for
+// service: its annotations, or else Options.ServiceAPIQPS and
+// Options.ServiceAPIBurst. A rate of 0 is unlimited.
+func getServiceRateLimit(service *v1.Service) (rate.Limit, int, error) {
+	qps, ok, err := getAnnotationFloat(service, annotations.AnnLinodeAPIQPS)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		qps = Options.ServiceAPIQPS
+	} else if qps < 0 {
+		return 0, 0, invalidAnnotationError{name: annotations.AnnLinodeAPIQPS, value: strconv.FormatFloat(qps, 'f', -1, 64), reason: "must not be negative"}
+	}
+
+	burst, ok, err := getAnnotationInt(service, annotations.AnnLinodeAPIBurst)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		burst = Options.ServiceAPIBurst
+	} else if burst < 1 {
+		return 0, 0, invalidAnnotationError{name: annotations.AnnLinodeAPIBurst, value: strconv.Itoa(burst), reason: "must be at least 1"}
+	}
+	return rate.Limit(qps), max(burst, 1), nil
+}
+
+// get returns the rate limiter of service, updated to its current rate limit,
+// or nil if its requests are not limited.
+func (s *serviceRateLimiters) get(service *v1.Service) (*rate.Limiter, error) {
+	limit, burst, err := getServiceRateLimit(service)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit == 0 {
+		delete(s.limiters, service.UID)
+		return nil, nil
+	}
+	if s.limiters == nil {
+		s.limiters = make(map[types.UID]*rate.Limiter)
+	}
+	limiter, ok := s.limiters[service.UID]
+	if !ok {
+		limiter = rate.NewLimiter(limit, burst)
+		s.limiters[service.UID] = limiter
+	}
+	if limiter.Limit() != limit {
+		limiter.SetLimit(limit)
+	}
+	if limiter.Burst() != burst {
+		limiter.SetBurst(burst)
+	}
+	return limiter, nil
+}
+
+// forget drops the rate limiter of service.
+func (s *serviceRateLimiters) forget(service *v1.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.limiters, service.UID)
+}
+
+// withServiceRateLimit returns ctx carrying the rate limiter of service, which
+// throttles the Linode API requests made with it. Invalid rate limit
+// annotations are returned as an error.
+func (s *serviceRateLimiters) withServiceRateLimit(ctx context.Context, service *v1.Service) (context.Context, error) {
+	limiter, err := s.get(service)
+	if err != nil || limiter == nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, serviceRateLimitKey{}, limiter), nil
+}
+
+// waitServiceRateLimit blocks request until the rate limiter of the Service it
+// is made for, if any, allows it, or its context is done. It is registered to
+// run before every Linode API request. It never returns an error, which the
+// linodego retry conditions do not handle; a request whose context is done
+// fails with the context error instead.
+func waitServiceRateLimit(request *linodego.Request) error {
+	ctx := request.Context()
+	limiter, ok := ctx.Value(serviceRateLimitKey{}).(*rate.Limiter)
+	if !ok {
+		return nil
+	}
+
+	reservation := limiter.Reserve()
+	timer := time.NewTimer(reservation.Delay())
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		reservation.Cancel()
+	}
+	return nil
+}
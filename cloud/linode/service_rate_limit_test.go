@@ -0,0 +1,136 @@
+package linode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/linode/linode-cloud-controller-manager/cloud/annotations"
+)
+
+func TestServiceRateLimiters(t *testing.T) {
+	throttled := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "throttled",
+			UID:  "throttled",
+			Annotations: map[string]string{
+				annotations.AnnLinodeAPIQPS:   "0.001",
+				annotations.AnnLinodeAPIBurst: "2",
+			},
+		},
+	}
+	other := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other"}}
+
+	defer func() {
+		Options.ServiceAPIQPS = 0
+		Options.ServiceAPIBurst = 0
+	}()
+
+	var limiters serviceRateLimiters
+	get := func(service *v1.Service) *rate.Limiter {
+		limiter, err := limiters.get(service)
+		assert.NoError(t, err)
+		return limiter
+	}
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		assert.Nil(t, get(other))
+	})
+
+	t.Run("limited by the annotations", func(t *testing.T) {
+		limiter := get(throttled)
+		assert.NotNil(t, limiter)
+		assert.Equal(t, rate.Limit(0.001), limiter.Limit())
+		assert.True(t, limiter.Allow())
+		assert.True(t, limiter.Allow())
+		assert.False(t, limiter.Allow(), "expected the requests above the burst to be throttled")
+		assert.Same(t, limiter, get(throttled), "expected the limiter to be kept across reconciles")
+	})
+
+	t.Run("others limited by the defaults", func(t *testing.T) {
+		Options.ServiceAPIQPS = 100
+		Options.ServiceAPIBurst = 10
+		limiter := get(other)
+		assert.NotNil(t, limiter)
+		assert.Equal(t, rate.Limit(100), limiter.Limit())
+		for i := 0; i < 10; i++ {
+			assert.True(t, limiter.Allow())
+		}
+		assert.False(t, get(throttled).Allow(), "expected the throttled service to stay throttled")
+	})
+
+	t.Run("invalid annotations", func(t *testing.T) {
+		for name, value := range map[string]string{
+			annotations.AnnLinodeAPIQPS:   "fast",
+			annotations.AnnLinodeAPIBurst: "0",
+		} {
+			invalid := throttled.DeepCopy()
+			invalid.Annotations[name] = value
+			_, err := limiters.get(invalid)
+			assert.ErrorAs(t, err, &invalidAnnotationError{}, "expected %s=%q to be rejected", name, value)
+		}
+		invalid := throttled.DeepCopy()
+		invalid.Annotations[annotations.AnnLinodeAPIQPS] = "-1"
+		_, err := limiters.withServiceRateLimit(context.TODO(), invalid)
+		assert.ErrorAs(t, err, &invalidAnnotationError{})
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		limiters.forget(throttled)
+		assert.True(t, get(throttled).Allow(), "expected a new limiter once forgotten")
+	})
+}
+
+func TestWaitServiceRateLimit(t *testing.T) {
+	fake := newFake(t)
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	client := linodego.NewClient(http.DefaultClient)
+	client.SetBaseURL(ts.URL)
+	client.OnBeforeRequest(waitServiceRateLimit)
+
+	throttled := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "throttled",
+			UID:  "throttled",
+			Annotations: map[string]string{
+				annotations.AnnLinodeAPIQPS:   "0.001",
+				annotations.AnnLinodeAPIBurst: "1",
+			},
+		},
+	}
+	other := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other"}}
+
+	var limiters serviceRateLimiters
+	throttledCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	throttledCtx, err := limiters.withServiceRateLimit(throttledCtx, throttled)
+	assert.NoError(t, err)
+
+	_, err = client.ListNodeBalancers(throttledCtx, nil)
+	assert.NoError(t, err)
+	start := time.Now()
+	_, err = client.ListNodeBalancers(throttledCtx, nil)
+	assert.Error(t, err, "expected the request above the rate limit of the service to be throttled")
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond, "expected the request to wait for the rate limiter")
+
+	otherCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	otherCtx, err = limiters.withServiceRateLimit(otherCtx, other)
+	assert.NoError(t, err)
+	start = time.Now()
+	for i := 0; i < 5; i++ {
+		_, err = client.ListNodeBalancers(otherCtx, nil)
+		assert.NoError(t, err, "expected the requests of other services not to be throttled")
+	}
+	assert.Less(t, time.Since(start), time.Second)
+}
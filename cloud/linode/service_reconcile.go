@@ -0,0 +1,58 @@
+package linode
+
+import (
+	"context"
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// toBeDeletedTaint is the taint the Cluster Autoscaler puts on a node before
+// deleting it.
+const toBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
+
+var errNodeListerNotSet = errors.New("node lister is not set")
+
+// reconcileService updates the NodeBalancer of a provisioned LoadBalancer
+// Service. It is used by the controllers of this package to apply changes the
+// service controller does not watch for, instead of waiting for its resync.
+// Services without a NodeBalancer yet are left to the service controller.
+func (l *loadbalancers) reconcileService(ctx context.Context, service *v1.Service) error {
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer || len(service.Status.LoadBalancer.Ingress) == 0 {
+		return nil
+	}
+	if l.nodeLister == nil {
+		return errNodeListerNotSet
+	}
+
+	nodes, err := l.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	return l.UpdateLoadBalancer(ctx, Options.ClusterID, service, loadBalancerNodes(nodes))
+}
+
+// loadBalancerNodes returns the nodes the service controller passes to
+// UpdateLoadBalancer: nodes which are not being deleted, not labelled for
+// exclusion from load balancers and not tainted for deletion by the Cluster
+// Autoscaler.
+func loadBalancerNodes(nodes []*v1.Node) []*v1.Node {
+	filtered := make([]*v1.Node, 0, len(nodes))
+nodes:
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if _, excluded := node.Labels[v1.LabelNodeExcludeBalancers]; excluded {
+			continue
+		}
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == toBeDeletedTaint {
+				continue nodes
+			}
+		}
+		filtered = append(filtered, node)
+	}
+	return filtered
+}
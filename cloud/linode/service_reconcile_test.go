@@ -0,0 +1,32 @@
+package linode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadBalancerNodes(t *testing.T) {
+	now := metav1.Now()
+	nodes := []*v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ready"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "deleting", DeletionTimestamp: &now}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "excluded", Labels: map[string]string{v1.LabelNodeExcludeBalancers: ""}}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaled-down"},
+			Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: toBeDeletedTaint, Effect: v1.TaintEffectNoSchedule}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "tainted"},
+			Spec:       v1.NodeSpec{Taints: []v1.Taint{{Key: "dedicated", Effect: v1.TaintEffectNoSchedule}}},
+		},
+	}
+
+	var names []string
+	for _, node := range loadBalancerNodes(nodes) {
+		names = append(names, node.Name)
+	}
+	assert.Equal(t, []string{"ready", "tainted"}, names)
+}
@@ -0,0 +1,65 @@
+package linode
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cloud-provider/api"
+	"k8s.io/klog/v2"
+)
+
+// maxTLSSecretRetryDoublings caps the wait between reconciles of a Service
+// whose TLS secret is missing at 16 times Options.TLSSecretRetryInterval.
+const maxTLSSecretRetryDoublings = 4
+
+// tlsSecretRetries counts the consecutive reconciles of each Service which
+// failed because a TLS secret it references does not exist, e.g. when it is
+// applied right after the Service, to back off while waiting for it.
+type tlsSecretRetries struct {
+	mu       sync.Mutex
+	attempts map[types.UID]int
+}
+
+// next records a reconcile of service failing on a missing TLS secret and
+// returns how long to wait before the next one: Options.TLSSecretRetryInterval,
+// doubled with each consecutive failure up to maxTLSSecretRetryDoublings times.
+func (r *tlsSecretRetries) next(service *v1.Service) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.attempts == nil {
+		r.attempts = make(map[types.UID]int)
+	}
+	retryAfter := Options.TLSSecretRetryInterval << min(r.attempts[service.UID], maxTLSSecretRetryDoublings)
+	r.attempts[service.UID]++
+	return retryAfter
+}
+
+// forget drops the failures recorded for service.
+func (r *tlsSecretRetries) forget(service *v1.Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, service.UID)
+}
+
+// requeueMissingTLSSecret turns err into a request to reconcile service again
+// after a backoff when it is because a TLS secret does not exist (yet), so the
+// Service is reconciled as soon as it likely appears rather than after the
+// generic backoff, and without counting against its retry budget. Retries are
+// disabled when Options.TLSSecretRetryInterval is not positive.
+func (l *loadbalancers) requeueMissingTLSSecret(service *v1.Service, err error) error {
+	if Options.TLSSecretRetryInterval <= 0 {
+		return err
+	}
+	if !errors.Is(err, errTLSSecretNotFound) {
+		l.tlsSecretRetries.forget(service)
+		return err
+	}
+
+	retryAfter := l.tlsSecretRetries.next(service)
+	klog.Infof("reconcile of service (%s) is waiting for a TLS secret, retrying in %s: %s", getServiceNn(service), retryAfter, err)
+	return api.NewRetryError(err.Error(), retryAfter)
+}
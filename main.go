@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"k8s.io/component-base/logs"
 
@@ -78,10 +79,61 @@ func main() {
 
 	// Add Linode-specific flags
 	command.Flags().BoolVar(&linode.Options.LinodeGoDebug, "linodego-debug", false, "enables debug output for the LinodeAPI wrapper")
+	command.Flags().IntVar(&linode.Options.APIRateLimitAttempts, "api-rate-limit-attempts", 5, "maximum number of attempts of a Linode API request rate limited with HTTP 429, retried with exponential backoff honoring Retry-After (1 to not retry)")
+	command.Flags().IntVar(&linode.Options.APITransientErrorAttempts, "api-transient-error-attempts", 3, "maximum number of attempts of a Linode API request failing with a server error (HTTP 5xx), a timeout or a dropped connection, retried with exponential backoff; requests creating resources are only retried when the connection was refused (1 to not retry)")
+	command.Flags().DurationVar(&linode.Options.APIRetryBackoff, "api-retry-backoff", time.Second, "delay before the first retry of a Linode API request which is rate limited or fails with a transient error, doubled before each following retry and randomized between half and all of it")
+	command.Flags().BoolVar(&linode.Options.EnablePprof, "enable-pprof", false, "serve the pprof endpoints under /debug/pprof on --pprof-address; the endpoints are not authenticated, so bind them to a trusted address")
+	command.Flags().StringVar(&linode.Options.PprofAddress, "pprof-address", "127.0.0.1:6060", "address the pprof endpoints are served on when --enable-pprof is set")
 	command.Flags().BoolVar(&linode.Options.EnableRouteController, "enable-route-controller", false, "enables route_controller for ccm")
+	command.Flags().BoolVar(&linode.Options.RequireProviderID, "require-provider-id", false, "log an error for initialized nodes without a provider ID and never match them to a linode by name or IP, nor report them as deleted or shut down")
+	command.Flags().BoolVar(&linode.Options.UseMetadataService, "use-metadata-service", false, "look up the linode of the node the CCM runs on by the instance ID the Linode metadata service reports, rather than by name, when the node has no provider ID; the node is named by the NODE_NAME environment variable, or the hostname")
+	command.Flags().StringVar(&linode.Options.InstanceIDCacheConfigMap, "instance-id-cache-configmap", "", "<namespace>/<name> of a ConfigMap persisting the linode IDs of nodes across restarts, so that nodes can be looked up without listing all linodes on startup (disabled if empty)")
+	command.Flags().BoolVar(&linode.Options.InstanceClassLabel, "instance-class-label", false, "label nodes with the class of their Linode type (standard, dedicated, gpu, highmem or other) as node.k8s.linode.com/instance-class")
+	command.Flags().BoolVar(&linode.Options.TransitionalInstancesShutdown, "transitional-instances-shutdown", false, "report nodes whose linode is migrating, rebooting or provisioning as shut down, as they are while offline or shutting down, so that their pods are rescheduled promptly")
+	command.Flags().BoolVar(&linode.Options.ZoneFromRegion, "zone-from-region", false, "set the topology.kubernetes.io/zone label of nodes to their Linode region, which Linode has no zones within, for topology features requiring a zone")
+	command.Flags().DurationVar(&linode.Options.InstanceCacheTTL, "instance-cache-ttl", 0, "how long the linodes looked up for nodes are cached before the Linode API is queried again (defaults to LINODE_INSTANCE_CACHE_TTL seconds, or 15s)")
+	command.Flags().IntVar(&linode.Options.InstanceLookupConcurrency, "instance-lookup-concurrency", 10, "maximum number of concurrent lookups of the linodes backing nodes, bounding the burst of Linode API calls on startup (0 for no limit)")
 	command.Flags().StringVar(&linode.Options.VPCName, "vpc-name", "", "vpc name whose routes will be managed by route-controller")
 	command.Flags().StringVar(&linode.Options.LoadBalancerType, "load-balancer-type", "nodebalancer", "configures which type of load-balancing to use for LoadBalancer Services (options: nodebalancer, cilium-bgp)")
 	command.Flags().StringVar(&linode.Options.BGPNodeSelector, "bgp-node-selector", "", "node selector to use to perform shared IP fail-over with BGP (e.g. cilium-bgp-peering=true")
+	command.Flags().StringVar(&linode.Options.BackendIPPreference, "backend-ip-preference", "", "ordered, comma separated list of node address types to use for NodeBalancer backends (options: vpc, private, public)")
+	command.Flags().StringVar(&linode.Options.BackendIPSource, "backend-ip-source", "node", "where NodeBalancer backend addresses are looked up (options: node, instance); instance uses the networking of the Linode backing each node instead of the Node status addresses")
+	command.Flags().IntVar(&linode.Options.MinBackends, "min-backends", 0, "minimum number of backend nodes of a NodeBalancer, unless overridden by the min-backends annotation of its service; a Warning event is emitted for LoadBalancer services with fewer backends (0 to disable)")
+	command.Flags().StringVar(&linode.Options.UnschedulableNodesPolicy, "unschedulable-nodes-policy", "accept", "mode of the NodeBalancer backends of unschedulable (cordoned) nodes (options: accept, drain); drain keeps existing connections to them but routes no new ones there")
+	command.Flags().StringVar(&linode.Options.NoBackendNodesPolicy, "no-backend-nodes-policy", "keep", "how to handle LoadBalancer Services for which no nodes are available as backends (options: keep, defer); keep emits a Warning event and creates or updates the NodeBalancer without backends, defer skips creating or updating the NodeBalancer until at least one node is available")
+	command.Flags().DurationVar(&linode.Options.AccountUsageInterval, "account-usage-interval", 5*time.Minute, "how often the NodeBalancer usage of the Linode account is exported as the ccm_account_nodebalancers_used metric (0 to disable)")
+	command.Flags().IntVar(&linode.Options.AccountNodeBalancerLimit, "account-nodebalancer-limit", 0, "NodeBalancer limit of the Linode account, exported as the ccm_account_nodebalancers_limit metric since it is not exposed by the Linode API (0 to not export it)")
+	command.Flags().Float64Var(&linode.Options.ServiceAPIQPS, "service-api-qps", 0, "maximum rate, in requests per second, of the Linode API requests made for each LoadBalancer Service, unless overridden by its api-qps annotation (0 for unlimited)")
+	command.Flags().IntVar(&linode.Options.ServiceAPIBurst, "service-api-burst", 5, "maximum burst of the Linode API requests made for each LoadBalancer Service, unless overridden by its api-burst annotation")
+	command.Flags().BoolVar(&linode.Options.AnnotateEffectiveConfig, "annotate-effective-config", false, "annotate LoadBalancer services with the NodeBalancer configuration applied for them, as JSON with TLS certificates and keys redacted, as service.beta.kubernetes.io/linode-loadbalancer-status-effective-config; requires the patch permission on services")
+	command.Flags().BoolVar(&linode.Options.AnnotateNodeBalancerID, "annotate-nodebalancer-id", false, "annotate LoadBalancer services with the ID of their NodeBalancer as service.beta.kubernetes.io/linode-loadbalancer-status-nodebalancer-id; requires the patch permission on services")
+	command.Flags().BoolVar(&linode.Options.AllowCrossNamespaceTLSSecrets, "allow-cross-namespace-tls-secrets", false, "allow the tls-secret-name of a port config to reference a secret of another namespace as <namespace>/<name>; by default only secrets of the namespace of the service are read")
+	command.Flags().StringVar(&linode.Options.PreviousClusterID, "previous-cluster-id", "", "previous cluster ID (cluster name) of the cluster; on startup, NodeBalancers tagged with it are re-tagged with the current cluster ID, e.g. after migrating the cluster")
+	command.Flags().StringVar(&linode.Options.PrivilegedPortsPolicy, "privileged-ports-policy", "allow", "how to handle LoadBalancer Services with ports below 1024 (options: allow, reject); reject emits a Warning event and fails the reconcile")
+	command.Flags().StringVar(&linode.Options.AdoptedTagsPolicy, "adopted-nodebalancer-tags-policy", "preserve", "how to handle the tags of NodeBalancers not created by the CCM, e.g. adopted with the nodebalancer-id annotation (options: preserve, replace); preserve keeps their tags next to the ones set by the CCM, replace replaces them")
+	command.Flags().StringVar(&linode.Options.NodeBalancerConfigPolicy, "nodebalancer-config-policy", "manage-only-owned", "which NodeBalancer configs for ports not in the service are deleted (options: manage-only-owned, manage-all); manage-only-owned leaves configs the CCM did not create, such as ones added manually, alone")
+	command.Flags().StringVar(&linode.Options.DuplicateBackendAddressPolicy, "duplicate-backend-address-policy", "keep-first", "how to handle nodes which resolve to the NodeBalancer backend address of another node (options: keep-first, fail); keep-first keeps the backend of the first node by name and logs a warning for the others, fail fails the reconcile")
+	command.Flags().BoolVar(&linode.Options.RejectRegionMismatch, "reject-nodebalancer-region-mismatch", false, "refuse to attach backends to a NodeBalancer in a different region than the cluster, instead of only emitting a Warning event")
+	command.Flags().DurationVar(&linode.Options.NodeBalancerIPRequeueInterval, "nodebalancer-ip-requeue-interval", 5*time.Second, "how long to wait before checking again for the IP of a NodeBalancer which is still being provisioned; the wait grows with the age of the NodeBalancer, up to 16 times this interval")
+	command.Flags().IntVar(&linode.Options.MaxReconcileRetries, "max-reconcile-retries", 0, "number of consecutive failed reconciles of a NodeBalancer Service after which it is no longer reconciled until its spec, annotations or nodes change, or the reconcile-retry-cooldown passes (0 for no limit); transient errors such as Linode API outages are not counted")
+	command.Flags().DurationVar(&linode.Options.ReconcileRetryCooldown, "reconcile-retry-cooldown", 10*time.Minute, "time after which a Service that exhausted its max-reconcile-retries is reconciled again (0 to wait for it to change)")
+	command.Flags().IntVar(&linode.Options.NodeBalancerTransferQuota, "nodebalancer-transfer-quota", 0, "monthly network transfer allowance of a NodeBalancer, in MB; a Warning event is emitted for LoadBalancer services whose NodeBalancer used more than nodebalancer-transfer-warning-threshold of it this month (0 to disable)")
+	command.Flags().Float64Var(&linode.Options.TransferWarningThreshold, "nodebalancer-transfer-warning-threshold", 0.8, "fraction of the nodebalancer-transfer-quota above which a Warning event is emitted")
+	command.Flags().DurationVar(&linode.Options.CertExpiryWarningWindow, "cert-expiry-warning-window", 30*24*time.Hour, "emit a Warning event for LoadBalancer services whose TLS certificates expire within this window")
+	command.Flags().DurationVar(&linode.Options.TLSSecretRetryInterval, "tls-secret-retry-interval", 5*time.Second, "how long to wait before reconciling again a Service whose TLS secret does not exist yet, doubled with each attempt up to 16 times; such reconciles are not counted against max-reconcile-retries (0 to fail them as other errors)")
+	command.Flags().StringVar(&linode.Options.TLSSecretMissingPolicy, "tls-secret-missing-policy", "keep-last-good", "how to handle a deleted TLS secret referenced by a NodeBalancer config (options: keep-last-good, fail)")
+	command.Flags().StringSliceVar(&linode.Options.ServiceNamespaces, "service-namespaces", nil, "comma separated list of namespaces in which LoadBalancer Services are managed (default: all namespaces)")
+	command.Flags().StringSliceVar(&linode.Options.ExcludedServiceNamespaces, "excluded-service-namespaces", nil, "comma separated list of namespaces in which LoadBalancer Services are not managed")
+	command.Flags().StringToStringVar(&linode.Options.NodeBalancerLabelTags, "nodebalancer-label-tags", nil, "comma separated list of service-label=tag-key pairs; every NodeBalancer is tagged with <tag-key>:<label value> for the mapped labels set on its service (e.g. example.com/team=team)")
+	command.Flags().StringToStringVar(&linode.Options.RequiredNodeConditions, "required-node-conditions", map[string]string{"Ready": "True"}, "comma separated list of condition=status pairs nodes must have to be used as NodeBalancer backends (e.g. Ready=True,example.com/NetworkReady=True)")
+	command.Flags().StringVar(&linode.Options.BackendNodeSelector, "backend-node-selector", "", "label selector nodes must match to be used as NodeBalancer backends, e.g. to only use a dedicated node pool (e.g. lke.linode.com/pool-id=1234); Services can override it with the backend-node-selector annotation (default: all nodes)")
+	command.Flags().StringSliceVar(&linode.Options.ExcludedNodeTaints, "excluded-node-taints", []string{"ToBeDeletedByClusterAutoscaler"}, "comma separated list of taint keys; nodes with any of them are not used as NodeBalancer backends")
+	command.Flags().StringToStringVar(&linode.Options.DefaultCheckPaths, "default-check-paths", nil, "comma separated list of protocol=path pairs; the path of http and http_body health checks for NodeBalancer configs of that protocol (tcp, http, https) when the service sets no check-path annotation (e.g. http=/healthz,https=/healthz), defaults to /")
+	command.Flags().DurationVar(&linode.Options.NodeBalancerProvisionTimeout, "nodebalancer-provision-timeout", 2*time.Minute, "maximum time to wait for a NodeBalancer to be created before retrying; NodeBalancers created after the timeout are adopted on retry (0 to disable)")
+	command.Flags().DurationVar(&linode.Options.BackendDrainPeriod, "backend-drain-period", 0, "duration NodeBalancer backends are left in drain mode before they are removed (0 to remove them immediately)")
+	command.Flags().IntVar(&linode.Options.BackendRemovalBatchSize, "backend-removal-batch-size", 0, "maximum number of NodeBalancer backends deleted at once; more backends are deleted in batches before the configs are rebuilt (0 to remove them all at once by rebuilding the configs)")
+	command.Flags().DurationVar(&linode.Options.BackendRemovalBatchDelay, "backend-removal-batch-delay", time.Second, "duration to wait between batches of NodeBalancer backend deletions")
+	command.Flags().DurationVar(&linode.Options.BackendHealthGracePeriod, "backend-health-grace-period", time.Minute, "duration after a NodeBalancer backend is added during which failing health checks are not reported")
 
 	// Set static flags
 	command.Flags().VisitAll(func(fl *pflag.Flag) {
@@ -128,6 +180,9 @@ func main() {
 }
 
 func cloudInitializer(config *config.CompletedConfig) cloudprovider.Interface {
+	// the cluster name identifies this cluster in requests made to the Linode API
+	linode.Options.ClusterID = config.ComponentConfig.KubeCloudShared.ClusterName
+
 	// initialize cloud provider with the cloud provider name and config file provided
 	cloud, err := cloudprovider.InitCloudProvider(linode.ProviderName, "")
 	if err != nil {